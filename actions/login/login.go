@@ -215,6 +215,10 @@ func logoutAction(ctx context.Context, cmd *cli.Command) error {
 		return nil
 	}
 
+	if err := storage.DeleteAccount(storedSession.AccountName()); err != nil {
+		fmt.Printf("⚠ Warning: %v\n", err)
+	}
+
 	igClient, err := instagram.NewClientFromSession(storedSession)
 	if err != nil {
 		if err := storage.DeleteSession(); err != nil {
@@ -283,6 +287,14 @@ func statusAction(ctx context.Context, cmd *cli.Command) error {
 		fmt.Println("  Session: Expired (will attempt refresh on next request)")
 	}
 
+	if accounts, err := storage.LoadAccounts(); err == nil && len(accounts) > 1 {
+		names := make([]string, len(accounts))
+		for i, account := range accounts {
+			names[i] = "@" + account.AccountName()
+		}
+		fmt.Printf("  Accounts: %s (messages --all-accounts)\n", strings.Join(names, ", "))
+	}
+
 	fmt.Printf("  Storage: %s\n", storage.GetBasePath())
 
 	return nil
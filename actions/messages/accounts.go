@@ -0,0 +1,314 @@
+package messages
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/storage"
+)
+
+// account is one logged-in account of the merged inbox and its inbox as last
+// fetched
+type account struct {
+	name string
+	c    *instagram.Client
+
+	conversations   []instagram.Conversation
+	pendingRequests int
+	err             error
+}
+
+// accountConversation is a conversation of the merged inbox, answered from
+// the account it belongs to
+type accountConversation struct {
+	account *account
+	conv    instagram.Conversation
+}
+
+// replyingAs is set in the merged inbox, where the conversation header says
+// which account replies go out from
+var replyingAs bool
+
+// accountName names the account of a client the way session storage does:
+// its username, or its user ID for sessions restored from a session ID
+func accountName(c *instagram.Client) string {
+	if c.Username != "" {
+		return c.Username
+	}
+	return strconv.FormatInt(c.UserID(), 10)
+}
+
+// loadAccounts restores a client for every logged-in account
+func loadAccounts(cmd *cli.Command) ([]*account, error) {
+	store, err := storage.NewSessionStorage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize session storage: %w", err)
+	}
+
+	sessions, err := store.LoadAccounts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load accounts: %w", err)
+	}
+
+	if len(sessions) == 0 {
+		return nil, errNotLoggedIn
+	}
+
+	accounts := make([]*account, 0, len(sessions))
+	for _, stored := range sessions {
+		c, err := instagram.NewClientFromSession(stored)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore session of @%s: %w", stored.AccountName(), err)
+		}
+		c.Debug = cmd.Bool("debug")
+
+		accounts = append(accounts, &account{name: stored.AccountName(), c: c})
+	}
+	return accounts, nil
+}
+
+// refreshAccounts fetches the inbox of every account at once. An account
+// whose fetch fails keeps its previous conversations.
+func refreshAccounts(accounts []*account) {
+	fmt.Printf("%s⏳ Loading %d inboxes...%s\n", colorCyan, len(accounts), colorReset)
+
+	var wg sync.WaitGroup
+	for _, a := range accounts {
+		wg.Add(1)
+		go func(a *account) {
+			defer wg.Done()
+
+			list, err := fetchInbox(a.c, inboxView.folder, inboxView.pages)
+			a.err = err
+			if err != nil {
+				return
+			}
+			a.conversations = list.Conversations
+			a.pendingRequests = list.PendingRequests
+		}(a)
+	}
+	wg.Wait()
+}
+
+// unread totals the unread messages of the account
+func (a *account) unread() int {
+	total := 0
+	for _, conv := range a.conversations {
+		total += conv.UnreadCount
+	}
+	return total
+}
+
+// markRead clears the unread count of a thread after it was opened, so the
+// totals are right without fetching every inbox again
+func (a *account) markRead(threadID string) {
+	for i := range a.conversations {
+		if a.conversations[i].ThreadID == threadID {
+			a.conversations[i].UnreadCount = 0
+		}
+	}
+}
+
+// mergeInboxes lists the conversations of every account that match the
+// inbox filter, newest first unless the filter orders them otherwise
+func mergeInboxes(accounts []*account) []accountConversation {
+	var merged []accountConversation
+	for _, a := range accounts {
+		for _, conv := range a.conversations {
+			if inboxView.filter.matches(conv) {
+				merged = append(merged, accountConversation{account: a, conv: conv})
+			}
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		a, b := merged[i].conv, merged[j].conv
+		switch inboxView.filter.sort {
+		case sortUnread:
+			if (a.UnreadCount > 0) != (b.UnreadCount > 0) {
+				return a.UnreadCount > 0
+			}
+		case sortTitle:
+			return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+		}
+		return a.LastMessageAt.After(b.LastMessageAt)
+	})
+	return merged
+}
+
+// displayAccountTotals prints a line per account with its unread total
+func displayAccountTotals(accounts []*account) {
+	for _, a := range accounts {
+		if a.err != nil {
+			fmt.Printf("%s  ⚠ @%s: failed to fetch inbox: %v%s\n", colorYellow, a.name, a.err, colorReset)
+			continue
+		}
+
+		line := fmt.Sprintf("  @%-20s %d unread", a.name, a.unread())
+		if a.pendingRequests > 0 {
+			line += fmt.Sprintf(" · %d request(s)", a.pendingRequests)
+		}
+
+		if a.unread() > 0 {
+			fmt.Printf("%s%s%s\n", colorGreen, line, colorReset)
+		} else {
+			fmt.Printf("%s%s%s\n", colorDim, line, colorReset)
+		}
+	}
+}
+
+func displayMergedConversations(merged []accountConversation) {
+	if len(merged) == 0 {
+		fmt.Printf("\n%s📭 No conversations found.%s\n", colorDim, colorReset)
+		return
+	}
+
+	fmt.Printf("\n%s%-4s %-16s %-23s %-30s %s%s\n", colorBold, "#", "ACCOUNT", "FROM", "LAST MESSAGE", "TIME", colorReset)
+	fmt.Printf("%s─────────────────────────────────────────────────────────────────────────────────%s\n", colorDim, colorReset)
+
+	for i, entry := range merged {
+		conv := entry.conv
+
+		preview := truncateString(conv.LastMessage, 28)
+		if preview == "" {
+			preview = "[No messages]"
+		}
+
+		numColor := colorDim
+		titleColor := colorWhite
+		previewColor := colorDim
+		indicators := ""
+
+		if conv.UnreadCount > 0 {
+			numColor = colorGreen
+			titleColor = colorBold + colorWhite
+			previewColor = colorWhite
+			indicators = fmt.Sprintf("%s(%d)%s", colorGreen, conv.UnreadCount, colorReset)
+		}
+
+		fmt.Printf("%s%-4d%s %s%-16s%s %s%-23s%s %s%-30s%s %s%s%s %s\n",
+			numColor, i+1, colorReset,
+			colorCyan, truncateString("@"+entry.account.name, 15), colorReset,
+			titleColor, truncateString(conv.Title, 21), colorReset,
+			previewColor, preview, colorReset,
+			colorDim, formatTimeAgo(conv.LastMessageAt), colorReset,
+			indicators,
+		)
+	}
+}
+
+// runAllAccountsMode is the line-mode inbox of --all-accounts: the
+// conversations of every logged-in account in one list. Opening one replies
+// from the account it belongs to.
+func runAllAccountsMode(accounts []*account) error {
+	reader := bufio.NewReader(os.Stdin)
+	replyingAs = true
+
+	clearScreen()
+	refreshAccounts(accounts)
+	lastRefresh := time.Now()
+	clearScreen()
+
+	for {
+		merged := mergeInboxes(accounts)
+
+		fmt.Printf("\n%s📂 %s · %d accounts%s\n", colorBold, inboxView.describe(), len(accounts), colorReset)
+		displayAccountTotals(accounts)
+		displayMergedConversations(merged)
+
+		fmt.Printf("\n%s─────────────────────────────────────────────────────────%s\n", colorDim, colorReset)
+		fmt.Printf("%sCommands:%s [number] View conversation • %sr%s Refresh • %sq%s Quit\n",
+			colorCyan, colorReset, colorGreen, colorReset, colorRed, colorReset)
+		fmt.Printf("%s          filter unread|muted|pinned|group|direct|@user|all • folder all|primary|general|requests • sort recent|unread|title%s\n",
+			colorDim, colorReset)
+		fmt.Printf("%s➜ %s", colorGreen, colorReset)
+
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+
+		switch strings.ToLower(input) {
+		case "q", "quit", "exit":
+			fmt.Printf("\n%s👋 Goodbye!%s\n", colorCyan, colorReset)
+			return nil
+		case "r", "refresh":
+			clearScreen()
+			refreshAccounts(accounts)
+			lastRefresh = time.Now()
+			clearScreen()
+			continue
+		case "":
+			clearScreen()
+			if time.Since(lastRefresh) > 60*time.Second {
+				refreshAccounts(accounts)
+				lastRefresh = time.Now()
+				clearScreen()
+			}
+			continue
+		}
+
+		if handled, refetch, err := inboxView.runCommand(input); handled {
+			clearScreen()
+			if err != nil {
+				fmt.Printf("%s✗ %v%s\n", colorRed, err, colorReset)
+			}
+			if refetch {
+				refreshAccounts(accounts)
+				lastRefresh = time.Now()
+				clearScreen()
+			}
+			continue
+		}
+
+		num, err := strconv.Atoi(input)
+		if err != nil || num < 1 || num > len(merged) {
+			fmt.Printf("%s✗ Invalid selection. Enter a number 1-%d%s\n", colorRed, len(merged), colorReset)
+			time.Sleep(1 * time.Second)
+			clearScreen()
+			continue
+		}
+
+		entry := merged[num-1]
+		previews.use(entry.account.c)
+
+		if err := openConversation(entry.account.c, entry.conv, reader); err != nil {
+			fmt.Printf("%s✗ Error: %v%s\n", colorRed, err, colorReset)
+			time.Sleep(2 * time.Second)
+		} else {
+			entry.account.markRead(entry.conv.ThreadID)
+		}
+
+		clearScreen()
+	}
+}
+
+func allAccountsAction(cmd *cli.Command) error {
+	accounts, err := loadAccounts(cmd)
+	if errors.Is(err, errNotLoggedIn) {
+		fmt.Printf("%s✗ Not logged in. Please run 'go-instagram-cli login' first.%s\n", colorRed, colorReset)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := setInboxView(cmd); err != nil {
+		return cli.Exit(err.Error(), exitUsage)
+	}
+	sendReceipts = !cmd.Bool("no-receipts")
+
+	if err := setupPreviews(cmd, accounts[0].c); err != nil {
+		return cli.Exit(err.Error(), exitUsage)
+	}
+
+	return runAllAccountsMode(accounts)
+}
@@ -0,0 +1,546 @@
+package messages
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/storage"
+)
+
+const autoReplyRulesFile = "autoreply.json"
+
+var autoReplyCommand = &cli.Command{
+	Name:  "autoreply",
+	Usage: "Answer incoming messages automatically from a rules file",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "rules",
+			Usage:   "Rules file (default ~/.local/go-instagram-cli/" + autoReplyRulesFile + ")",
+			Sources: cli.EnvVars("IG_AUTOREPLY_RULES"),
+		},
+	},
+	Commands: []*cli.Command{
+		{
+			Name:  "run",
+			Usage: "Watch the inbox and reply to new messages that match a rule, at most once per thread per cooldown",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Only print what would be sent",
+				},
+				&cli.DurationFlag{
+					Name:    "interval",
+					Aliases: []string{"i"},
+					Value:   15 * time.Second,
+					Usage:   "Time between inbox polls when realtime is off or disconnected",
+				},
+			},
+			Action: autoReplyRunAction,
+		},
+		{
+			Name:  "rules",
+			Usage: "Check the rules file and list its rules",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "init",
+					Usage: "Write an example rules file if there is none",
+				},
+			},
+			Action: autoReplyRulesAction,
+		},
+		{
+			Name:  "log",
+			Usage: "Print the replies that were sent automatically",
+			Flags: []cli.Flag{
+				jsonFlag(),
+				&cli.IntFlag{
+					Name:    "limit",
+					Aliases: []string{"n"},
+					Value:   20,
+					Usage:   "Number of entries to print (0 for all)",
+				},
+			},
+			Action: autoReplyLogAction,
+		},
+	},
+}
+
+// autoReplyRules is the rules file. Rules are tried in order and the first
+// match answers, unless the thread got an automatic reply within that
+// rule's cooldown.
+type autoReplyRules struct {
+	// Cooldown is the default time after any automatic reply before a rule
+	// answers the same thread again. Cooldowns are per thread, so one thread
+	// gets at most one reply per window however many rules match.
+	Cooldown string           `json:"cooldown,omitempty"`
+	Rules    []*autoReplyRule `json:"rules"`
+}
+
+type autoReplyRule struct {
+	Name string `json:"name"`
+
+	// From limits the rule to these usernames
+	From []string `json:"from,omitempty"`
+
+	// Thread is "direct", "group" or empty for both
+	Thread string `json:"thread,omitempty"`
+
+	// Match is a regular expression the message text must match
+	Match string `json:"match,omitempty"`
+
+	// Hours is a local time range like "18:00-09:00"
+	Hours string `json:"hours,omitempty"`
+
+	// FirstMessageOnly answers only the sender's first message in a thread
+	FirstMessageOnly bool `json:"first_message_only,omitempty"`
+
+	// Reply is the text to send, with {{username}} style placeholders
+	Reply string `json:"reply"`
+
+	Cooldown string `json:"cooldown,omitempty"`
+
+	pattern    *regexp.Regexp
+	start, end int // minutes after midnight
+	cooldown   time.Duration
+}
+
+const exampleAutoReplyRules = `{
+  "cooldown": "1h",
+  "rules": [
+    {
+      "name": "welcome",
+      "thread": "direct",
+      "first_message_only": true,
+      "reply": "Hi {{first_name}}, thanks for reaching out! We usually answer within a few hours."
+    },
+    {
+      "name": "pricing",
+      "match": "(?i)\\b(price|pricing|cost)s?\\b",
+      "reply": "Hi {{username}}, you can find our prices at https://example.com/pricing",
+      "cooldown": "24h"
+    },
+    {
+      "name": "after-hours",
+      "thread": "direct",
+      "hours": "18:00-09:00",
+      "reply": "Thanks {{first_name}}! We're offline right now and will reply after 9:00."
+    }
+  ]
+}
+`
+
+// loadAutoReplyRules reads and checks the rules file
+func loadAutoReplyRules(path string) (*autoReplyRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no rules file at %s (run 'messages autoreply rules --init' for an example)", path)
+		}
+		return nil, fmt.Errorf("failed to read rules: %w", err)
+	}
+
+	var rules autoReplyRules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	defaultCooldown := time.Hour
+	if rules.Cooldown != "" {
+		if defaultCooldown, err = time.ParseDuration(rules.Cooldown); err != nil {
+			return nil, fmt.Errorf("invalid cooldown %q: %w", rules.Cooldown, err)
+		}
+	}
+
+	for i, rule := range rules.Rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		if err := rule.compile(defaultCooldown); err != nil {
+			return nil, fmt.Errorf("%s: %w", rule.Name, err)
+		}
+	}
+
+	return &rules, nil
+}
+
+func (r *autoReplyRule) compile(defaultCooldown time.Duration) error {
+	if strings.TrimSpace(r.Reply) == "" {
+		return errors.New("reply is empty")
+	}
+
+	switch r.Thread {
+	case "", "direct", "group":
+	default:
+		return fmt.Errorf("thread must be direct or group, not %q", r.Thread)
+	}
+
+	for i, username := range r.From {
+		r.From[i] = strings.TrimPrefix(username, "@")
+	}
+
+	if r.Match != "" {
+		pattern, err := regexp.Compile(r.Match)
+		if err != nil {
+			return fmt.Errorf("invalid match: %w", err)
+		}
+		r.pattern = pattern
+	}
+
+	if r.Hours != "" {
+		from, to, ok := strings.Cut(r.Hours, "-")
+		start, err1 := parseClock(from)
+		end, err2 := parseClock(to)
+		if !ok || err1 != nil || err2 != nil {
+			return fmt.Errorf("hours must look like 09:00-17:00, not %q", r.Hours)
+		}
+		r.start, r.end = start, end
+	}
+
+	r.cooldown = defaultCooldown
+	if r.Cooldown != "" {
+		cooldown, err := time.ParseDuration(r.Cooldown)
+		if err != nil {
+			return fmt.Errorf("invalid cooldown %q: %w", r.Cooldown, err)
+		}
+		r.cooldown = cooldown
+	}
+
+	return nil
+}
+
+// parseClock turns "HH:MM" into minutes after midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// matchesMessage applies the checks that need only the event
+func (r *autoReplyRule) matchesMessage(e instagram.Event, now time.Time) bool {
+	if len(r.From) > 0 && !containsFold(r.From, e.SenderName) {
+		return false
+	}
+
+	if r.pattern != nil && !r.pattern.MatchString(e.Text) {
+		return false
+	}
+
+	if r.Hours != "" {
+		minute := now.Hour()*60 + now.Minute()
+		if r.start <= r.end {
+			return minute >= r.start && minute < r.end
+		}
+		// The range wraps past midnight
+		return minute >= r.start || minute < r.end
+	}
+
+	return true
+}
+
+// matchesThread applies the checks that need the thread
+func (r *autoReplyRule) matchesThread(thread instagram.Thread, e instagram.Event) bool {
+	isGroup := thread.IsGroup || len(thread.Users) > 1
+	switch {
+	case r.Thread == "direct" && isGroup:
+		return false
+	case r.Thread == "group" && !isGroup:
+		return false
+	case r.FirstMessageOnly && !isFirstMessage(thread, e):
+		return false
+	}
+	return true
+}
+
+// isFirstMessage reports whether nothing older from the same sender is in
+// the thread
+func isFirstMessage(thread instagram.Thread, e instagram.Event) bool {
+	if thread.HasOlder {
+		return false
+	}
+
+	for _, item := range thread.Items {
+		senderID, _ := item.UserID.Int64()
+		ts, _ := item.Timestamp.Int64()
+		if item.ItemID != e.ItemID && senderID == e.SenderID && ts < e.Timestamp.UnixMicro() {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// autoResponder answers message events according to the rules
+type autoResponder struct {
+	c      *instagram.Client
+	store  *storage.Storage
+	rules  *autoReplyRules
+	log    *storage.AutoReplyLog
+	dryRun bool
+
+	// Messages received before the responder started are never answered
+	started time.Time
+}
+
+// coolingDown reports whether the thread got an automatic reply within
+// rule's cooldown
+func (a *autoResponder) coolingDown(rule *autoReplyRule, threadID string, now time.Time) bool {
+	last, ok := a.log.LastSent[threadID]
+	return ok && now.Sub(time.Unix(last, 0)) < rule.cooldown
+}
+
+// candidates returns the rules that may answer e, in order
+func (a *autoResponder) candidates(e instagram.Event, now time.Time) []*autoReplyRule {
+	var rules []*autoReplyRule
+	for _, rule := range a.rules.Rules {
+		if rule.matchesMessage(e, now) && !a.coolingDown(rule, e.ThreadID, now) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// handle answers one event, returning the entry it sent or nil
+func (a *autoResponder) handle(e instagram.Event) (*storage.AutoReplyEntry, error) {
+	if e.Type != instagram.EventMessage || e.FromMe || e.Timestamp.Before(a.started) {
+		return nil, nil
+	}
+
+	now := time.Now()
+
+	candidates := a.candidates(e, now)
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	resp, err := a.c.GetThread(e.ThreadID, "", 20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", e.ThreadTitle, err)
+	}
+	thread := resp.Thread
+
+	var rule *autoReplyRule
+	for _, candidate := range candidates {
+		if candidate.matchesThread(thread, e) {
+			rule = candidate
+			break
+		}
+	}
+	if rule == nil {
+		return nil, nil
+	}
+
+	fullName := ""
+	if sender := findMember(thread.Users, e.SenderName); sender != nil {
+		fullName = sender.FullName
+	}
+
+	entry := &storage.AutoReplyEntry{
+		Rule:        rule.Name,
+		ThreadID:    e.ThreadID,
+		ThreadTitle: e.ThreadTitle,
+		Sender:      e.SenderName,
+		ItemID:      e.ItemID,
+		Text:        expandPlaceholders(rule.Reply, replyVars(e.SenderName, fullName, e.ThreadTitle)),
+		Timestamp:   now,
+	}
+
+	// A dry run keeps its cooldowns in memory only
+	if a.dryRun {
+		a.log.LastSent[e.ThreadID] = now.Unix()
+		return entry, nil
+	}
+
+	sent, err := a.c.SendMessage(e.ThreadID, entry.Text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reply to %s: %w", e.ThreadTitle, err)
+	}
+	entry.SentItemID = sent.Payload.ItemID
+
+	a.log.LastSent[e.ThreadID] = now.Unix()
+	a.log.Entries = append(a.log.Entries, *entry)
+	if err := a.store.SaveAutoReplyLog(a.log); err != nil {
+		return entry, fmt.Errorf("failed to save autoreply log: %w", err)
+	}
+
+	return entry, nil
+}
+
+// rulesPath is the file picked by --rules or the default in the config dir
+func rulesPath(cmd *cli.Command, store *storage.Storage) string {
+	if path := cmd.String("rules"); path != "" {
+		return path
+	}
+	return store.ConfigPath(autoReplyRulesFile)
+}
+
+func autoReplyRunAction(ctx context.Context, cmd *cli.Command) error {
+	interval := cmd.Duration("interval")
+	if interval < time.Second {
+		return cli.Exit("interval must be at least 1s", exitUsage)
+	}
+
+	c, store, err := loadClient(cmd)
+	if err != nil {
+		return scriptError(err)
+	}
+
+	rules, err := loadAutoReplyRules(rulesPath(cmd, store))
+	if err != nil {
+		return cli.Exit(err.Error(), exitUsage)
+	}
+
+	log, err := store.LoadAutoReplyLog()
+	if err != nil {
+		return scriptError(fmt.Errorf("failed to load autoreply log: %w", err))
+	}
+
+	responder := &autoResponder{c: c, store: store, rules: rules, log: log, dryRun: cmd.Bool("dry-run"), started: time.Now()}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	opts := streamOptions(cmd)
+	opts.Interval = interval
+	opts.OnError = func(err error, retryIn time.Duration) {
+		fmt.Fprintf(os.Stderr, "autoreply: %v (retrying in %s)\n", err, retryIn.Round(time.Second))
+	}
+
+	mode := ""
+	if responder.dryRun {
+		mode = " (dry run, nothing is sent)"
+	}
+	fmt.Printf("%s🤖 Auto-replying with %d rules%s. Press Ctrl+C to stop.%s\n", colorDim, len(rules.Rules), mode, colorReset)
+
+	for event := range c.StreamEvents(ctx, opts) {
+		entry, err := responder.handle(event)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "autoreply: %v\n", err)
+		}
+		if entry == nil {
+			continue
+		}
+
+		verb := "Replied to"
+		if responder.dryRun {
+			verb = "Would reply to"
+		}
+		fmt.Printf("%s %s✓ %s @%s in %s%s %s[%s]%s\n  %s\n",
+			entry.Timestamp.Format("15:04:05"), colorGreen, verb, entry.Sender, entry.ThreadTitle, colorReset,
+			colorDim, entry.Rule, colorReset, singleLine(entry.Text))
+	}
+
+	return nil
+}
+
+func autoReplyRulesAction(ctx context.Context, cmd *cli.Command) error {
+	store, err := storage.NewSessionStorage()
+	if err != nil {
+		return scriptError(fmt.Errorf("failed to initialize session storage: %w", err))
+	}
+
+	path := rulesPath(cmd, store)
+
+	if cmd.Bool("init") {
+		if _, err := os.Stat(path); err == nil {
+			return cli.Exit(fmt.Sprintf("%s already exists", path), exitUsage)
+		}
+		if err := os.WriteFile(path, []byte(exampleAutoReplyRules), 0600); err != nil {
+			return scriptError(fmt.Errorf("failed to write %s: %w", path, err))
+		}
+		fmt.Printf("%s✓ Wrote example rules to %s%s\n", colorGreen, path, colorReset)
+	}
+
+	rules, err := loadAutoReplyRules(path)
+	if err != nil {
+		return cli.Exit(err.Error(), exitUsage)
+	}
+
+	fmt.Printf("%s%s%s %s(%d rules, checked in order)%s\n", colorBold, path, colorReset, colorDim, len(rules.Rules), colorReset)
+	for i, rule := range rules.Rules {
+		var conditions []string
+		if len(rule.From) > 0 {
+			conditions = append(conditions, "from @"+strings.Join(rule.From, ", @"))
+		}
+		if rule.Thread != "" {
+			conditions = append(conditions, rule.Thread+" threads")
+		}
+		if rule.Match != "" {
+			conditions = append(conditions, "text ~ "+rule.Match)
+		}
+		if rule.Hours != "" {
+			conditions = append(conditions, "between "+rule.Hours)
+		}
+		if rule.FirstMessageOnly {
+			conditions = append(conditions, "first message only")
+		}
+		if len(conditions) == 0 {
+			conditions = append(conditions, "every message")
+		}
+
+		fmt.Printf("%d. %s%s%s %s(cooldown %s)%s\n", i+1, colorCyan, rule.Name, colorReset, colorDim, rule.cooldown, colorReset)
+		fmt.Printf("   when %s\n", strings.Join(conditions, ", "))
+		fmt.Printf("   %s→ %s%s\n", colorDim, singleLine(rule.Reply), colorReset)
+	}
+	return nil
+}
+
+func autoReplyLogAction(ctx context.Context, cmd *cli.Command) error {
+	store, err := storage.NewSessionStorage()
+	if err != nil {
+		return scriptError(fmt.Errorf("failed to initialize session storage: %w", err))
+	}
+
+	log, err := store.LoadAutoReplyLog()
+	if err != nil {
+		return scriptError(fmt.Errorf("failed to load autoreply log: %w", err))
+	}
+
+	entries := log.Entries
+	if limit := cmd.Int("limit"); limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+
+	if cmd.Bool("json") {
+		if entries == nil {
+			entries = []storage.AutoReplyEntry{}
+		}
+		return printJSON(entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("Nothing was auto-sent yet")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "TIME\tRULE\tTHREAD\tSENDER\tREPLY")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t@%s\t%s\n",
+			e.Timestamp.Format(time.DateTime), e.Rule, e.ThreadTitle, e.Sender, truncateString(singleLine(e.Text), 60))
+	}
+	return nil
+}
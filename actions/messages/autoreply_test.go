@@ -0,0 +1,69 @@
+package messages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/storage"
+)
+
+// TestAutoReplyCooldownPerThread checks that two rules matching the same
+// thread answer it once per cooldown window, not once each
+func TestAutoReplyCooldownPerThread(t *testing.T) {
+	rules := &autoReplyRules{Rules: []*autoReplyRule{
+		{Name: "pricing", Match: "(?i)price", Reply: "See our prices"},
+		{Name: "hello", Match: "(?i)hi", Reply: "Hello!", Cooldown: "10m"},
+	}}
+	for _, rule := range rules.Rules {
+		if err := rule.compile(time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := &autoResponder{rules: rules, log: &storage.AutoReplyLog{LastSent: map[string]int64{}}}
+	now := time.Now()
+	e := instagram.Event{Type: instagram.EventMessage, ThreadID: "t1", SenderName: "alice", Text: "hi, what's the price?"}
+
+	got := a.candidates(e, now)
+	if len(got) != 2 || got[0].Name != "pricing" || got[1].Name != "hello" {
+		t.Fatalf("candidates before a reply = %v, want both rules in order", ruleNames(got))
+	}
+
+	// The first rule answered; the second must not answer the same thread
+	a.log.LastSent[e.ThreadID] = now.Unix()
+
+	tests := []struct {
+		name  string
+		event instagram.Event
+		at    time.Time
+		want  []string
+	}{
+		{"same thread right after", e, now.Add(time.Minute), nil},
+		{"same thread after the short cooldown", e, now.Add(20 * time.Minute), []string{"hello"}},
+		{"same thread after both cooldowns", e, now.Add(2 * time.Hour), []string{"pricing", "hello"}},
+		{"other thread", instagram.Event{Type: instagram.EventMessage, ThreadID: "t2", Text: e.Text}, now.Add(time.Minute), []string{"pricing", "hello"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ruleNames(a.candidates(tt.event, tt.at))
+			if len(got) != len(tt.want) {
+				t.Fatalf("candidates = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("candidates = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func ruleNames(rules []*autoReplyRule) []string {
+	var names []string
+	for _, rule := range rules {
+		names = append(names, rule.Name)
+	}
+	return names
+}
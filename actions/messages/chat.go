@@ -0,0 +1,373 @@
+package messages
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+)
+
+// chatView holds the state of a conversation opened in the interactive mode
+type chatView struct {
+	c        *instagram.Client
+	conv     instagram.Conversation
+	reader   *bufio.Reader
+	messages []instagram.Message
+
+	// visible is the last rendered list, indexed by the #n labels
+	visible []instagram.Message
+
+	// Messages sent from this view that the thread fetch hasn't returned yet
+	outgoing []instagram.Message
+
+	// Messages of this thread waiting in the outbox
+	queued []instagram.Message
+
+	// Typing expiry by user, from realtime events
+	typing map[string]time.Time
+
+	// How far each other participant has read, from thread fetches and
+	// realtime events
+	seenBy map[string]instagram.SeenReceipt
+
+	// draft is the unsent text saved by /edit
+	draft string
+}
+
+type chatCommand struct {
+	args  string
+	usage string
+	run   func(v *chatView, args string) error
+
+	// pauses is set for commands that print and wait for Enter themselves
+	pauses bool
+}
+
+var chatCommands map[string]chatCommand
+
+func init() {
+	chatCommands = map[string]chatCommand{
+		"help": {
+			usage: "Show chat commands",
+			run:   (*chatView).showHelp,
+		},
+		"photo": {
+			args:  "<path>",
+			usage: "Send a photo",
+			run:   mediaChatCommand("photo"),
+		},
+		"video": {
+			args:  "<path>",
+			usage: "Send a video",
+			run:   mediaChatCommand("video"),
+		},
+		"voice": {
+			args:  "<path>",
+			usage: "Send an audio file as a voice note",
+			run:   mediaChatCommand("voice"),
+		},
+		"react": {
+			args:  "<n> <emoji>",
+			usage: "React to message #n",
+			run:   (*chatView).react,
+		},
+		"like": {
+			args:  "<n>",
+			usage: "Like message #n",
+			run:   (*chatView).like,
+		},
+		"reply": {
+			args:  "<n> <text>",
+			usage: "Reply to message #n",
+			run:   (*chatView).reply,
+		},
+		"unsend": {
+			args:  "<n>",
+			usage: "Unsend your message #n",
+			run:   (*chatView).unsend,
+		},
+		"edit": {
+			args:  "[text]",
+			usage: "Write a message in $EDITOR (kept as a draft until sent)",
+			run:   (*chatView).edit,
+		},
+		"forward": {
+			args:  "<n> <to>",
+			usage: "Forward message #n to @user or a thread",
+			run:   (*chatView).forward,
+		},
+		"new": {
+			args:  "@user",
+			usage: "Start or open a 1:1 conversation",
+			run:   (*chatView).startNew,
+		},
+		"t": {
+			args:   "<name>",
+			usage:  "Fill in a saved template to check and send (lists them without a name)",
+			run:    (*chatView).sendTemplate,
+			pauses: true,
+		},
+		"at": {
+			args:  "<time> <text>",
+			usage: "Schedule a message (15:04, 2006-01-02 15:04 or 30m)",
+			run:   (*chatView).sendLater,
+		},
+		"search": {
+			args:   "<query>",
+			usage:  "Search this conversation's history",
+			run:    (*chatView).search,
+			pauses: true,
+		},
+	}
+}
+
+func (v *chatView) refresh() error {
+	threadResp, err := v.c.GetThread(v.conv.ThreadID, "", 30)
+	if err != nil {
+		return err
+	}
+
+	messages, _ := v.c.ThreadMessages(&threadResp.Thread)
+	v.setMessages(messages)
+	v.updateSeen(v.c.SeenReceipts(&threadResp.Thread))
+	return nil
+}
+
+// setMessages replaces the fetched messages and drops outgoing ones the
+// server now returns. The outbox is reread so flushed messages disappear.
+func (v *chatView) setMessages(messages []instagram.Message) {
+	v.messages = messages
+	v.outgoing = pruneDelivered(v.outgoing, messages)
+	v.queued = pruneDelivered(queuedMessages(v.conv.ThreadID), messages)
+}
+
+// arrange merges fetched and outgoing messages oldest first and remembers the
+// order for the #n labels
+func (v *chatView) arrange() []instagram.Message {
+	all := make([]instagram.Message, 0, len(v.messages)+len(v.outgoing)+len(v.queued))
+	all = append(all, v.messages...)
+	all = append(all, v.outgoing...)
+
+	// Messages queued from this view are already among the outgoing ones
+	outgoing := make(map[string]bool, len(v.outgoing))
+	for _, msg := range v.outgoing {
+		outgoing[msg.ClientContext] = true
+	}
+	for _, msg := range v.queued {
+		if !outgoing[msg.ClientContext] {
+			all = append(all, msg)
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Timestamp.Before(all[j].Timestamp)
+	})
+	v.markSeen(all)
+	v.visible = all
+
+	return all
+}
+
+func (v *chatView) render() {
+	status := v.statusLines()
+	if v.draft != "" {
+		status = append(status, draftLine(v.draft))
+	}
+	renderConversation(v.c, v.conv, v.arrange(), status)
+}
+
+// messageAt looks up a message by the #n label shown in the view
+func (v *chatView) messageAt(arg string) (*instagram.Message, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+	if err != nil || n < 1 || n > len(v.visible) {
+		return nil, fmt.Errorf("no message #%s", strings.TrimPrefix(arg, "#"))
+	}
+
+	msg := &v.visible[n-1]
+	if msg.ID == "" {
+		return nil, fmt.Errorf("message #%d hasn't been delivered yet", n)
+	}
+
+	return msg, nil
+}
+
+func (v *chatView) runCommand(input string) error {
+	name, args, _ := strings.Cut(strings.TrimPrefix(input, "/"), " ")
+
+	cmd, ok := chatCommands[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("unknown command /%s (type /help for a list)", name)
+	}
+
+	return cmd.run(v, strings.TrimSpace(args))
+}
+
+func (v *chatView) showHelp(string) error {
+	names := make([]string, 0, len(chatCommands))
+	for name := range chatCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("\n%sChat commands:%s\n", colorBold, colorReset)
+	for _, name := range names {
+		cmd := chatCommands[name]
+		fmt.Printf("  %s/%-8s%s %-14s %s%s%s\n", colorCyan, name, colorReset, cmd.args, colorDim, cmd.usage, colorReset)
+	}
+
+	fmt.Printf("\n%sPress Enter to continue%s", colorDim, colorReset)
+	v.reader.ReadString('\n')
+	return nil
+}
+
+// addOutgoing shows a message as pending and returns its index in v.outgoing
+func (v *chatView) addOutgoing(msg instagram.Message) int {
+	index := v.queueOutgoing(msg)
+
+	clearScreen()
+	v.render()
+
+	return index
+}
+
+// queueOutgoing records a message as pending and returns its index in
+// v.outgoing
+func (v *chatView) queueOutgoing(msg instagram.Message) int {
+	msg.SenderID = v.c.UserID()
+	msg.SenderName = "You"
+	msg.Timestamp = time.Now()
+	msg.IsFromMe = true
+	msg.State = instagram.SendPending
+	if msg.Type == "" {
+		msg.Type = "text"
+	}
+
+	v.outgoing = append(v.outgoing, msg)
+
+	return len(v.outgoing) - 1
+}
+
+// finishOutgoing records the result of a send started with addOutgoing
+func (v *chatView) finishOutgoing(index int, resp *instagram.SendMessageResponse, err error) {
+	msg := &v.outgoing[index]
+
+	if err != nil {
+		msg.State = instagram.SendFailed
+		fmt.Printf("\r%s✗ Failed to send: %v%s\n", colorRed, err, colorReset)
+		time.Sleep(2 * time.Second)
+		return
+	}
+
+	msg.State = instagram.SendSent
+	if resp != nil {
+		msg.ID = resp.Payload.ItemID
+		if resp.Payload.ClientContext != "" {
+			msg.ClientContext = resp.Payload.ClientContext
+		}
+	}
+}
+
+// outgoingMessage finds a message sent from this view by its client context
+func (v *chatView) outgoingMessage(clientContext string) *instagram.Message {
+	for i := range v.outgoing {
+		if v.outgoing[i].ClientContext == clientContext {
+			return &v.outgoing[i]
+		}
+	}
+	return nil
+}
+
+// settleOutgoing records the result of a send by its client context, for
+// callers where v.outgoing may have been pruned in the meantime
+func (v *chatView) settleOutgoing(clientContext string, resp *instagram.SendMessageResponse, err error) {
+	for i := range v.outgoing {
+		msg := &v.outgoing[i]
+		if msg.ClientContext != clientContext {
+			continue
+		}
+
+		if err != nil {
+			msg.State = instagram.SendFailed
+			return
+		}
+
+		msg.State = instagram.SendSent
+		if resp != nil {
+			msg.ID = resp.Payload.ItemID
+		}
+		return
+	}
+}
+
+func (v *chatView) sendText(text string, opts instagram.SendOptions, replyTo *instagram.QuotedMessage) {
+	if opts.ClientContext == "" {
+		opts.ClientContext = instagram.NewClientContext()
+	}
+
+	index := v.addOutgoing(instagram.Message{Text: text, ClientContext: opts.ClientContext, ReplyTo: replyTo})
+	resp, err := v.c.SendTextMessage(v.conv.ThreadID, text, opts)
+	if instagram.IsNetworkError(err) && v.queueOffline(&v.outgoing[index], opts) {
+		return
+	}
+	v.finishOutgoing(index, resp, err)
+}
+
+func (v *chatView) react(args string) error {
+	n, emoji, _ := strings.Cut(args, " ")
+	emoji = strings.TrimSpace(emoji)
+	if emoji == "" {
+		return fmt.Errorf("usage: /react <n> <emoji>")
+	}
+
+	msg, err := v.messageAt(n)
+	if err != nil {
+		return err
+	}
+
+	return v.c.SendReaction(v.conv.ThreadID, msg.ID, emoji)
+}
+
+func (v *chatView) like(args string) error {
+	msg, err := v.messageAt(strings.TrimSpace(args))
+	if err != nil {
+		return err
+	}
+
+	return v.c.SendReaction(v.conv.ThreadID, msg.ID, "❤️")
+}
+
+func (v *chatView) reply(args string) error {
+	n, text, _ := strings.Cut(args, " ")
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("usage: /reply <n> <text>")
+	}
+
+	msg, err := v.messageAt(n)
+	if err != nil {
+		return err
+	}
+
+	v.sendText(text, instagram.SendOptions{
+		ReplyToItemID:        msg.ID,
+		ReplyToClientContext: msg.ClientContext,
+	}, &instagram.QuotedMessage{ID: msg.ID, SenderName: msg.SenderName, Text: msg.Text})
+	return nil
+}
+
+func (v *chatView) unsend(args string) error {
+	msg, err := v.messageAt(strings.TrimSpace(args))
+	if err != nil {
+		return err
+	}
+
+	if !msg.IsFromMe {
+		return fmt.Errorf("you can only unsend your own messages")
+	}
+
+	return v.c.UnsendMessage(v.conv.ThreadID, msg.ID)
+}
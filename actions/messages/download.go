@@ -0,0 +1,287 @@
+package messages
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+)
+
+const manifestFile = "manifest.json"
+
+var downloadCommand = &cli.Command{
+	Name:      "download",
+	Usage:     "Download photos, videos and voice notes from a conversation",
+	ArgsUsage: "<thread|@user>",
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "types",
+			Value: []string{"photo", "video", "voice"},
+			Usage: "Attachment types to download",
+		},
+		&cli.StringFlag{
+			Name:  "out",
+			Usage: "Output directory (default: <title>_media)",
+		},
+		&cli.BoolFlag{
+			Name:  "shared",
+			Usage: "Also download posts, reels and stories shared into the thread",
+		},
+	},
+	Action: downloadAction,
+}
+
+// manifestEntry describes one downloaded file
+type manifestEntry struct {
+	File      string    `json:"file"`
+	ItemID    string    `json:"item_id"`
+	Kind      string    `json:"kind"`
+	ItemType  string    `json:"item_type"`
+	Shared    bool      `json:"shared,omitempty"`
+	Sender    string    `json:"sender"`
+	Timestamp time.Time `json:"timestamp"`
+	Size      int64     `json:"size"`
+}
+
+type manifest struct {
+	ThreadID string          `json:"thread_id"`
+	Title    string          `json:"title"`
+	Updated  time.Time       `json:"updated"`
+	Files    []manifestEntry `json:"files"`
+}
+
+func downloadAction(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() != 1 {
+		return cli.Exit("usage: messages download <thread|@user> [--types photo,video,voice] [--out dir]", exitUsage)
+	}
+
+	types := make(map[string]bool)
+	for _, t := range cmd.StringSlice("types") {
+		for _, part := range strings.Split(t, ",") {
+			part = strings.ToLower(strings.TrimSpace(part))
+			if part != "photo" && part != "video" && part != "voice" {
+				return cli.Exit(fmt.Sprintf("unknown type %q (use photo, video or voice)", part), exitUsage)
+			}
+			types[part] = true
+		}
+	}
+
+	c, store, err := loadClient(cmd)
+	if err != nil {
+		return scriptError(err)
+	}
+
+	// Ctrl+C stops the current download and keeps what finished
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	conv, err := resolveThread(c, cmd.Args().First())
+	if err != nil {
+		return scriptError(err)
+	}
+
+	thread, err := syncHistory(c, store, conv.ThreadID)
+	if err != nil {
+		return scriptError(err)
+	}
+
+	outDir := cmd.String("out")
+	if outDir == "" {
+		outDir = strings.TrimSuffix(exportFileName(instagram.ThreadTitle(*thread), "x"), ".x") + "_media"
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return scriptError(fmt.Errorf("failed to create %s: %w", outDir, err))
+	}
+
+	m := loadManifest(outDir)
+	m.ThreadID = thread.ThreadID
+	m.Title = instagram.ThreadTitle(*thread)
+
+	recorded := make(map[string]bool, len(m.Files))
+	for _, e := range m.Files {
+		recorded[e.File] = true
+	}
+
+	_, userMap := c.ThreadMessages(thread)
+
+	// Attachment URLs come from the synced history, and the CDN signatures of
+	// older items expire; their page is fetched again once when that happens
+	refreshed := make(map[string]bool)
+	refresh := func(index int) {
+		ids, err := refreshHistoryPage(c, store, thread, index)
+		if err != nil {
+			fmt.Printf("%s⚠ Failed to refresh expired media links: %v%s\n", colorYellow, err, colorReset)
+		}
+		refreshed[thread.Items[index].ItemID] = true
+		for _, id := range ids {
+			refreshed[id] = true
+		}
+	}
+
+	var downloaded, skipped, failed int
+	for i := range thread.Items {
+		if err := ctx.Err(); err != nil {
+			break
+		}
+
+		item := &thread.Items[i]
+		senderID, _ := item.UserID.Int64()
+		ts, _ := item.Timestamp.Int64()
+		sentAt := time.Unix(0, ts*1000)
+
+		for _, a := range item.Attachments() {
+			if !types[a.Kind] || (a.Shared && !cmd.Bool("shared")) {
+				continue
+			}
+
+			name := attachmentFileName(item.ItemID, sentAt, a)
+			path := filepath.Join(outDir, name)
+
+			if !refreshed[item.ItemID] && !fileExists(path) && instagram.MediaURLExpired(a.URL) {
+				refresh(i)
+				a = refreshedAttachment(item, a)
+			}
+
+			size, err := downloadAttachment(ctx, c, a.URL, path)
+			if errors.Is(err, instagram.ErrMediaExpired) && !refreshed[item.ItemID] {
+				refresh(i)
+				a = refreshedAttachment(item, a)
+				size, err = downloadAttachment(ctx, c, a.URL, path)
+			}
+
+			switch {
+			case err != nil:
+				fmt.Printf("%s✗ %s: %v%s\n", colorRed, name, err, colorReset)
+				failed++
+				continue
+			case size < 0:
+				skipped++
+			default:
+				fmt.Printf("%s✓ %s%s\n", colorGreen, name, colorReset)
+				downloaded++
+			}
+
+			if !recorded[name] {
+				recorded[name] = true
+				m.Files = append(m.Files, manifestEntry{
+					File:      name,
+					ItemID:    item.ItemID,
+					Kind:      a.Kind,
+					ItemType:  item.ItemType,
+					Shared:    a.Shared,
+					Sender:    userMap[senderID],
+					Timestamp: sentAt,
+					Size:      fileSizeOf(path),
+				})
+			}
+		}
+	}
+
+	if err := saveManifest(outDir, m); err != nil {
+		return scriptError(err)
+	}
+
+	fmt.Printf("\n📁 %s: %d downloaded, %d already present, %d failed\n", outDir, downloaded, skipped, failed)
+
+	if failed > 0 {
+		return cli.Exit(fmt.Sprintf("%d download(s) failed", failed), exitFailure)
+	}
+	return nil
+}
+
+// refreshedAttachment finds a in the refreshed item by kind and carousel
+// position, keeping a when the item no longer has it
+func refreshedAttachment(item *instagram.MessageItem, a instagram.Attachment) instagram.Attachment {
+	for _, fresh := range item.Attachments() {
+		if fresh.Kind == a.Kind && fresh.Index == a.Index && fresh.Shared == a.Shared {
+			return fresh
+		}
+	}
+	return a
+}
+
+// attachmentFileName is stable across runs so re-running skips finished files
+func attachmentFileName(itemID string, sentAt time.Time, a instagram.Attachment) string {
+	name := fmt.Sprintf("%s_%s_%s", sentAt.UTC().Format("20060102-150405"), a.Kind, itemID)
+	if a.Index > 0 {
+		name += fmt.Sprintf("_%02d", a.Index)
+	}
+	return name + "." + a.Ext
+}
+
+// downloadAttachment fetches url into path. It returns -1 when the file is
+// already there.
+func downloadAttachment(ctx context.Context, c *instagram.Client, url string, path string) (int64, error) {
+	if fileExists(path) {
+		return -1, nil
+	}
+
+	tmpPath := path + ".part"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return 0, err
+	}
+
+	size, err := c.DownloadMedia(ctx, url, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+
+	return size, os.Rename(tmpPath, path)
+}
+
+func loadManifest(dir string) *manifest {
+	m := &manifest{}
+
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if err == nil {
+		_ = json.Unmarshal(data, m)
+	}
+
+	return m
+}
+
+func saveManifest(dir string, m *manifest) error {
+	sort.Slice(m.Files, func(i, j int) bool {
+		return m.Files[i].File < m.Files[j].File
+	})
+	m.Updated = time.Now()
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, manifestFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+func fileExists(path string) bool {
+	stat, err := os.Stat(path)
+	return err == nil && stat.Size() > 0
+}
+
+func fileSizeOf(path string) int64 {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return stat.Size()
+}
@@ -0,0 +1,98 @@
+package messages
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/storage"
+)
+
+// loadDrafts returns the saved drafts by thread ID, empty when they can't
+// be read
+func loadDrafts() map[string]storage.Draft {
+	store, err := storage.NewSessionStorage()
+	if err != nil {
+		return map[string]storage.Draft{}
+	}
+
+	drafts, err := store.LoadDrafts()
+	if err != nil {
+		return map[string]storage.Draft{}
+	}
+	return drafts.Threads
+}
+
+// saveDraft stores the draft of a thread, or drops it when text is blank
+func saveDraft(threadID string, text string) error {
+	store, err := storage.NewSessionStorage()
+	if err != nil {
+		return fmt.Errorf("failed to initialize session storage: %w", err)
+	}
+
+	if err := store.SaveDraft(threadID, text); err != nil {
+		return fmt.Errorf("failed to save draft: %w", err)
+	}
+	return nil
+}
+
+// edit is /edit: it writes a message in $EDITOR, starting from the thread's
+// draft or the given text. Whatever was written stays a draft until it's
+// sent.
+func (v *chatView) edit(args string) error {
+	text := v.draft
+	if text == "" {
+		text = args
+	}
+	return v.editFrom(text)
+}
+
+// editFrom opens text in $EDITOR, then asks before sending the result
+func (v *chatView) editFrom(text string) error {
+	for {
+		stopTyping := typingWhileEditing(v.c, v.conv)
+		edited, err := editText(text)
+		stopTyping()
+		if err != nil {
+			return err
+		}
+
+		if strings.TrimSpace(edited) == "" {
+			v.draft = ""
+			return saveDraft(v.conv.ThreadID, "")
+		}
+
+		text = edited
+		v.draft = text
+		if err := saveDraft(v.conv.ThreadID, text); err != nil {
+			return err
+		}
+
+		clearScreen()
+		fmt.Printf("%sTo %s:%s\n\n%s\n\n", colorBold, v.conv.Title, colorReset, text)
+		fmt.Printf("Send it? [Y]es, [e]dit, [n]o (keep the draft): ")
+
+		answer, _ := v.reader.ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "", "y", "yes":
+			clientContext := instagram.NewClientContext()
+			v.sendText(text, instagram.SendOptions{ClientContext: clientContext}, nil)
+
+			// A failed send keeps the draft to try again
+			if msg := v.outgoingMessage(clientContext); msg != nil && msg.State == instagram.SendFailed {
+				return nil
+			}
+			v.draft = ""
+			return saveDraft(v.conv.ThreadID, "")
+		case "e", "edit":
+			continue
+		default:
+			return nil
+		}
+	}
+}
+
+// draftLine is the reminder shown under a conversation with a saved draft
+func draftLine(draft string) string {
+	return fmt.Sprintf("✎ Draft: %s (/edit to finish it)", truncateString(singleLine(draft), 40))
+}
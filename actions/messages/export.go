@@ -0,0 +1,266 @@
+package messages
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+)
+
+var exportCommand = &cli.Command{
+	Name:      "export",
+	Usage:     "Export the full history of a conversation",
+	ArgsUsage: "<thread|@user>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "format",
+			Aliases: []string{"f"},
+			Value:   "markdown",
+			Usage:   "Transcript format: json, markdown, html or txt",
+		},
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "Output file (default: <title>.<ext>, - for stdout)",
+		},
+		&cli.BoolFlag{
+			Name:  "offline",
+			Usage: "Export the locally synced history without fetching new messages (by thread ID or @user)",
+		},
+	},
+	Action: exportAction,
+}
+
+var exportExtensions = map[string]string{
+	"json":     "json",
+	"markdown": "md",
+	"md":       "md",
+	"html":     "html",
+	"txt":      "txt",
+}
+
+// transcript is a whole conversation ready to be written out
+type transcript struct {
+	ThreadID     string              `json:"thread_id"`
+	Title        string              `json:"title"`
+	Participants []string            `json:"participants"`
+	ExportedAt   time.Time           `json:"exported_at"`
+	Messages     []instagram.Message `json:"messages"`
+}
+
+func exportAction(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() != 1 {
+		return cli.Exit("usage: messages export <thread|@user> [--format json|markdown|html|txt]", exitUsage)
+	}
+
+	format := strings.ToLower(cmd.String("format"))
+	ext, ok := exportExtensions[format]
+	if !ok {
+		return cli.Exit(fmt.Sprintf("unknown format %q (use json, markdown, html or txt)", format), exitUsage)
+	}
+
+	offline := cmd.Bool("offline")
+	if arg := cmd.Args().First(); offline && !isThreadID(arg) && !strings.HasPrefix(arg, "@") {
+		return cli.Exit("--offline takes a thread ID or @user; inbox numbers need the inbox", exitUsage)
+	}
+
+	c, store, err := loadClient(cmd)
+	if err != nil {
+		return scriptError(err)
+	}
+
+	var thread *instagram.Thread
+	if offline {
+		thread, err = loadSyncedThread(store, cmd.Args().First())
+	} else {
+		var conv instagram.Conversation
+		conv, err = resolveThread(c, cmd.Args().First())
+		if err == nil {
+			thread, err = syncHistory(c, store, conv.ThreadID)
+		}
+	}
+	if err != nil {
+		return scriptError(err)
+	}
+
+	t := newTranscript(c, thread)
+
+	output := cmd.String("output")
+	if output == "" {
+		output = exportFileName(t.Title, ext)
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "-" {
+		f, err := os.Create(output)
+		if err != nil {
+			return scriptError(fmt.Errorf("failed to create %s: %w", output, err))
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := writeTranscript(w, format, t); err != nil {
+		return scriptError(fmt.Errorf("failed to write transcript: %w", err))
+	}
+
+	if output != "-" {
+		fmt.Printf("%s✓ Exported %d messages to %s%s\n", colorGreen, len(t.Messages), output, colorReset)
+	}
+	return nil
+}
+
+func newTranscript(c *instagram.Client, thread *instagram.Thread) *transcript {
+	messages, _ := c.ThreadMessages(thread)
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Timestamp.Before(messages[j].Timestamp)
+	})
+
+	// "You" reads oddly in a record kept for someone else
+	if c.Username != "" {
+		for i := range messages {
+			if messages[i].IsFromMe {
+				messages[i].SenderName = c.Username
+			}
+		}
+	}
+
+	t := &transcript{
+		ThreadID:   thread.ThreadID,
+		Title:      instagram.ThreadTitle(*thread),
+		ExportedAt: time.Now(),
+		Messages:   messages,
+	}
+
+	for _, u := range thread.Users {
+		t.Participants = append(t.Participants, u.Username)
+	}
+	if c.Username != "" {
+		t.Participants = append(t.Participants, c.Username)
+	}
+
+	if t.Messages == nil {
+		t.Messages = []instagram.Message{}
+	}
+
+	return t
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^\p{L}\p{N}._-]+`)
+
+func exportFileName(title string, ext string) string {
+	name := strings.Trim(unsafeFileChars.ReplaceAllString(title, "_"), "_")
+	if name == "" {
+		name = "conversation"
+	}
+	return filepath.Clean(name + "." + ext)
+}
+
+func writeTranscript(w io.Writer, format string, t *transcript) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(t)
+	case "markdown", "md":
+		return writeMarkdownTranscript(w, t)
+	case "html":
+		return htmlTranscript.Execute(w, t)
+	default:
+		return writeTextTranscript(w, t)
+	}
+}
+
+func writeTextTranscript(w io.Writer, t *transcript) error {
+	fmt.Fprintf(w, "%s\n", t.Title)
+	fmt.Fprintf(w, "Participants: %s\n", strings.Join(t.Participants, ", "))
+	fmt.Fprintf(w, "Exported: %s\n\n", t.ExportedAt.Format(time.DateTime))
+
+	for _, msg := range t.Messages {
+		if _, err := fmt.Fprintln(w, formatPlainMessage(msg)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeMarkdownTranscript(w io.Writer, t *transcript) error {
+	fmt.Fprintf(w, "# %s\n\n", t.Title)
+	fmt.Fprintf(w, "- **Participants:** %s\n", strings.Join(t.Participants, ", "))
+	fmt.Fprintf(w, "- **Messages:** %d\n", len(t.Messages))
+	fmt.Fprintf(w, "- **Exported:** %s\n", t.ExportedAt.Format(time.DateTime))
+
+	var lastDate string
+	for _, msg := range t.Messages {
+		if date := msg.Timestamp.Format("Mon, Jan 2 2006"); date != lastDate {
+			fmt.Fprintf(w, "\n## %s\n\n", date)
+			lastDate = date
+		}
+
+		fmt.Fprintf(w, "**%s** · %s\n", msg.SenderName, msg.Timestamp.Format("15:04"))
+		if msg.ReplyTo != nil {
+			fmt.Fprintf(w, "> ↪ %s: %s\n>\n", msg.ReplyTo.SenderName, singleLine(msg.ReplyTo.Text))
+		}
+		fmt.Fprintf(w, "%s\n", strings.ReplaceAll(msg.Text, "\n", "  \n"))
+
+		if len(msg.Reactions) > 0 {
+			reactions := make([]string, 0, len(msg.Reactions))
+			for _, r := range msg.Reactions {
+				reactions = append(reactions, r.Emoji+" "+r.SenderName)
+			}
+			fmt.Fprintf(w, "\n_%s_\n", strings.Join(reactions, " · "))
+		}
+
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var htmlTranscript = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.Format("Mon, Jan 2 2006") },
+	"time": func(t time.Time) string { return t.Format("15:04") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, sans-serif; max-width: 720px; margin: 2em auto; color: #222; }
+.meta { color: #777; font-size: 0.9em; }
+.date { text-align: center; color: #999; margin: 1.5em 0 0.5em; }
+.msg { margin: 0.4em 0; padding: 0.5em 0.8em; border-radius: 12px; background: #efefef; max-width: 75%; white-space: pre-wrap; }
+.me { margin-left: auto; background: #3797f0; color: #fff; }
+.who { font-weight: bold; font-size: 0.85em; }
+.when { font-size: 0.75em; opacity: 0.7; margin-left: 0.5em; }
+.quote { border-left: 3px solid #aaa; padding-left: 0.5em; font-size: 0.85em; opacity: 0.8; }
+.reactions { font-size: 0.8em; margin-top: 0.3em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">Participants: {{range $i, $p := .Participants}}{{if $i}}, {{end}}{{$p}}{{end}}<br>
+Messages: {{len .Messages}} · Exported {{.ExportedAt.Format "2006-01-02 15:04:05"}}</p>
+{{$last := ""}}{{range .Messages}}{{$d := date .Timestamp}}{{if ne $d $last}}<div class="date">{{$d}}</div>{{$last = $d}}{{end}}
+<div class="msg{{if .IsFromMe}} me{{end}}">
+<div><span class="who">{{.SenderName}}</span><span class="when">{{time .Timestamp}}</span></div>
+{{with .ReplyTo}}<div class="quote">↪ {{.SenderName}}: {{.Text}}</div>{{end}}
+<div>{{.Text}}</div>
+{{if .Reactions}}<div class="reactions">{{range .Reactions}}{{.Emoji}} {{.SenderName}} {{end}}</div>{{end}}
+</div>{{end}}
+</body>
+</html>
+`))
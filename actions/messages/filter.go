@@ -0,0 +1,349 @@
+package messages
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+)
+
+// Conversation orders accepted by --sort
+const (
+	sortRecent = "recent"
+	sortUnread = "unread"
+	sortTitle  = "title"
+)
+
+var inboxSorts = []string{sortRecent, sortUnread, sortTitle}
+
+// inboxFlags select and order the conversations shown by the inbox, list
+// and unread. They live on the messages command so every subcommand sees them.
+func inboxFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "folder",
+			Value: instagram.FolderAll,
+			Usage: "Inbox folder: all, primary, general or requests",
+		},
+		&cli.BoolFlag{
+			Name:  "unread",
+			Usage: "Only conversations with unread messages",
+		},
+		&cli.BoolFlag{
+			Name:  "muted",
+			Usage: "Only muted conversations",
+		},
+		&cli.BoolFlag{
+			Name:  "pinned",
+			Usage: "Only pinned conversations",
+		},
+		&cli.BoolFlag{
+			Name:  "groups",
+			Usage: "Only group conversations",
+		},
+		&cli.BoolFlag{
+			Name:  "direct",
+			Usage: "Only 1:1 conversations",
+		},
+		&cli.StringFlag{
+			Name:  "with",
+			Usage: "Only conversations that include @user",
+		},
+		&cli.StringFlag{
+			Name:  "sort",
+			Value: sortRecent,
+			Usage: "Order: recent, unread (unread first) or title",
+		},
+		&cli.IntFlag{
+			Name:  "pages",
+			Value: 1,
+			Usage: "Inbox pages of 50 conversations to load (0 for all)",
+		},
+	}
+}
+
+// inboxFilter narrows and orders a list of conversations
+type inboxFilter struct {
+	unread bool
+	muted  bool
+	pinned bool
+	kind   string // "group", "direct" or "" for both
+	with   string
+	sort   string
+}
+
+// inboxFilterFromFlags reads the inbox flags of cmd
+func inboxFilterFromFlags(cmd *cli.Command) (inboxFilter, error) {
+	f := inboxFilter{
+		unread: cmd.Bool("unread"),
+		muted:  cmd.Bool("muted"),
+		pinned: cmd.Bool("pinned"),
+		with:   strings.TrimPrefix(cmd.String("with"), "@"),
+		sort:   sortRecent,
+	}
+
+	switch {
+	case cmd.Bool("groups") && cmd.Bool("direct"):
+		return f, fmt.Errorf("--groups and --direct can't be combined")
+	case cmd.Bool("groups"):
+		f.kind = "group"
+	case cmd.Bool("direct"):
+		f.kind = "direct"
+	}
+
+	if s := cmd.String("sort"); s != "" {
+		if err := f.set("sort:" + s); err != nil {
+			return f, err
+		}
+	}
+
+	return f, nil
+}
+
+// set applies one filter term as typed in the interactive modes: unread,
+// muted, pinned, group, direct, @user, sort:<order>, or all to reset
+func (f *inboxFilter) set(term string) error {
+	term = strings.ToLower(strings.TrimSpace(term))
+
+	if order, ok := strings.CutPrefix(term, "sort:"); ok {
+		for _, s := range inboxSorts {
+			if order == s {
+				f.sort = s
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown sort %q (use %s)", order, strings.Join(inboxSorts, ", "))
+	}
+
+	if username, ok := strings.CutPrefix(term, "@"); ok {
+		f.with = username
+		return nil
+	}
+
+	switch term {
+	case "all", "none", "clear":
+		*f = inboxFilter{sort: f.sort}
+	case "unread":
+		f.unread = !f.unread
+	case "muted":
+		f.muted = !f.muted
+	case "pinned":
+		f.pinned = !f.pinned
+	case "group", "groups":
+		f.kind = toggle(f.kind, "group")
+	case "direct", "dm", "1:1":
+		f.kind = toggle(f.kind, "direct")
+	default:
+		return fmt.Errorf("unknown filter %q (use unread, muted, pinned, group, direct, @user or all)", term)
+	}
+	return nil
+}
+
+func toggle(current, value string) string {
+	if current == value {
+		return ""
+	}
+	return value
+}
+
+func (f inboxFilter) active() bool {
+	return f.unread || f.muted || f.pinned || f.kind != "" || f.with != ""
+}
+
+// String describes the filter for headers, e.g. "unread · group · @alice"
+func (f inboxFilter) String() string {
+	var parts []string
+	if f.unread {
+		parts = append(parts, "unread")
+	}
+	if f.muted {
+		parts = append(parts, "muted")
+	}
+	if f.pinned {
+		parts = append(parts, "pinned")
+	}
+	if f.kind != "" {
+		parts = append(parts, f.kind)
+	}
+	if f.with != "" {
+		parts = append(parts, "@"+f.with)
+	}
+	if f.sort != "" && f.sort != sortRecent {
+		parts = append(parts, "by "+f.sort)
+	}
+	return strings.Join(parts, " · ")
+}
+
+func (f inboxFilter) matches(conv instagram.Conversation) bool {
+	switch {
+	case f.unread && conv.UnreadCount == 0:
+		return false
+	case f.muted && !conv.IsMuted:
+		return false
+	case f.pinned && !conv.IsPinned:
+		return false
+	case f.kind == "group" && !conv.IsGroup:
+		return false
+	case f.kind == "direct" && conv.IsGroup:
+		return false
+	}
+
+	if f.with != "" {
+		for _, u := range conv.Users {
+			if strings.EqualFold(u, f.with) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// apply returns the matching conversations in the filter's order. The
+// recent order keeps the server's, which also keeps pinned threads on top.
+func (f inboxFilter) apply(conversations []instagram.Conversation) []instagram.Conversation {
+	out := make([]instagram.Conversation, 0, len(conversations))
+	for _, conv := range conversations {
+		if f.matches(conv) {
+			out = append(out, conv)
+		}
+	}
+
+	switch f.sort {
+	case sortUnread:
+		sort.SliceStable(out, func(i, j int) bool {
+			return out[i].UnreadCount > 0 && out[j].UnreadCount == 0
+		})
+	case sortTitle:
+		sort.SliceStable(out, func(i, j int) bool {
+			return strings.ToLower(out[i].Title) < strings.ToLower(out[j].Title)
+		})
+	}
+
+	return out
+}
+
+// fetchInbox loads up to pages pages of a folder, or all of it when pages is
+// 0 or less
+func fetchInbox(c *instagram.Client, folder string, pages int) (*instagram.ConversationList, error) {
+	list, err := c.GetFolder(folder, "", 50)
+	if err != nil {
+		return nil, err
+	}
+
+	for n := 1; list.HasOlder && (pages <= 0 || n < pages); n++ {
+		// Stay well under the request rate Instagram tolerates
+		time.Sleep(500 * time.Millisecond)
+
+		if err := fetchMoreConversations(c, folder, list); err != nil {
+			return nil, err
+		}
+	}
+
+	return list, nil
+}
+
+// fetchMoreConversations appends the next page of a folder to list
+func fetchMoreConversations(c *instagram.Client, folder string, list *instagram.ConversationList) error {
+	next, err := c.GetFolder(folder, list.OldestCursor, 50)
+	if err != nil {
+		return err
+	}
+
+	list.Conversations = appendConversations(list.Conversations, next.Conversations)
+	list.HasOlder = next.HasOlder && next.OldestCursor != list.OldestCursor
+	list.OldestCursor = next.OldestCursor
+	return nil
+}
+
+// appendConversations adds the conversations of a later page, skipping
+// threads that moved between pages while paging
+func appendConversations(list []instagram.Conversation, page []instagram.Conversation) []instagram.Conversation {
+	seen := make(map[string]bool, len(list))
+	for _, conv := range list {
+		seen[conv.ThreadID] = true
+	}
+
+	for _, conv := range page {
+		if !seen[conv.ThreadID] {
+			list = append(list, conv)
+		}
+	}
+	return list
+}
+
+// inboxState is the folder, filter and paging the interactive modes show
+type inboxState struct {
+	folder string
+	filter inboxFilter
+	pages  int
+}
+
+var inboxView = &inboxState{
+	folder: instagram.FolderAll,
+	filter: inboxFilter{sort: sortRecent},
+	pages:  1,
+}
+
+// setInboxView applies the inbox flags of cmd to the interactive modes
+func setInboxView(cmd *cli.Command) error {
+	folder, err := instagram.ParseFolder(cmd.String("folder"))
+	if err != nil {
+		return err
+	}
+
+	filter, err := inboxFilterFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	inboxView.folder = folder
+	inboxView.filter = filter
+	inboxView.pages = cmd.Int("pages")
+	return nil
+}
+
+// describe summarizes the folder and filter, e.g. "Primary · unread"
+func (s *inboxState) describe() string {
+	desc := strings.ToUpper(s.folder[:1]) + s.folder[1:]
+	if f := s.filter.String(); f != "" {
+		desc += " · " + f
+	}
+	return desc
+}
+
+// runInboxCommand applies a command typed at the line-mode inbox prompt:
+// filter <term>, folder <name>, sort <order> or more. It reports whether the
+// input was one of them and whether the inbox must be fetched again.
+func (s *inboxState) runCommand(input string) (handled bool, refetch bool, err error) {
+	name, arg, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(input), "/"), " ")
+	arg = strings.TrimSpace(arg)
+
+	switch strings.ToLower(name) {
+	case "f", "filter":
+		if arg == "" {
+			arg = "all"
+		}
+		for _, term := range strings.Fields(arg) {
+			if err := s.filter.set(term); err != nil {
+				return true, false, err
+			}
+		}
+		return true, false, nil
+	case "folder":
+		folder, err := instagram.ParseFolder(arg)
+		if err != nil {
+			return true, false, err
+		}
+		s.folder = folder
+		return true, true, nil
+	case "sort":
+		return true, false, s.filter.set("sort:" + arg)
+	}
+
+	return false, false, nil
+}
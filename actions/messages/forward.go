@@ -0,0 +1,157 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+)
+
+var forwardCommand = &cli.Command{
+	Name:      "forward",
+	Aliases:   []string{"fwd"},
+	Usage:     "Pass a message, post or reel on to other conversations (item IDs are in messages show --json)",
+	ArgsUsage: "<thread|@user> <item_id> <thread|@user>...",
+	Action:    forwardAction,
+}
+
+// unforwardable are item types that can't be passed on: media sent
+// directly has no ID to share again and quoting it says nothing
+var unforwardable = map[string]string{
+	"media":          "photos and videos",
+	"raven_media":    "disappearing photos and videos",
+	"voice_media":    "voice messages",
+	"animated_media": "GIFs and stickers",
+	"like":           "likes",
+}
+
+// forwardTarget resolves where to forward to. @user opens the 1:1 thread,
+// creating it if the two of you never talked.
+func forwardTarget(c *instagram.Client, arg string) (instagram.Conversation, error) {
+	if strings.HasPrefix(arg, "@") {
+		return startConversation(c, arg)
+	}
+	return resolveThread(c, arg)
+}
+
+// forwardText quotes a message for forwarding as text
+func forwardText(msg instagram.Message) string {
+	from := "@" + msg.SenderName
+	if msg.IsFromMe {
+		from = "me"
+	}
+
+	lines := strings.Split(strings.TrimSpace(msg.Text), "\n")
+	for i, line := range lines {
+		lines[i] = "> " + line
+	}
+	return fmt.Sprintf("Forwarded from %s:\n%s", from, strings.Join(lines, "\n"))
+}
+
+// forwardMessage shares msg's post, reel or story with conv, or quotes it
+// when it has none
+func forwardMessage(c *instagram.Client, msg instagram.Message, conv instagram.Conversation) error {
+	if msg.Shared != nil {
+		_, err := c.ShareMedia(conv.ThreadID, *msg.Shared, instagram.SendOptions{})
+		return err
+	}
+
+	if what, ok := unforwardable[msg.Type]; ok {
+		return fmt.Errorf("%s can't be forwarded", what)
+	}
+	if strings.TrimSpace(msg.Text) == "" {
+		return fmt.Errorf("the message has nothing to forward")
+	}
+
+	_, err := c.SendTextMessage(conv.ThreadID, forwardText(msg), instagram.SendOptions{})
+	return err
+}
+
+// findForwarded picks a message by item ID. Only /forward takes #n, since
+// only the chat view shows the labels it counts by.
+func findForwarded(messages []instagram.Message, itemID string) (instagram.Message, error) {
+	for _, msg := range messages {
+		if msg.ID == itemID {
+			return msg, nil
+		}
+	}
+
+	return instagram.Message{}, fmt.Errorf("no message %q among the latest %d (item IDs are in messages show --json)", itemID, len(messages))
+}
+
+func forwardAction(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() < 3 {
+		return cli.Exit("usage: messages forward <thread|@user> <item_id> <thread|@user>...", exitUsage)
+	}
+
+	args := cmd.Args().Slice()
+
+	c, _, err := loadClient(cmd)
+	if err != nil {
+		return scriptError(err)
+	}
+
+	from, err := resolveThread(c, args[0])
+	if err != nil {
+		return scriptError(err)
+	}
+
+	messages, _, err := c.GetMessages(from.ThreadID, 50)
+	if err != nil {
+		return scriptError(fmt.Errorf("failed to fetch messages: %w", err))
+	}
+
+	msg, err := findForwarded(messages, args[1])
+	if err != nil {
+		return cli.Exit(err.Error(), exitNotFound)
+	}
+
+	failed := 0
+	for _, arg := range args[2:] {
+		to, err := forwardTarget(c, arg)
+		if err == nil {
+			err = forwardMessage(c, msg, to)
+		}
+		if err != nil {
+			fmt.Printf("%s✗ %s: %v%s\n", colorRed, arg, err, colorReset)
+			failed++
+			continue
+		}
+
+		fmt.Printf("%s✓ Forwarded to %s%s\n", colorGreen, to.Title, colorReset)
+	}
+
+	if failed > 0 {
+		return cli.Exit("", exitFailure)
+	}
+	return nil
+}
+
+// forward is /forward: it passes message #n on to another conversation
+func (v *chatView) forward(args string) error {
+	n, target, _ := strings.Cut(args, " ")
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return fmt.Errorf("usage: /forward <n> @user|thread")
+	}
+
+	msg, err := v.messageAt(n)
+	if err != nil {
+		return err
+	}
+
+	to, err := forwardTarget(v.c, target)
+	if err != nil {
+		return err
+	}
+
+	if err := forwardMessage(v.c, *msg, to); err != nil {
+		return fmt.Errorf("failed to forward to %s: %w", to.Title, err)
+	}
+
+	fmt.Printf("%s✓ Forwarded to %s%s\n", colorGreen, to.Title, colorReset)
+	return nil
+}
@@ -0,0 +1,368 @@
+package messages
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+)
+
+var groupCommand = &cli.Command{
+	Name:    "group",
+	Aliases: []string{"groups"},
+	Usage:   "Create and manage group conversations",
+	Commands: []*cli.Command{
+		{
+			Name:      "create",
+			Usage:     "Start a group with two or more people",
+			ArgsUsage: "@user @user...",
+			Flags: []cli.Flag{
+				jsonFlag(),
+				&cli.StringFlag{
+					Name:    "title",
+					Aliases: []string{"t"},
+					Usage:   "Group name",
+				},
+			},
+			Action: groupCreateAction,
+		},
+		{
+			Name:      "rename",
+			Usage:     "Change a group's name",
+			ArgsUsage: "<thread> <title>",
+			Action:    groupRenameAction,
+		},
+		{
+			Name:      "add",
+			Usage:     "Add people to a group",
+			ArgsUsage: "<thread> @user...",
+			Action:    groupAddAction,
+		},
+		{
+			Name:      "remove",
+			Aliases:   []string{"rm"},
+			Usage:     "Remove people from a group (admins only)",
+			ArgsUsage: "<thread> @user...",
+			Action:    groupRemoveAction,
+		},
+		{
+			Name:      "leave",
+			Usage:     "Leave a group",
+			ArgsUsage: "<thread>",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "yes",
+					Aliases: []string{"y"},
+					Usage:   "Skip the confirmation prompt",
+				},
+			},
+			Action: groupLeaveAction,
+		},
+		{
+			Name:      "members",
+			Usage:     "List the members and admins of a group",
+			ArgsUsage: "<thread>",
+			Flags:     []cli.Flag{jsonFlag()},
+			Action:    groupMembersAction,
+		},
+	},
+}
+
+// groupMember is one entry of the members listing
+type groupMember struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	FullName string `json:"full_name,omitempty"`
+	Admin    bool   `json:"admin"`
+	Inviter  bool   `json:"inviter,omitempty"`
+}
+
+type groupSummary struct {
+	ThreadID string        `json:"thread_id"`
+	Title    string        `json:"title"`
+	Members  []groupMember `json:"members"`
+}
+
+func groupCreateAction(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() < 2 {
+		return cli.Exit("usage: messages group create @user @user... [--title name]", exitUsage)
+	}
+
+	c, _, err := loadClient(cmd)
+	if err != nil {
+		return scriptError(err)
+	}
+
+	users, err := lookupUsers(c, cmd.Args().Slice())
+	if err != nil {
+		return scriptError(err)
+	}
+
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.Pk.String())
+	}
+
+	thread, err := c.CreateGroupThread(ids, cmd.String("title"))
+	if err != nil {
+		return scriptError(fmt.Errorf("failed to create group: %w", err))
+	}
+
+	if cmd.Bool("json") {
+		return printJSON(threadToGroupSummary(*thread))
+	}
+
+	fmt.Printf("%s✓ Created %s%s %s(%s)%s\n", colorGreen, instagram.ThreadTitle(*thread), colorReset, colorDim, thread.ThreadID, colorReset)
+	return nil
+}
+
+func groupRenameAction(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() < 2 {
+		return cli.Exit("usage: messages group rename <thread> <title>", exitUsage)
+	}
+
+	title := strings.TrimSpace(strings.Join(cmd.Args().Tail(), " "))
+	if title == "" {
+		return cli.Exit("the title cannot be empty", exitUsage)
+	}
+
+	c, conv, err := loadGroup(cmd)
+	if err != nil {
+		return scriptError(err)
+	}
+
+	if err := c.RenameThread(conv.ThreadID, title); err != nil {
+		return scriptError(fmt.Errorf("failed to rename %s: %w", conv.Title, err))
+	}
+
+	fmt.Printf("%s✓ Renamed %s to %s%s\n", colorGreen, conv.Title, title, colorReset)
+	return nil
+}
+
+func groupAddAction(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() < 2 {
+		return cli.Exit("usage: messages group add <thread> @user...", exitUsage)
+	}
+
+	c, conv, err := loadGroup(cmd)
+	if err != nil {
+		return scriptError(err)
+	}
+
+	users, err := lookupUsers(c, cmd.Args().Tail())
+	if err != nil {
+		return scriptError(err)
+	}
+
+	ids := make([]string, 0, len(users))
+	names := make([]string, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.Pk.String())
+		names = append(names, "@"+u.Username)
+	}
+
+	if err := c.AddThreadUsers(conv.ThreadID, ids); err != nil {
+		return scriptError(fmt.Errorf("failed to add members to %s: %w", conv.Title, err))
+	}
+
+	fmt.Printf("%s✓ Added %s to %s%s\n", colorGreen, strings.Join(names, ", "), conv.Title, colorReset)
+	return nil
+}
+
+func groupRemoveAction(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() < 2 {
+		return cli.Exit("usage: messages group remove <thread> @user...", exitUsage)
+	}
+
+	c, conv, err := loadGroup(cmd)
+	if err != nil {
+		return scriptError(err)
+	}
+
+	thread, err := c.GetThread(conv.ThreadID, "", 1)
+	if err != nil {
+		return scriptError(fmt.Errorf("failed to fetch %s: %w", conv.Title, err))
+	}
+
+	var ids, names []string
+	for _, arg := range cmd.Args().Tail() {
+		username := strings.TrimPrefix(arg, "@")
+		member := findMember(thread.Thread.Users, username)
+		if member == nil {
+			return cli.Exit(fmt.Sprintf("@%s is not a member of %s", username, conv.Title), exitNotFound)
+		}
+		ids = append(ids, member.Pk.String())
+		names = append(names, "@"+member.Username)
+	}
+
+	if err := c.RemoveThreadUsers(conv.ThreadID, ids); err != nil {
+		return scriptError(fmt.Errorf("failed to remove members from %s: %w", conv.Title, err))
+	}
+
+	fmt.Printf("%s✓ Removed %s from %s%s\n", colorGreen, strings.Join(names, ", "), conv.Title, colorReset)
+	return nil
+}
+
+func groupLeaveAction(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() != 1 {
+		return cli.Exit("usage: messages group leave <thread> [--yes]", exitUsage)
+	}
+
+	c, conv, err := loadGroup(cmd)
+	if err != nil {
+		return scriptError(err)
+	}
+
+	if !cmd.Bool("yes") {
+		fmt.Printf("Leave %s? [y/N]: ", conv.Title)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if !isYes(answer) {
+			fmt.Println("Cancelled")
+			return nil
+		}
+	}
+
+	if err := c.LeaveThread(conv.ThreadID); err != nil {
+		return scriptError(fmt.Errorf("failed to leave %s: %w", conv.Title, err))
+	}
+
+	fmt.Printf("%s✓ Left %s%s\n", colorGreen, conv.Title, colorReset)
+	return nil
+}
+
+func groupMembersAction(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() != 1 {
+		return cli.Exit("usage: messages group members <thread>", exitUsage)
+	}
+
+	c, conv, err := loadGroup(cmd)
+	if err != nil {
+		return scriptError(err)
+	}
+
+	resp, err := c.GetThread(conv.ThreadID, "", 1)
+	if err != nil {
+		return scriptError(fmt.Errorf("failed to fetch %s: %w", conv.Title, err))
+	}
+
+	members := threadMembers(resp.Thread)
+
+	if cmd.Bool("json") {
+		return printJSON(members)
+	}
+
+	fmt.Printf("%s👥 %s%s %s(%d members including you)%s\n", colorBold, conv.Title, colorReset, colorDim, len(members)+1, colorReset)
+	for _, m := range members {
+		line := "  @" + m.Username
+		if m.FullName != "" {
+			line += " " + colorDim + m.FullName + colorReset
+		}
+		if m.Admin {
+			line += " " + colorYellow + "★ admin" + colorReset
+		}
+		if m.Inviter {
+			line += " " + colorDim + "(created the group)" + colorReset
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// loadGroup resolves the thread in the first argument
+func loadGroup(cmd *cli.Command) (*instagram.Client, instagram.Conversation, error) {
+	c, _, err := loadClient(cmd)
+	if err != nil {
+		return nil, instagram.Conversation{}, err
+	}
+
+	conv, err := resolveThread(c, cmd.Args().First())
+	if err != nil {
+		return nil, instagram.Conversation{}, err
+	}
+
+	return c, conv, nil
+}
+
+// lookupUsers resolves @usernames to accounts
+func lookupUsers(c *instagram.Client, args []string) ([]*instagram.ThreadUser, error) {
+	users := make([]*instagram.ThreadUser, 0, len(args))
+	for _, arg := range args {
+		u, err := c.GetUserByUsername(arg)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+func findMember(users []instagram.ThreadUser, username string) *instagram.ThreadUser {
+	for i, u := range users {
+		if strings.EqualFold(u.Username, username) {
+			return &users[i]
+		}
+	}
+	return nil
+}
+
+// threadMembers lists the other members of a thread, admins first
+func threadMembers(thread instagram.Thread) []groupMember {
+	admins := make(map[string]bool, len(thread.AdminUserIDs))
+	for _, id := range thread.AdminUserIDs {
+		admins[id.String()] = true
+	}
+
+	var members, others []groupMember
+	for _, u := range thread.Users {
+		m := groupMember{
+			ID:       u.Pk.String(),
+			Username: u.Username,
+			FullName: u.FullName,
+			Admin:    admins[u.Pk.String()],
+			Inviter:  thread.Inviter != nil && thread.Inviter.Pk == u.Pk,
+		}
+		if m.Admin {
+			members = append(members, m)
+		} else {
+			others = append(others, m)
+		}
+	}
+
+	return append(members, others...)
+}
+
+func threadToGroupSummary(thread instagram.Thread) groupSummary {
+	return groupSummary{
+		ThreadID: thread.ThreadID,
+		Title:    instagram.ThreadTitle(thread),
+		Members:  threadMembers(thread),
+	}
+}
+
+// memberSummary describes a group's members for the conversation header,
+// marking admins with a star
+func memberSummary(conv instagram.Conversation) string {
+	if !conv.IsGroup || len(conv.Users) == 0 {
+		return ""
+	}
+
+	admins := make(map[string]bool, len(conv.Admins))
+	for _, a := range conv.Admins {
+		admins[a] = true
+	}
+
+	names := make([]string, 0, len(conv.Users))
+	for _, u := range conv.Users {
+		if admins[u] {
+			u += "★"
+		}
+		names = append(names, u)
+	}
+
+	return fmt.Sprintf("👥 %d members: %s", len(conv.Users)+1, strings.Join(names, ", "))
+}
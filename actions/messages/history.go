@@ -0,0 +1,246 @@
+package messages
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/storage"
+)
+
+// loadHistory decodes the local copy of a thread. It returns nil when the
+// thread was never synced.
+func loadHistory(store *storage.Storage, threadID string) (*instagram.Thread, *storage.ThreadHistory, error) {
+	history, err := store.LoadHistory(threadID)
+	if err != nil || history == nil {
+		return nil, history, err
+	}
+
+	var thread instagram.Thread
+	if len(history.Thread) > 0 {
+		if err := json.Unmarshal(history.Thread, &thread); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode stored thread: %w", err)
+		}
+	}
+
+	if len(history.Items) > 0 {
+		if err := json.Unmarshal(history.Items, &thread.Items); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode stored messages: %w", err)
+		}
+	}
+
+	thread.ThreadID = threadID
+	return &thread, history, nil
+}
+
+// loadSyncedThread finds a locally synced thread by thread ID or @username,
+// without the inbox. Anything else never reaches the history path.
+func loadSyncedThread(store *storage.Storage, arg string) (*instagram.Thread, error) {
+	if isThreadID(arg) {
+		thread, _, err := loadHistory(store, arg)
+		if err == nil && thread == nil {
+			err = fmt.Errorf("%w synced locally with ID %q", errThreadNotFound, arg)
+		}
+		return thread, err
+	}
+
+	username, ok := strings.CutPrefix(arg, "@")
+	if !ok {
+		return nil, fmt.Errorf("%w synced locally matching %q", errThreadNotFound, arg)
+	}
+
+	threadIDs, err := store.ListHistories()
+	if err != nil {
+		return nil, err
+	}
+
+	threads := make(map[string]*instagram.Thread, len(threadIDs))
+	conversations := make([]instagram.Conversation, 0, len(threadIDs))
+	for _, threadID := range threadIDs {
+		thread, _, err := loadHistory(store, threadID)
+		if err != nil {
+			return nil, err
+		}
+		if thread == nil {
+			continue
+		}
+		threads[threadID] = thread
+		conversations = append(conversations, instagram.ThreadToConversation(*thread))
+	}
+
+	conv, err := findConversationWith(conversations, username)
+	if err != nil {
+		return nil, fmt.Errorf("%w synced locally with @%s", errThreadNotFound, username)
+	}
+	return threads[conv.ThreadID], nil
+}
+
+func saveHistory(store *storage.Storage, history *storage.ThreadHistory, meta instagram.Thread, items []instagram.MessageItem) error {
+	meta.Items = nil
+
+	threadData, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to encode thread: %w", err)
+	}
+
+	itemsData, err := json.Marshal(items)
+	if err != nil {
+		return fmt.Errorf("failed to encode messages: %w", err)
+	}
+
+	history.Thread = threadData
+	history.Items = itemsData
+
+	return store.SaveHistory(history)
+}
+
+// historySavePages is how many pages a sync fetches between saves. Every save
+// rewrites the whole history, so saving each page would make a long sync
+// quadratic.
+const historySavePages = 10
+
+// syncHistory brings the local copy of a thread up to date. It first pulls
+// pages newer than what's stored, then keeps walking towards the start of the
+// thread. Progress is saved every few pages and when the walk ends or fails,
+// so an interrupted sync resumes from the last cursor instead of starting
+// over.
+func syncHistory(c *instagram.Client, store *storage.Storage, threadID string) (*instagram.Thread, error) {
+	thread, history, err := loadHistory(store, threadID)
+	if err != nil {
+		return nil, err
+	}
+
+	if history == nil {
+		history = &storage.ThreadHistory{ThreadID: threadID}
+		thread = &instagram.Thread{ThreadID: threadID}
+	}
+
+	items := thread.Items
+	known := make(map[string]bool, len(items))
+	for _, item := range items {
+		known[item.ItemID] = true
+	}
+
+	meta := *thread
+	fetched := 0
+
+	// Catch up on messages sent since the last sync
+	if len(items) > 0 {
+		var newer []instagram.MessageItem
+
+		err := c.WalkThreadHistory(threadID, "", func(page *instagram.Thread) (bool, error) {
+			meta = *page
+			reachedKnown := false
+
+			for _, item := range page.Items {
+				if known[item.ItemID] {
+					reachedKnown = true
+					continue
+				}
+				known[item.ItemID] = true
+				newer = append(newer, item)
+			}
+
+			fetched += len(page.Items)
+			reportSyncProgress(fetched)
+			return !reachedKnown, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(newer, items...)
+		if err := saveHistory(store, history, meta, items); err != nil {
+			return nil, err
+		}
+	}
+
+	// Walk the rest of the way back to the first message
+	if !history.Complete {
+		unsaved := 0
+		err := c.WalkThreadHistory(threadID, history.Cursor, func(page *instagram.Thread) (bool, error) {
+			if history.Cursor == "" {
+				meta = *page
+			}
+
+			for _, item := range page.Items {
+				if known[item.ItemID] {
+					continue
+				}
+				known[item.ItemID] = true
+				items = append(items, item)
+			}
+
+			history.Cursor = page.OldestCursor
+			history.Complete = !page.HasOlder || page.OldestCursor == ""
+
+			fetched += len(page.Items)
+			reportSyncProgress(fetched)
+
+			if unsaved++; unsaved < historySavePages {
+				return true, nil
+			}
+			unsaved = 0
+			return true, saveHistory(store, history, meta, items)
+		})
+
+		// Keep the pages fetched since the last save, even when the walk failed
+		if unsaved > 0 {
+			if saveErr := saveHistory(store, history, meta, items); err == nil {
+				err = saveErr
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if fetched > 0 {
+		fmt.Fprintln(os.Stderr)
+	}
+
+	meta.ThreadID = threadID
+	meta.Items = items
+	return &meta, nil
+}
+
+// refreshHistoryPage fetches the page of a synced thread starting at
+// thread.Items[index] again, so its media URLs are freshly signed, and saves
+// the refreshed items. It returns the IDs of the items it replaced.
+func refreshHistoryPage(c *instagram.Client, store *storage.Storage, thread *instagram.Thread, index int) ([]string, error) {
+	// Items are newest first and a cursor returns what's older than it
+	cursor := ""
+	if index > 0 {
+		cursor = thread.Items[index-1].ItemID
+	}
+
+	resp, err := c.GetThread(thread.ThreadID, cursor, 50)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make(map[string]int, len(thread.Items))
+	for i, item := range thread.Items {
+		positions[item.ItemID] = i
+	}
+
+	var refreshed []string
+	for _, item := range resp.Thread.Items {
+		if i, ok := positions[item.ItemID]; ok {
+			thread.Items[i] = item
+			refreshed = append(refreshed, item.ItemID)
+		}
+	}
+
+	history, err := store.LoadHistory(thread.ThreadID)
+	if err != nil || history == nil {
+		return refreshed, err
+	}
+	return refreshed, saveHistory(store, history, *thread, thread.Items)
+}
+
+func reportSyncProgress(fetched int) {
+	fmt.Fprintf(os.Stderr, "\r%s⏳ Synced %d messages...%s", colorDim, fetched, colorReset)
+}
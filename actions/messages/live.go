@@ -0,0 +1,206 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+)
+
+// typingTimeout is how long a typing indicator stays up without a refresh
+const typingTimeout = 6 * time.Second
+
+// typingInterval is how often our own indicator is renewed while composing
+const typingInterval = 4 * time.Second
+
+// sendReceipts is cleared by --no-receipts, so others don't see when we read
+// their messages or type
+var sendReceipts = true
+
+// typingWhileEditing keeps our typing indicator up in a thread until the
+// returned function is called. The line-based prompt can't see keystrokes,
+// so this covers the time spent in $EDITOR.
+func typingWhileEditing(c *instagram.Client, conv instagram.Conversation) (stop func()) {
+	if !sendReceipts || conv.IsPending {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(typingInterval)
+		defer ticker.Stop()
+
+		for {
+			c.IndicateTyping(conv.ThreadID, true)
+			select {
+			case <-done:
+				c.IndicateTyping(conv.ThreadID, false)
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// liveEvents feeds the interactive mode when --realtime is on. It is nil
+// otherwise, which leaves the views refreshing on Enter only.
+var liveEvents <-chan instagram.Event
+
+func streamOptions(cmd *cli.Command) instagram.StreamOptions {
+	var opts instagram.StreamOptions
+	if cmd.Bool("realtime") {
+		opts.RealtimeAddr = cmd.String("realtime-addr")
+	}
+	return opts
+}
+
+// startLiveEvents streams events for the interactive views. Events nobody is
+// waiting for are dropped so the stream never stalls behind a prompt.
+func startLiveEvents(ctx context.Context, c *instagram.Client, opts instagram.StreamOptions) <-chan instagram.Event {
+	opts.OnError = func(err error, retryIn time.Duration) {
+		if c.Debug {
+			fmt.Printf("[DEBUG] Live events: %v (retrying in %s)\n", err, retryIn)
+		}
+	}
+
+	in := c.StreamEvents(ctx, opts)
+	out := make(chan instagram.Event, 16)
+
+	go func() {
+		defer close(out)
+		for e := range in {
+			select {
+			case out <- e:
+			default:
+			}
+		}
+	}()
+
+	return out
+}
+
+// handleEvent applies a live event to the view. It reports whether the view
+// needs redrawing, and refetching when a message or reaction arrived.
+func (v *chatView) handleEvent(e instagram.Event) (redraw bool, refetch bool) {
+	if e.ThreadID != v.conv.ThreadID {
+		return false, false
+	}
+
+	switch e.Type {
+	case instagram.EventMessage, instagram.EventReaction:
+		delete(v.typing, e.SenderName)
+		return true, true
+	case instagram.EventTyping:
+		if e.FromMe {
+			return false, false
+		}
+		if v.typing == nil {
+			v.typing = make(map[string]time.Time)
+		}
+		v.typing[e.SenderName] = time.Now().Add(typingTimeout)
+		return true, false
+	case instagram.EventSeen:
+		if e.FromMe {
+			return false, false
+		}
+		v.updateSeen([]instagram.SeenReceipt{{Username: e.SenderName, ItemID: e.ItemID, Timestamp: e.Timestamp}})
+		return true, false
+	}
+
+	return false, false
+}
+
+// typingExpiry fires when the next typing indicator should be taken down
+func (v *chatView) typingExpiry() <-chan time.Time {
+	var next time.Time
+	for _, until := range v.typing {
+		if next.IsZero() || until.Before(next) {
+			next = until
+		}
+	}
+
+	if next.IsZero() {
+		return nil
+	}
+	return time.After(time.Until(next))
+}
+
+// updateSeen records read positions, keeping the newer one per person
+func (v *chatView) updateSeen(receipts []instagram.SeenReceipt) {
+	if v.seenBy == nil {
+		v.seenBy = make(map[string]instagram.SeenReceipt)
+	}
+
+	for _, r := range receipts {
+		if current, ok := v.seenBy[r.Username]; !ok || r.Timestamp.After(current.Timestamp) {
+			v.seenBy[r.Username] = r
+		}
+	}
+}
+
+// markSeen puts each participant's name on the last of messages they have
+// read. Their own messages need no marker, nor do unsent ones.
+func (v *chatView) markSeen(messages []instagram.Message) {
+	for i := range messages {
+		messages[i].SeenBy = nil
+	}
+
+	names := make([]string, 0, len(v.seenBy))
+	for name := range v.seenBy {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		r := v.seenBy[name]
+
+		last := -1
+		for i, msg := range messages {
+			if msg.ID == "" {
+				continue
+			}
+			if msg.ID == r.ItemID || !msg.Timestamp.After(r.Timestamp) {
+				last = i
+			}
+		}
+
+		if last >= 0 && messages[last].SenderName != name {
+			messages[last].SeenBy = append(messages[last].SeenBy, name)
+		}
+	}
+}
+
+// statusLines describes who is typing
+func (v *chatView) statusLines() []string {
+	var lines []string
+
+	now := time.Now()
+	var typing []string
+	for name, until := range v.typing {
+		if until.After(now) {
+			typing = append(typing, name)
+		} else {
+			delete(v.typing, name)
+		}
+	}
+	if len(typing) > 0 {
+		sort.Strings(typing)
+		lines = append(lines, fmt.Sprintf("✍️  %s typing...", joinNames(typing)))
+	}
+
+	return lines
+}
+
+func joinNames(names []string) string {
+	if len(names) == 1 {
+		return names[0] + " is"
+	}
+	return strings.Join(names, ", ") + " are"
+}
@@ -0,0 +1,121 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/progress"
+)
+
+// mediaSendTimeout bounds sending media from a chat, which has no other way
+// to stop an upload or a transcode that never finishes
+const mediaSendTimeout = 10 * time.Minute
+
+type mediaSender func(c *instagram.Client, ctx context.Context, threadID string, path string, pr instagram.ProgressReporter) (*instagram.SendMessageResponse, error)
+
+type mediaKind struct {
+	label string
+	icon  string
+	send  mediaSender
+}
+
+var mediaKinds = map[string]mediaKind{
+	"photo": {label: "Photo", icon: "📸", send: (*instagram.Client).SendPhoto},
+	"video": {label: "Video", icon: "🎥", send: (*instagram.Client).SendVideo},
+	"voice": {label: "Voice message", icon: "🎤", send: (*instagram.Client).SendVoice},
+}
+
+func newMediaCommand(kind string) *cli.Command {
+	m := mediaKinds[kind]
+
+	return &cli.Command{
+		Name:      kind,
+		Usage:     fmt.Sprintf("Send a %s to a conversation", strings.ToLower(m.label)),
+		ArgsUsage: "<thread> <file>",
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if cmd.NArg() != 2 {
+				return fmt.Errorf("usage: messages %s <thread> <file>", kind)
+			}
+
+			c, _, err := loadClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			conv, err := resolveThread(c, cmd.Args().Get(0))
+			if err != nil {
+				return err
+			}
+
+			path, err := mediaPath(cmd.Args().Get(1))
+			if err != nil {
+				return err
+			}
+
+			reporter := progress.NewCLIReporter(instagram.ProgressMessage)
+			_, err = m.send(c, ctx, conv.ThreadID, path, reporter)
+			reporter.Wait()
+
+			if err != nil {
+				return fmt.Errorf("failed to send %s: %w", strings.ToLower(m.label), err)
+			}
+
+			fmt.Printf("%s✓ %s sent to %s%s\n", colorGreen, m.label, conv.Title, colorReset)
+			return nil
+		},
+	}
+}
+
+func mediaChatCommand(kind string) func(v *chatView, args string) error {
+	m := mediaKinds[kind]
+
+	return func(v *chatView, args string) error {
+		path, err := mediaPath(args)
+		if err != nil {
+			return err
+		}
+
+		index := v.addOutgoing(instagram.Message{
+			Text: fmt.Sprintf("%s [%s] %s", m.icon, m.label, filepath.Base(path)),
+			Type: kind,
+		})
+		fmt.Println()
+
+		ctx, cancel := context.WithTimeout(context.Background(), mediaSendTimeout)
+		defer cancel()
+
+		reporter := progress.NewCLIReporter(instagram.ProgressMessage)
+		resp, err := m.send(v.c, ctx, v.conv.ThreadID, path, reporter)
+		reporter.Wait()
+
+		v.finishOutgoing(index, resp, err)
+		return nil
+	}
+}
+
+// mediaPath cleans up a path typed by the user and checks that it exists
+func mediaPath(arg string) (string, error) {
+	path := strings.Trim(strings.TrimSpace(arg), `"'`)
+	if path == "" {
+		return "", fmt.Errorf("a file path is required")
+	}
+
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("cannot read %s: %w", path, err)
+	}
+
+	return path, nil
+}
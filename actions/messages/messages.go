@@ -3,6 +3,7 @@ package messages
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"sort"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
 	"github.com/PiotrWarzachowski/go-instagram-cli/internal/storage"
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/tui"
 )
 
 const (
@@ -35,46 +37,136 @@ var MessagesCommand = &cli.Command{
 	Name:    "messages",
 	Aliases: []string{"dm", "inbox", "dms"},
 	Usage:   "View and manage your Instagram direct messages",
-	Flags: []cli.Flag{
+	Flags: append([]cli.Flag{
 		&cli.BoolFlag{
 			Name:    "debug",
 			Aliases: []string{"d"},
 			Usage:   "Enable debug mode",
 		},
-	},
+		&cli.BoolFlag{
+			Name:  "line",
+			Usage: "Use the line-based interface instead of the full-screen one",
+		},
+		&cli.BoolFlag{
+			Name:  "realtime",
+			Usage: "Receive messages, typing and seen receipts over MQTT instead of polling",
+		},
+		&cli.StringFlag{
+			Name:    "realtime-addr",
+			Value:   instagram.DefaultRealtimeAddr,
+			Usage:   "MQTT endpoint for --realtime (tcp:// speaks plain MQTT for a local stand-in)",
+			Sources: cli.EnvVars("IG_MQTT_ADDR"),
+		},
+		&cli.BoolFlag{
+			Name:  "no-receipts",
+			Usage: "Don't send seen receipts or typing indicators (typing is sent while composing in the full-screen UI or in $EDITOR via /edit)",
+		},
+		&cli.BoolFlag{
+			Name:  "all-accounts",
+			Usage: "Merge the inboxes of every logged-in account into one list (line-based interface)",
+		},
+		&cli.StringFlag{
+			Name:    "images",
+			Value:   "auto",
+			Usage:   "Inline image previews in the line-based interface: auto, kitty, sixel or off",
+			Sources: cli.EnvVars("IG_IMAGES"),
+		},
+	}, inboxFlags()...),
+	Commands: append([]*cli.Command{
+		listCommand,
+		showCommand,
+		sendCommand,
+		forwardCommand,
+		unreadCommand,
+		exportCommand,
+		downloadCommand,
+		searchCommand,
+		statsCommand,
+		watchCommand,
+		autoReplyCommand,
+		flushCommand,
+		outboxCommand,
+		templatesCommand,
+		requestsCommand,
+		groupCommand,
+		newConversationCommand,
+		newMediaCommand("photo"),
+		newMediaCommand("video"),
+		newMediaCommand("voice"),
+	}, threadActionCommands()...),
 	Action: messagesAction,
 }
 
 type conversationCache struct {
-	conversations []instagram.Conversation
-	lastRefresh   time.Time
+	conversations   []instagram.Conversation
+	pendingRequests int
+	lastRefresh     time.Time
+
+	// Folder the conversations came from and where its next page starts
+	folder   string
+	cursor   string
+	hasOlder bool
 }
 
 var cache = &conversationCache{}
 
-func messagesAction(ctx context.Context, cmd *cli.Command) error {
-	debug := cmd.Bool("debug")
+var errNotLoggedIn = errors.New("not logged in, please run 'go-instagram-cli login' first")
 
+// loadClient restores the logged-in client from session storage
+func loadClient(cmd *cli.Command) (*instagram.Client, *storage.Storage, error) {
 	storage, err := storage.NewSessionStorage()
 	if err != nil {
-		return fmt.Errorf("failed to initialize session storage: %w", err)
+		return nil, nil, fmt.Errorf("failed to initialize session storage: %w", err)
 	}
 
 	stored, err := storage.LoadSession()
 	if err != nil {
-		return fmt.Errorf("failed to load session: %w", err)
+		return nil, nil, fmt.Errorf("failed to load session: %w", err)
 	}
 
 	if stored == nil {
-		fmt.Printf("%s✗ Not logged in. Please run 'go-instagram-cli login' first.%s\n", colorRed, colorReset)
-		return nil
+		return nil, nil, errNotLoggedIn
 	}
 
 	c, err := instagram.NewClientFromSession(stored)
 	if err != nil {
-		return fmt.Errorf("failed to restore session: %w", err)
+		return nil, nil, fmt.Errorf("failed to restore session: %w", err)
+	}
+	c.Debug = cmd.Bool("debug")
+
+	return c, storage, nil
+}
+
+func messagesAction(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Bool("all-accounts") {
+		return allAccountsAction(cmd)
+	}
+
+	c, storage, err := loadClient(cmd)
+	if errors.Is(err, errNotLoggedIn) {
+		fmt.Printf("%s✗ Not logged in. Please run 'go-instagram-cli login' first.%s\n", colorRed, colorReset)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := setInboxView(cmd); err != nil {
+		return cli.Exit(err.Error(), exitUsage)
+	}
+	sendReceipts = !cmd.Bool("no-receipts")
+
+	if !cmd.Bool("line") && tui.Supported() {
+		return runTUI(ctx, c, storage, streamOptions(cmd), nil)
+	}
+
+	if err := setupPreviews(cmd, c); err != nil {
+		return cli.Exit(err.Error(), exitUsage)
+	}
+
+	if cmd.Bool("realtime") {
+		liveEvents = startLiveEvents(ctx, c, streamOptions(cmd))
 	}
-	c.Debug = debug
 
 	return runInteractiveMode(c, storage)
 }
@@ -87,16 +179,33 @@ func runInteractiveMode(c *instagram.Client, storage *storage.Storage) error {
 	conversations, fromCache := getConversationsWithCache(c, storage, false)
 
 	for {
-		displayConversations(conversations)
+		visible := inboxView.filter.apply(conversations)
+
+		fmt.Printf("\n%s📂 %s%s", colorBold, inboxView.describe(), colorReset)
+		fmt.Printf(" %s(%d of %d loaded", colorDim, len(visible), len(conversations))
+		if cache.hasOlder {
+			fmt.Print(", more available")
+		}
+		fmt.Printf(")%s\n", colorReset)
+
+		displayConversations(visible)
 
 		if fromCache && !cache.lastRefresh.IsZero() {
 			ago := time.Since(cache.lastRefresh).Round(time.Second)
 			fmt.Printf("%s  📋 Cached %s ago (auto-refreshes every 60s)%s\n", colorDim, ago, colorReset)
 		}
 
+		if cache.pendingRequests > 0 {
+			fmt.Printf("%s  📨 %d message request(s) waiting%s\n", colorYellow, cache.pendingRequests, colorReset)
+		}
+
 		fmt.Printf("\n%s─────────────────────────────────────────────────────────%s\n", colorDim, colorReset)
-		fmt.Printf("%sCommands:%s [number] View conversation • %sr%s Refresh • %sq%s Quit\n",
-			colorCyan, colorReset, colorGreen, colorReset, colorRed, colorReset)
+		fmt.Printf("%sCommands:%s [number] View conversation • %snew @user%s New chat • %sreq%s Requests • %sr%s Refresh • %sq%s Quit\n",
+			colorCyan, colorReset, colorBlue, colorReset, colorYellow, colorReset, colorGreen, colorReset, colorRed, colorReset)
+		fmt.Printf("%s          filter unread|muted|pinned|group|direct|@user|all • folder all|primary|general|requests • sort recent|unread|title • more%s\n",
+			colorDim, colorReset)
+		fmt.Printf("%s          M <n> mute/unmute • p <n> pin/unpin • a <n> archive • D <n> delete%s\n",
+			colorDim, colorReset)
 		fmt.Printf("%s➜ %s", colorGreen, colorReset)
 
 		input, _ := reader.ReadString('\n')
@@ -112,21 +221,75 @@ func runInteractiveMode(c *instagram.Client, storage *storage.Storage) error {
 			conversations, fromCache = getConversationsWithCache(c, storage, true)
 			clearScreen()
 			continue
+		case "req", "requests":
+			if err := runRequestsMode(c, reader); err != nil {
+				fmt.Printf("%s✗ Error: %v%s\n", colorRed, err, colorReset)
+				time.Sleep(2 * time.Second)
+			}
+			clearScreen()
+			conversations, fromCache = getConversationsWithCache(c, storage, true)
+			continue
 		case "":
 			if !cache.lastRefresh.IsZero() && time.Since(cache.lastRefresh) > 60*time.Second {
 				conversations, fromCache = getConversationsWithCache(c, storage, true)
 			}
 			continue
+		case "more", "m":
+			clearScreen()
+			if !cache.hasOlder {
+				fmt.Printf("%s✓ All conversations in this folder are loaded%s\n", colorDim, colorReset)
+				continue
+			}
+			conversations = loadMoreConversations(c)
+			continue
 		default:
+			if key, arg, ok := cutThreadAction(input); ok {
+				done, err := runInboxAction(c, storage, reader, visible, key, arg)
+				clearScreen()
+				if err != nil {
+					fmt.Printf("%s✗ %v%s\n", colorRed, err, colorReset)
+				} else if done != "" {
+					fmt.Printf("%s✓ %s%s\n", colorGreen, done, colorReset)
+				}
+				conversations = cache.conversations
+				continue
+			}
+
+			if handled, refetch, err := inboxView.runCommand(input); handled {
+				clearScreen()
+				if err != nil {
+					fmt.Printf("%s✗ %v%s\n", colorRed, err, colorReset)
+				}
+				if refetch {
+					conversations, fromCache = getConversationsWithCache(c, storage, true)
+				}
+				continue
+			}
+
+			if username, ok := cutNewCommand(input); ok {
+				conv, err := startConversation(c, username)
+				if err == nil {
+					err = openConversation(c, conv, reader)
+				}
+				if err != nil {
+					fmt.Printf("%s✗ Error: %v%s\n", colorRed, err, colorReset)
+					time.Sleep(2 * time.Second)
+				}
+
+				clearScreen()
+				conversations, fromCache = getConversationsWithCache(c, storage, true)
+				continue
+			}
+
 			num, err := strconv.Atoi(input)
-			if err != nil || num < 1 || num > len(conversations) {
-				fmt.Printf("%s✗ Invalid selection. Enter a number 1-%d%s\n", colorRed, len(conversations), colorReset)
+			if err != nil || num < 1 || num > len(visible) {
+				fmt.Printf("%s✗ Invalid selection. Enter a number 1-%d%s\n", colorRed, len(visible), colorReset)
 				time.Sleep(1 * time.Second)
 				clearScreen()
 				continue
 			}
 
-			conv := conversations[num-1]
+			conv := visible[num-1]
 			if err := openConversation(c, conv, reader); err != nil {
 				fmt.Printf("%s✗ Error: %v%s\n", colorRed, err, colorReset)
 				time.Sleep(2 * time.Second)
@@ -139,15 +302,15 @@ func runInteractiveMode(c *instagram.Client, storage *storage.Storage) error {
 }
 
 func getConversationsWithCache(c *instagram.Client, storage *storage.Storage, forceRefresh bool) ([]instagram.Conversation, bool) {
-	if !forceRefresh && cache.conversations != nil && !cache.lastRefresh.IsZero() {
+	if !forceRefresh && cache.conversations != nil && !cache.lastRefresh.IsZero() && cache.folder == inboxView.folder {
 		if time.Since(cache.lastRefresh) < 60*time.Second {
 			return cache.conversations, true
 		}
 	}
 
-	conversations, err := c.GetConversations()
+	list, err := fetchInbox(c, inboxView.folder, inboxView.pages)
 	if err != nil {
-		if cache.conversations != nil {
+		if cache.conversations != nil && cache.folder == inboxView.folder {
 			fmt.Printf("%s⚠ Using cached data (fetch failed: %v)%s\n", colorYellow, err, colorReset)
 			return cache.conversations, true
 		}
@@ -155,10 +318,41 @@ func getConversationsWithCache(c *instagram.Client, storage *storage.Storage, fo
 		return nil, false
 	}
 
-	cache.conversations = conversations
-	cache.lastRefresh = time.Now()
+	cache.store(inboxView.folder, list)
+	return list.Conversations, false
+}
 
-	return conversations, false
+func (cc *conversationCache) store(folder string, list *instagram.ConversationList) {
+	cc.conversations = list.Conversations
+	cc.pendingRequests = list.PendingRequests
+	cc.lastRefresh = time.Now()
+	cc.folder = folder
+	cc.cursor = list.OldestCursor
+	cc.hasOlder = list.HasOlder
+}
+
+// loadMoreConversations appends the next page of the current folder to the
+// cache
+func loadMoreConversations(c *instagram.Client) []instagram.Conversation {
+	fmt.Printf("%s⏳ Loading more conversations...%s\n", colorCyan, colorReset)
+
+	list := &instagram.ConversationList{
+		Conversations:   cache.conversations,
+		PendingRequests: cache.pendingRequests,
+		OldestCursor:    cache.cursor,
+		HasOlder:        cache.hasOlder,
+	}
+
+	clearScreen()
+	if err := fetchMoreConversations(c, cache.folder, list); err != nil {
+		fmt.Printf("%s✗ Failed to load more: %v%s\n", colorRed, err, colorReset)
+		return cache.conversations
+	}
+
+	cache.conversations = list.Conversations
+	cache.cursor = list.OldestCursor
+	cache.hasOlder = list.HasOlder
+	return cache.conversations
 }
 
 func clearScreen() {
@@ -174,6 +368,8 @@ func displayConversations(conversations []instagram.Conversation) {
 	fmt.Printf("\n%s%-4s %-25s %-35s %s%s\n", colorBold, "#", "FROM", "LAST MESSAGE", "TIME", colorReset)
 	fmt.Printf("%s────────────────────────────────────────────────────────────────────────────%s\n", colorDim, colorReset)
 
+	drafts := loadDrafts()
+
 	for i, conv := range conversations {
 		num := fmt.Sprintf("%d", i+1)
 
@@ -197,6 +393,9 @@ func displayConversations(conversations []instagram.Conversation) {
 		}
 
 		indicators := ""
+		if _, ok := drafts[conv.ThreadID]; ok {
+			indicators += "✎"
+		}
 		if conv.IsPinned {
 			indicators += "📌"
 		}
@@ -220,27 +419,69 @@ func displayConversations(conversations []instagram.Conversation) {
 func openConversation(c *instagram.Client, conv instagram.Conversation, reader *bufio.Reader) error {
 	clearScreen()
 
+	v := &chatView{c: c, conv: conv, reader: reader, draft: loadDrafts()[conv.ThreadID].Text}
+	var lastSeenID string
+
+	// Input is read in the background so live events can redraw the view
+	// while the prompt is waiting
+	lines := make(chan string, 1)
+	reading := false
+	refetch := true
+
 	for {
-		// Fetch messages
-		messages, _, err := c.GetMessages(conv.ThreadID, 30)
-		if err != nil {
-			return fmt.Errorf("failed to fetch messages: %w", err)
+		if refetch {
+			if err := v.refresh(); err != nil {
+				return fmt.Errorf("failed to fetch messages: %w", err)
+			}
+
+			// Pending requests stay unseen until they're approved
+			if !conv.IsPending {
+				lastSeenID = markLatestSeen(c, conv.ThreadID, v.messages, lastSeenID)
+			}
 		}
+		refetch = true
 
-		fmt.Printf("%s%s", colorBold, colorMagenta)
-		fmt.Println("╔════════════════════════════════════════════════════════════╗")
-		fmt.Printf("║  💬 Conversation with: %-36s ║\n", truncateString(conv.Title, 35))
-		fmt.Println("╚════════════════════════════════════════════════════════════╝")
-		fmt.Printf("%s\n", colorReset)
+		v.render()
 
-		displayMessages(messages, c.UserID())
+		if !reading {
+			reading = true
+			go func() {
+				line, _ := reader.ReadString('\n')
+				lines <- line
+			}()
+		}
 
-		fmt.Printf("\n%s─────────────────────────────────────────────────────────%s\n", colorDim, colorReset)
-		fmt.Printf("%sCommands:%s Type message to reply • %sr%s Refresh • %sb%s Back\n",
-			colorCyan, colorReset, colorGreen, colorReset, colorYellow, colorReset)
-		fmt.Printf("%s%s ➜ %s", colorBold, conv.Title, colorReset)
+		var input string
+	wait:
+		for {
+			select {
+			case input = <-lines:
+				reading = false
+				break wait
+			case e, ok := <-liveEvents:
+				if !ok {
+					liveEvents = nil
+					continue
+				}
+				if redraw, fetch := v.handleEvent(e); redraw {
+					refetch = fetch
+					break wait
+				}
+			case <-v.typingExpiry():
+				refetch = false
+				break wait
+			case <-previews.readyChan():
+				refetch = false
+				break wait
+			}
+		}
+
+		// Woken by a live event rather than input
+		if reading {
+			clearScreen()
+			continue
+		}
 
-		input, _ := reader.ReadString('\n')
 		input = strings.TrimSpace(input)
 
 		switch strings.ToLower(input) {
@@ -252,35 +493,115 @@ func openConversation(c *instagram.Client, conv instagram.Conversation, reader *
 		case "":
 			continue
 		default:
-			// Send message
-			fmt.Printf("%sSending...%s", colorDim, colorReset)
-			_, err := c.SendMessage(conv.ThreadID, input)
-			if err != nil {
-				fmt.Printf("\r%s✗ Failed to send: %v%s\n", colorRed, err, colorReset)
-				time.Sleep(2 * time.Second)
+			if strings.HasPrefix(input, "/") {
+				if err := v.runCommand(input); err != nil {
+					fmt.Printf("%s✗ %v%s\n", colorRed, err, colorReset)
+					time.Sleep(2 * time.Second)
+				}
 			} else {
-				fmt.Printf("\r%s✓ Message sent!%s    \n", colorGreen, colorReset)
-				time.Sleep(500 * time.Millisecond)
+				v.sendText(input, instagram.SendOptions{}, nil)
 			}
 			clearScreen()
 		}
 	}
 }
 
+func renderConversation(c *instagram.Client, conv instagram.Conversation, messages []instagram.Message, status []string) {
+	fmt.Printf("%s%s", colorBold, colorMagenta)
+	fmt.Println("╔════════════════════════════════════════════════════════════╗")
+	fmt.Printf("║  💬 Conversation with: %-36s ║\n", truncateString(conv.Title, 35))
+	fmt.Println("╚════════════════════════════════════════════════════════════╝")
+	fmt.Printf("%s", colorReset)
+	if members := memberSummary(conv); members != "" {
+		fmt.Printf("%s  %s%s\n", colorDim, members, colorReset)
+	}
+	if replyingAs {
+		fmt.Printf("%s  Replying as @%s%s\n", colorCyan, accountName(c), colorReset)
+	}
+	fmt.Println()
+
+	displayMessages(messages, c.UserID())
+
+	if len(status) > 0 {
+		fmt.Println()
+		for _, line := range status {
+			fmt.Printf("%s  %s%s\n", colorDim, line, colorReset)
+		}
+	}
+
+	fmt.Printf("\n%s─────────────────────────────────────────────────────────%s\n", colorDim, colorReset)
+	fmt.Printf("%sCommands:%s Type message to reply • %s/help%s Chat commands • %sr%s Refresh • %sb%s Back\n",
+		colorCyan, colorReset, colorBlue, colorReset, colorGreen, colorReset, colorYellow, colorReset)
+	fmt.Printf("%s%s ➜ %s", colorBold, conv.Title, colorReset)
+}
+
+// markLatestSeen marks the newest incoming message as seen, skipping the call
+// when nothing new arrived since the last time
+func markLatestSeen(c *instagram.Client, threadID string, messages []instagram.Message, lastSeenID string) string {
+	var latest *instagram.Message
+	for i := range messages {
+		if messages[i].IsFromMe {
+			continue
+		}
+		if latest == nil || messages[i].Timestamp.After(latest.Timestamp) {
+			latest = &messages[i]
+		}
+	}
+
+	if !sendReceipts || latest == nil || latest.ID == "" || latest.ID == lastSeenID {
+		return lastSeenID
+	}
+
+	if err := c.MarkThreadSeen(threadID, latest.ID); err != nil && c.Debug {
+		fmt.Printf("[DEBUG] Failed to mark thread seen: %v\n", err)
+	}
+
+	return latest.ID
+}
+
+// pruneDelivered drops locally sent messages once the server returns them.
+// Failed messages are kept so the user can see what didn't go through.
+func pruneDelivered(outgoing []instagram.Message, fetched []instagram.Message) []instagram.Message {
+	if len(outgoing) == 0 {
+		return outgoing
+	}
+
+	seen := make(map[string]bool, len(fetched)*2)
+	for _, m := range fetched {
+		if m.ClientContext != "" {
+			seen[m.ClientContext] = true
+		}
+		if m.ID != "" {
+			seen[m.ID] = true
+		}
+	}
+
+	kept := outgoing[:0]
+	for _, m := range outgoing {
+		if seen[m.ClientContext] || (m.ID != "" && seen[m.ID]) {
+			continue
+		}
+		kept = append(kept, m)
+	}
+	return kept
+}
+
+// displayMessages prints messages oldest first. Each one is numbered so chat
+// commands like /reply and /react can address it.
 func displayMessages(messages []instagram.Message, myUserID int64) {
 	if len(messages) == 0 {
 		fmt.Printf("\n%s📭 No messages in this conversation.%s\n", colorDim, colorReset)
 		return
 	}
 
-	sort.Slice(messages, func(i, j int) bool {
+	sort.SliceStable(messages, func(i, j int) bool {
 		return messages[i].Timestamp.Before(messages[j].Timestamp)
 	})
 
 	// Group messages by date
 	var lastDate string
 
-	for _, msg := range messages {
+	for i, msg := range messages {
 		// Date separator
 		date := msg.Timestamp.Format("Mon, Jan 2 2006")
 		if date != lastDate {
@@ -292,24 +613,29 @@ func displayMessages(messages []instagram.Message, myUserID int64) {
 
 		if msg.IsFromMe {
 			// Right-aligned (my messages)
-			displayMyMessage(msg, timeStr)
+			displayMyMessage(msg, i+1, timeStr)
 		} else {
 			// Left-aligned (their messages)
-			displayTheirMessage(msg, timeStr)
+			displayTheirMessage(msg, i+1, timeStr)
 		}
 	}
 }
 
-func displayMyMessage(msg instagram.Message, timeStr string) {
+func displayMyMessage(msg instagram.Message, index int, timeStr string) {
 	// Format message text with wrapping
 	lines := wrapText(msg.Text, 45)
 
 	padding := strings.Repeat(" ", 15)
+	label := fmt.Sprintf("%s%-15s%s", colorDim, fmt.Sprintf("#%d", index), colorReset)
+
+	if msg.ReplyTo != nil {
+		displayQuote(padding, msg.ReplyTo)
+	}
 
 	for i, line := range lines {
 		if i == 0 {
 			fmt.Printf("%s%s%s%s%s%s %s%s%s\n",
-				padding,
+				label,
 				colorBgBlue, colorBold, colorWhite, " "+line+" ", colorReset,
 				colorWhite, timeStr, colorReset,
 			)
@@ -322,25 +648,51 @@ func displayMyMessage(msg instagram.Message, timeStr string) {
 		}
 	}
 
-	if msg.HasReaction {
-		fmt.Printf("%s%s💗%s\n", padding, colorDim, colorReset)
+	previews.print(padding, msg.PreviewURL)
+	displayReactions(padding, msg.Reactions)
+	displaySeen(padding, msg.SeenBy)
+
+	switch msg.State {
+	case instagram.SendPending:
+		fmt.Printf("%s%s🕓 Sending...%s\n", padding, colorDim, colorReset)
+	case instagram.SendSent:
+		fmt.Printf("%s%s✓ Sent%s\n", padding, colorDim, colorReset)
+	case instagram.SendFailed:
+		fmt.Printf("%s%s✗ Failed to send%s\n", padding, colorRed, colorReset)
+	case instagram.SendQueued:
+		fmt.Printf("%s%s📤 %s%s\n", padding, colorYellow, queuedStatus(msg), colorReset)
 	}
 }
 
-func displayTheirMessage(msg instagram.Message, timeStr string) {
+// queuedStatus says when an outbox message goes out
+func queuedStatus(msg instagram.Message) string {
+	if msg.Timestamp.After(time.Now()) {
+		return "Scheduled for " + msg.Timestamp.Format("Mon 15:04")
+	}
+	return "Queued, sent by 'messages flush'"
+}
+
+func displayTheirMessage(msg instagram.Message, index int, timeStr string) {
 	// Format message text with wrapping
 	lines := wrapText(msg.Text, 45)
 
+	label := fmt.Sprintf("%s%-5s%s", colorDim, fmt.Sprintf("#%d", index), colorReset)
+	senderPadding := strings.Repeat(" ", len(msg.SenderName)+1+5)
+
+	if msg.ReplyTo != nil {
+		displayQuote(strings.Repeat(" ", 5), msg.ReplyTo)
+	}
+
 	for i, line := range lines {
 		if i == 0 {
 			// First line with sender name and timestamp
-			fmt.Printf("%s%s%s %s%s%s %s%s%s\n",
+			fmt.Printf("%s%s%s%s %s%s%s %s%s%s\n",
+				label,
 				colorCyan, msg.SenderName, colorReset,
 				colorBgGray, " "+line+" ", colorReset,
 				colorDim, timeStr, colorReset,
 			)
 		} else {
-			senderPadding := strings.Repeat(" ", len(msg.SenderName)+1)
 			fmt.Printf("%s%s%s%s%s\n",
 				senderPadding,
 				colorBgGray, " "+line+" ", colorReset, "",
@@ -348,12 +700,48 @@ func displayTheirMessage(msg instagram.Message, timeStr string) {
 		}
 	}
 
-	if msg.HasReaction {
-		fmt.Printf("  %s💗%s\n", colorDim, colorReset)
+	previews.print(senderPadding, msg.PreviewURL)
+	displayReactions(senderPadding, msg.Reactions)
+	displaySeen(senderPadding, msg.SeenBy)
+}
+
+// displayQuote renders the message a reply points at, above the reply bubble
+func displayQuote(padding string, quote *instagram.QuotedMessage) {
+	fmt.Printf("%s%s↪ %s: %s%s\n", padding, colorDim, quote.SenderName, truncateString(singleLine(quote.Text), 40), colorReset)
+}
+
+// displaySeen marks the last message the listed people have read
+func displaySeen(padding string, names []string) {
+	if len(names) == 0 {
+		return
 	}
+	fmt.Printf("%s%s👁 Seen by %s%s\n", padding, colorDim, strings.Join(names, ", "), colorReset)
 }
 
+func displayReactions(padding string, reactions []instagram.Reaction) {
+	if len(reactions) == 0 {
+		return
+	}
+
+	parts := make([]string, 0, len(reactions))
+	for _, r := range reactions {
+		parts = append(parts, fmt.Sprintf("%s %s", r.Emoji, r.SenderName))
+	}
+
+	fmt.Printf("%s%s%s%s\n", padding, colorDim, strings.Join(parts, " · "), colorReset)
+}
+
+// wrapText breaks text into lines of at most maxWidth, keeping explicit
+// newlines
 func wrapText(text string, maxWidth int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		lines = append(lines, wrapLine(paragraph, maxWidth)...)
+	}
+	return lines
+}
+
+func wrapLine(text string, maxWidth int) []string {
 	if len(text) <= maxWidth {
 		return []string{text}
 	}
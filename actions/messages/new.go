@@ -0,0 +1,93 @@
+package messages
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/tui"
+)
+
+var newConversationCommand = &cli.Command{
+	Name:      "new",
+	Usage:     "Start a conversation by username (sends right away when text is given)",
+	ArgsUsage: "@username [text]",
+	Flags:     []cli.Flag{jsonFlag()},
+	Action:    newConversationAction,
+}
+
+func newConversationAction(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() < 1 {
+		return cli.Exit("usage: messages new @username [text]", exitUsage)
+	}
+
+	c, store, err := loadClient(cmd)
+	if err != nil {
+		return scriptError(err)
+	}
+
+	conv, err := startConversation(c, cmd.Args().First())
+	if err != nil {
+		return scriptError(err)
+	}
+
+	if text := strings.Join(cmd.Args().Tail(), " "); strings.TrimSpace(text) != "" {
+		resp, err := c.SendMessage(conv.ThreadID, text)
+		if err != nil {
+			return scriptError(fmt.Errorf("failed to send message: %w", err))
+		}
+
+		if cmd.Bool("json") {
+			return printJSON(resp.Payload)
+		}
+
+		fmt.Printf("sent %s to %s (%s)\n", resp.Payload.ItemID, conv.Title, conv.ThreadID)
+		return nil
+	}
+
+	sendReceipts = !cmd.Bool("no-receipts")
+
+	if !cmd.Bool("line") && tui.Supported() {
+		return runTUI(ctx, c, store, streamOptions(cmd), &conv)
+	}
+
+	if err := setupPreviews(cmd, c); err != nil {
+		return cli.Exit(err.Error(), exitUsage)
+	}
+
+	if cmd.Bool("realtime") {
+		liveEvents = startLiveEvents(ctx, c, streamOptions(cmd))
+	}
+
+	return openConversation(c, conv, bufio.NewReader(os.Stdin))
+}
+
+// cutNewCommand recognizes "new @user" and "/new @user" typed at the inbox
+// prompt
+func cutNewCommand(input string) (string, bool) {
+	name, username, ok := strings.Cut(strings.TrimPrefix(input, "/"), " ")
+	if !ok || !strings.EqualFold(name, "new") {
+		return "", false
+	}
+
+	username = strings.TrimSpace(username)
+	return username, username != ""
+}
+
+// startNew opens the conversation with a user on top of the current one
+func (v *chatView) startNew(args string) error {
+	if args == "" {
+		return fmt.Errorf("usage: /new @username")
+	}
+
+	conv, err := startConversation(v.c, args)
+	if err != nil {
+		return err
+	}
+
+	return openConversation(v.c, conv, v.reader)
+}
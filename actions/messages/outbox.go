@@ -0,0 +1,478 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/storage"
+)
+
+// maxRetryDelay caps the wait between attempts of a failing message
+const maxRetryDelay = time.Hour
+
+var flushCommand = &cli.Command{
+	Name:  "flush",
+	Usage: "Send queued and scheduled messages that are due (messages Instagram rejects are marked failed)",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "watch",
+			Aliases: []string{"w"},
+			Usage:   "Keep running and send messages as they fall due",
+		},
+		&cli.DurationFlag{
+			Name:    "interval",
+			Aliases: []string{"i"},
+			Value:   30 * time.Second,
+			Usage:   "Time between outbox checks with --watch",
+		},
+	},
+	Action: flushAction,
+}
+
+var outboxCommand = &cli.Command{
+	Name:  "outbox",
+	Usage: "List queued and scheduled messages",
+	Flags: []cli.Flag{jsonFlag()},
+	Commands: []*cli.Command{
+		{
+			Name:      "cancel",
+			Aliases:   []string{"rm"},
+			Usage:     "Drop a queued message",
+			ArgsUsage: "<n>",
+			Action:    outboxCancelAction,
+		},
+	},
+	Action: outboxListAction,
+}
+
+// parseSendAt reads --at and /at times: a clock time (the next one to come),
+// a date and time, or a delay like 30m
+func parseSendAt(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "now" {
+		return now, nil
+	}
+
+	if d, err := time.ParseDuration(strings.TrimPrefix(s, "+")); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("%q is in the past", s)
+		}
+		return now.Add(d), nil
+	}
+
+	if clock, err := time.ParseInLocation("15:04", s, time.Local); err == nil {
+		at := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, time.Local)
+		if at.Before(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+		return at, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02T15:04"} {
+		if at, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			if at.Before(now) {
+				return time.Time{}, fmt.Errorf("%s is in the past", at.Format("2006-01-02 15:04"))
+			}
+			return at, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q (use 15:04, 2006-01-02 15:04 or a delay like 30m)", s)
+}
+
+// queueMessage adds a text message to the outbox
+func queueMessage(c *instagram.Client, store *storage.Storage, conv instagram.Conversation, text string, sendAt time.Time, opts instagram.SendOptions) (storage.OutboxMessage, error) {
+	if opts.ClientContext == "" {
+		opts.ClientContext = instagram.NewClientContext()
+	}
+
+	msg := storage.OutboxMessage{
+		ClientContext:        opts.ClientContext,
+		ThreadID:             conv.ThreadID,
+		ThreadTitle:          conv.Title,
+		Account:              accountName(c),
+		Text:                 text,
+		ReplyToItemID:        opts.ReplyToItemID,
+		ReplyToClientContext: opts.ReplyToClientContext,
+		CreatedAt:            time.Now(),
+		SendAt:               sendAt,
+	}
+
+	err := store.UpdateOutbox(func(outbox *storage.Outbox) {
+		outbox.Messages = append(outbox.Messages, msg)
+	})
+	if err != nil {
+		return msg, fmt.Errorf("failed to queue message: %w", err)
+	}
+	return msg, nil
+}
+
+// queuedMessages returns the outbox entries of a thread as pending messages
+// for the chat views
+func queuedMessages(threadID string) []instagram.Message {
+	store, err := storage.NewSessionStorage()
+	if err != nil {
+		return nil
+	}
+
+	outbox, err := store.LoadOutbox()
+	if err != nil {
+		return nil
+	}
+
+	var queued []instagram.Message
+	for _, msg := range outbox.Messages {
+		if msg.ThreadID != threadID {
+			continue
+		}
+		state := instagram.SendQueued
+		if msg.Failed {
+			state = instagram.SendFailed
+		}
+		queued = append(queued, instagram.Message{
+			Text:          msg.Text,
+			Type:          "text",
+			SenderName:    "You",
+			Timestamp:     msg.SendAt,
+			IsFromMe:      true,
+			ClientContext: msg.ClientContext,
+			State:         state,
+		})
+	}
+	return queued
+}
+
+// flushResult is the outcome of one delivery attempt
+type flushResult struct {
+	msg     storage.OutboxMessage
+	err     error
+	retryIn time.Duration
+}
+
+// outboxSender is an account flushing the outbox. current marks the
+// account of the current session.
+type outboxSender struct {
+	c       *instagram.Client
+	current bool
+}
+
+// owns reports whether msg goes out from this account. Messages queued
+// before they recorded their account belong to the current session.
+func (s outboxSender) owns(msg storage.OutboxMessage) bool {
+	if msg.Account == "" {
+		return s.current
+	}
+	return msg.Account == accountName(s.c)
+}
+
+// flushOutbox sends every due message of the sender's account once. It
+// stops early when Instagram can't be reached, since the rest would fail the
+// same way.
+func flushOutbox(sender outboxSender, store *storage.Storage, report func(flushResult)) error {
+	c := sender.c
+
+	outbox, err := store.LoadOutbox()
+	if err != nil {
+		return fmt.Errorf("failed to load outbox: %w", err)
+	}
+
+	now := time.Now()
+	for _, msg := range outbox.Messages {
+		if msg.Failed || msg.SendAt.After(now) || msg.NextAttempt.After(now) {
+			continue
+		}
+
+		// Messages queued from another account wait for its flush
+		if !sender.owns(msg) {
+			continue
+		}
+
+		_, sendErr := c.SendTextMessage(msg.ThreadID, msg.Text, instagram.SendOptions{
+			ClientContext:        msg.ClientContext,
+			ReplyToItemID:        msg.ReplyToItemID,
+			ReplyToClientContext: msg.ReplyToClientContext,
+		})
+
+		result := flushResult{msg: msg, err: sendErr}
+		err := store.UpdateOutbox(func(outbox *storage.Outbox) {
+			for i := range outbox.Messages {
+				queued := &outbox.Messages[i]
+				if queued.ClientContext != msg.ClientContext {
+					continue
+				}
+
+				if sendErr == nil {
+					outbox.Messages = append(outbox.Messages[:i], outbox.Messages[i+1:]...)
+					return
+				}
+
+				queued.Attempts++
+				queued.LastError = sendErr.Error()
+				if instagram.IsPermanentError(sendErr) {
+					queued.Failed = true
+					return
+				}
+				result.retryIn = retryDelay(queued.Attempts)
+				queued.NextAttempt = time.Now().Add(result.retryIn)
+				return
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update outbox: %w", err)
+		}
+
+		report(result)
+
+		if instagram.IsNetworkError(sendErr) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// retryDelay doubles the wait after every failed attempt
+func retryDelay(attempts int) time.Duration {
+	delay := 30 * time.Second
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+func printFlushResult(r flushResult) {
+	ts := time.Now().Format("15:04:05")
+	if r.err == nil {
+		fmt.Printf("%s %s✓ Sent to %s%s %s%s%s\n", ts, colorGreen, r.msg.ThreadTitle, colorReset, colorDim, truncateString(singleLine(r.msg.Text), 50), colorReset)
+		return
+	}
+	if r.retryIn == 0 {
+		fmt.Printf("%s %s✗ %s: %v%s %s(gave up, see 'messages outbox')%s\n", ts, colorRed, r.msg.ThreadTitle, r.err, colorReset, colorDim, colorReset)
+		return
+	}
+	fmt.Printf("%s %s✗ %s: %v%s %s(retrying in %s)%s\n", ts, colorRed, r.msg.ThreadTitle, r.err, colorReset, colorDim, r.retryIn, colorReset)
+}
+
+// flushSenders returns the logged-in account, or every account with
+// --all-accounts
+func flushSenders(cmd *cli.Command) ([]outboxSender, *storage.Storage, error) {
+	if !cmd.Bool("all-accounts") {
+		c, store, err := loadClient(cmd)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []outboxSender{{c: c, current: true}}, store, nil
+	}
+
+	accounts, err := loadAccounts(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store, err := storage.NewSessionStorage()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize session storage: %w", err)
+	}
+
+	current, err := store.LoadSession()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load session: %w", err)
+	}
+
+	senders := make([]outboxSender, len(accounts))
+	for i, a := range accounts {
+		senders[i] = outboxSender{c: a.c, current: current != nil && a.name == current.AccountName()}
+	}
+	return senders, store, nil
+}
+
+func flushAction(ctx context.Context, cmd *cli.Command) error {
+	interval := cmd.Duration("interval")
+	if interval < time.Second {
+		return cli.Exit("interval must be at least 1s", exitUsage)
+	}
+
+	senders, store, err := flushSenders(cmd)
+	if err != nil {
+		return scriptError(err)
+	}
+
+	failed := 0
+	report := func(r flushResult) {
+		if r.err != nil {
+			failed++
+		}
+		printFlushResult(r)
+	}
+
+	if !cmd.Bool("watch") {
+		for _, sender := range senders {
+			if err := flushOutbox(sender, store, report); err != nil {
+				return scriptError(err)
+			}
+		}
+
+		outbox, err := store.LoadOutbox()
+		if err != nil {
+			return scriptError(fmt.Errorf("failed to load outbox: %w", err))
+		}
+		waiting, gaveUp := 0, 0
+		for _, msg := range outbox.Messages {
+			if msg.Failed {
+				gaveUp++
+			} else {
+				waiting++
+			}
+		}
+		if waiting > 0 {
+			fmt.Printf("%s%d messages still queued%s\n", colorDim, waiting, colorReset)
+		}
+		if gaveUp > 0 {
+			fmt.Printf("%s%d messages failed for good, see 'messages outbox'%s\n", colorYellow, gaveUp, colorReset)
+		}
+		if failed > 0 {
+			return cli.Exit("", exitFailure)
+		}
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("%s📤 Sending queued messages as they fall due. Press Ctrl+C to stop.%s\n", colorDim, colorReset)
+
+	for {
+		for _, sender := range senders {
+			if err := flushOutbox(sender, store, report); err != nil {
+				fmt.Fprintf(os.Stderr, "flush: %v\n", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+func outboxListAction(ctx context.Context, cmd *cli.Command) error {
+	store, err := storage.NewSessionStorage()
+	if err != nil {
+		return scriptError(fmt.Errorf("failed to initialize session storage: %w", err))
+	}
+
+	outbox, err := store.LoadOutbox()
+	if err != nil {
+		return scriptError(fmt.Errorf("failed to load outbox: %w", err))
+	}
+
+	if cmd.Bool("json") {
+		if outbox.Messages == nil {
+			outbox.Messages = []storage.OutboxMessage{}
+		}
+		return printJSON(outbox.Messages)
+	}
+
+	if len(outbox.Messages) == 0 {
+		fmt.Println("The outbox is empty")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "#\tTHREAD\tSEND AT\tATTEMPTS\tTEXT")
+	for i, msg := range outbox.Messages {
+		attempts := strconv.Itoa(msg.Attempts)
+		if msg.Failed {
+			attempts += " failed"
+		}
+		if msg.LastError != "" {
+			attempts += " (" + truncateString(msg.LastError, 40) + ")"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n",
+			i+1, msg.ThreadTitle, msg.SendAt.Format("2006-01-02 15:04"), attempts, truncateString(singleLine(msg.Text), 50))
+	}
+	return nil
+}
+
+func outboxCancelAction(ctx context.Context, cmd *cli.Command) error {
+	n, err := strconv.Atoi(cmd.Args().First())
+	if cmd.NArg() != 1 || err != nil {
+		return cli.Exit("usage: messages outbox cancel <n>", exitUsage)
+	}
+
+	store, err := storage.NewSessionStorage()
+	if err != nil {
+		return scriptError(fmt.Errorf("failed to initialize session storage: %w", err))
+	}
+
+	var cancelled *storage.OutboxMessage
+	err = store.UpdateOutbox(func(outbox *storage.Outbox) {
+		if n >= 1 && n <= len(outbox.Messages) {
+			msg := outbox.Messages[n-1]
+			cancelled = &msg
+			outbox.Messages = append(outbox.Messages[:n-1], outbox.Messages[n:]...)
+		}
+	})
+	if err != nil {
+		return scriptError(fmt.Errorf("failed to update outbox: %w", err))
+	}
+
+	if cancelled == nil {
+		return cli.Exit(fmt.Sprintf("no queued message #%d", n), exitNotFound)
+	}
+
+	fmt.Printf("%s✓ Cancelled message to %s%s\n", colorGreen, cancelled.ThreadTitle, colorReset)
+	return nil
+}
+
+// sendLater is /at: it schedules a message in the open conversation
+func (v *chatView) sendLater(args string) error {
+	at, text, _ := strings.Cut(args, " ")
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("usage: /at <time> <text>")
+	}
+
+	sendAt, err := parseSendAt(at, time.Now())
+	if err != nil {
+		return err
+	}
+
+	store, err := storage.NewSessionStorage()
+	if err != nil {
+		return fmt.Errorf("failed to initialize session storage: %w", err)
+	}
+
+	_, err = queueMessage(v.c, store, v.conv, text, sendAt, instagram.SendOptions{})
+	return err
+}
+
+// queueOffline moves a message that couldn't reach Instagram to the outbox,
+// keeping its client context so a later flush can't send it twice
+func (v *chatView) queueOffline(msg *instagram.Message, opts instagram.SendOptions) bool {
+	store, err := storage.NewSessionStorage()
+	if err != nil {
+		return false
+	}
+
+	opts.ClientContext = msg.ClientContext
+	if _, err := queueMessage(v.c, store, v.conv, msg.Text, time.Now(), opts); err != nil {
+		return false
+	}
+
+	msg.State = instagram.SendQueued
+	return true
+}
@@ -0,0 +1,43 @@
+package messages
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([a-z_]+)\s*\}\}`)
+
+// replyVars are the placeholders generated replies can use
+func replyVars(username, fullName, threadTitle string) map[string]string {
+	if fullName == "" {
+		fullName = username
+	}
+
+	firstName := fullName
+	if fields := strings.Fields(fullName); len(fields) > 0 {
+		firstName = fields[0]
+	}
+
+	now := time.Now()
+	return map[string]string{
+		"username":   username,
+		"full_name":  fullName,
+		"first_name": firstName,
+		"thread":     threadTitle,
+		"date":       now.Format(time.DateOnly),
+		"time":       now.Format("15:04"),
+	}
+}
+
+// expandPlaceholders replaces {{name}} with its value. Unknown names are left
+// as typed so mistakes are visible in the sent text.
+func expandPlaceholders(text string, vars map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return match
+	})
+}
@@ -0,0 +1,151 @@
+package messages
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/termimg"
+)
+
+// Size of an inline preview in terminal cells
+const (
+	previewCols = 24
+	previewRows = 8
+)
+
+// imagePreviews draws thumbnails of photos and shares under messages in the
+// line-based interface. Images are fetched in the background and drawn on a
+// later redraw; encoded images are kept so redraws don't download them again.
+type imagePreviews struct {
+	protocol termimg.Protocol
+
+	mu      sync.Mutex
+	c       *instagram.Client
+	encoded map[string]string
+	loading map[string]bool
+
+	// ready wakes the chat view when a preview finished loading
+	ready chan struct{}
+
+	// slots bounds the downloads running at once
+	slots chan struct{}
+}
+
+// previewTimeout bounds each preview download, so a slow URL only costs
+// its own preview
+const previewTimeout = 10 * time.Second
+
+// previews is nil unless the terminal can show images
+var previews *imagePreviews
+
+// setupPreviews enables inline images as picked by the --images flag
+func setupPreviews(cmd *cli.Command, c *instagram.Client) error {
+	protocol, err := termimg.ParseProtocol(cmd.String("images"))
+	if err != nil {
+		return err
+	}
+
+	if protocol == termimg.None {
+		previews = nil
+		return nil
+	}
+
+	previews = &imagePreviews{
+		c:        c,
+		protocol: protocol,
+		encoded:  make(map[string]string),
+		loading:  make(map[string]bool),
+		ready:    make(chan struct{}, 1),
+		slots:    make(chan struct{}, 4),
+	}
+	return nil
+}
+
+// use switches the client that downloads previews
+func (p *imagePreviews) use(c *instagram.Client) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.c = c
+}
+
+// readyChan fires when a preview is ready to draw. It never fires when
+// previews are off.
+func (p *imagePreviews) readyChan() <-chan struct{} {
+	if p == nil {
+		return nil
+	}
+	return p.ready
+}
+
+// print draws the image at url below a message, indented by padding. An
+// image that isn't loaded yet is fetched and left out of this draw.
+func (p *imagePreviews) print(padding string, url string) {
+	if p == nil || url == "" {
+		return
+	}
+
+	p.mu.Lock()
+	seq, ok := p.encoded[url]
+	if !ok && !p.loading[url] {
+		p.loading[url] = true
+		go p.load(p.c, url)
+	}
+	p.mu.Unlock()
+
+	if seq != "" {
+		fmt.Print(padding + seq)
+	}
+}
+
+// load fetches and encodes one preview. Failures are remembered too, so a
+// broken URL isn't retried on every redraw.
+func (p *imagePreviews) load(c *instagram.Client, url string) {
+	p.slots <- struct{}{}
+	seq := p.encode(c, url)
+	<-p.slots
+
+	p.mu.Lock()
+	p.encoded[url] = seq
+	delete(p.loading, url)
+	p.mu.Unlock()
+
+	if seq != "" {
+		select {
+		case p.ready <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (p *imagePreviews) encode(c *instagram.Client, url string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), previewTimeout)
+	defer cancel()
+
+	var buf bytes.Buffer
+	if _, err := c.DownloadMedia(ctx, url, &buf); err != nil {
+		if c.Debug {
+			fmt.Printf("[DEBUG] Failed to download preview: %v\n", err)
+		}
+		return ""
+	}
+
+	img, err := termimg.Decode(&buf)
+	if err != nil {
+		if c.Debug {
+			fmt.Printf("[DEBUG] %v\n", err)
+		}
+		return ""
+	}
+
+	return termimg.Encode(img, p.protocol, previewCols, previewRows)
+}
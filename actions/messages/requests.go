@@ -0,0 +1,241 @@
+package messages
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+)
+
+var requestsCommand = &cli.Command{
+	Name:    "requests",
+	Aliases: []string{"req", "pending"},
+	Usage:   "List pending message requests",
+	Commands: []*cli.Command{
+		{
+			Name:      "approve",
+			Usage:     "Approve message requests",
+			ArgsUsage: "<number|thread_id>...",
+			Action:    approveRequestsAction,
+		},
+		{
+			Name:      "decline",
+			Usage:     "Decline message requests",
+			ArgsUsage: "<number|thread_id>...",
+			Action:    declineRequestsAction,
+		},
+		{
+			Name:  "decline-all",
+			Usage: "Decline every pending message request",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "yes",
+					Aliases: []string{"y"},
+					Usage:   "Skip the confirmation prompt",
+				},
+			},
+			Action: declineAllRequestsAction,
+		},
+		triageCommand,
+	},
+	Action: requestsAction,
+}
+
+func requestsAction(ctx context.Context, cmd *cli.Command) error {
+	c, _, err := loadClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	list, err := c.GetPendingRequests()
+	if err != nil {
+		return fmt.Errorf("failed to fetch message requests: %w", err)
+	}
+
+	displayRequests(list)
+	return nil
+}
+
+func approveRequestsAction(ctx context.Context, cmd *cli.Command) error {
+	return applyToRequests(cmd, "Approved", func(c *instagram.Client, threadID string) error {
+		return c.ApproveThread(threadID)
+	})
+}
+
+func declineRequestsAction(ctx context.Context, cmd *cli.Command) error {
+	return applyToRequests(cmd, "Declined", func(c *instagram.Client, threadID string) error {
+		return c.DeclineThread(threadID)
+	})
+}
+
+func applyToRequests(cmd *cli.Command, verb string, apply func(*instagram.Client, string) error) error {
+	if cmd.NArg() == 0 {
+		return fmt.Errorf("at least one request number or thread ID is required")
+	}
+
+	c, _, err := loadClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	list, err := c.GetPendingRequests()
+	if err != nil {
+		return fmt.Errorf("failed to fetch message requests: %w", err)
+	}
+
+	var failed int
+	for _, arg := range cmd.Args().Slice() {
+		conv, err := findRequest(list.Conversations, arg)
+		if err != nil {
+			fmt.Printf("%s✗ %v%s\n", colorRed, err, colorReset)
+			failed++
+			continue
+		}
+
+		if err := apply(c, conv.ThreadID); err != nil {
+			fmt.Printf("%s✗ %s: %v%s\n", colorRed, conv.Title, err, colorReset)
+			failed++
+			continue
+		}
+
+		fmt.Printf("%s✓ %s %s%s\n", colorGreen, verb, conv.Title, colorReset)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d request(s) failed", failed)
+	}
+	return nil
+}
+
+func declineAllRequestsAction(ctx context.Context, cmd *cli.Command) error {
+	c, _, err := loadClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.Bool("yes") {
+		list, err := c.GetPendingRequests()
+		if err != nil {
+			return fmt.Errorf("failed to fetch message requests: %w", err)
+		}
+		if len(list.Conversations) == 0 {
+			fmt.Printf("%s📭 No pending message requests.%s\n", colorDim, colorReset)
+			return nil
+		}
+
+		fmt.Printf("Decline all %d pending request(s)? [y/N]: ", len(list.Conversations))
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if !isYes(answer) {
+			fmt.Println("Cancelled")
+			return nil
+		}
+	}
+
+	if err := c.DeclineAllThreads(); err != nil {
+		return fmt.Errorf("failed to decline requests: %w", err)
+	}
+
+	fmt.Printf("%s✓ All message requests declined%s\n", colorGreen, colorReset)
+	return nil
+}
+
+// findRequest resolves a 1-based list number or a raw thread ID
+func findRequest(conversations []instagram.Conversation, arg string) (instagram.Conversation, error) {
+	if num, err := strconv.Atoi(arg); err == nil && num >= 1 && num <= len(conversations) {
+		return conversations[num-1], nil
+	}
+
+	for _, conv := range conversations {
+		if conv.ThreadID == arg {
+			return conv, nil
+		}
+	}
+
+	return instagram.Conversation{}, fmt.Errorf("no pending request matches %q", arg)
+}
+
+func isYes(answer string) bool {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func displayRequests(list *instagram.ConversationList) {
+	fmt.Printf("\n%s%s📨 Message requests (%d)%s\n", colorBold, colorYellow, len(list.Conversations), colorReset)
+	displayConversations(list.Conversations)
+}
+
+func runRequestsMode(c *instagram.Client, reader *bufio.Reader) error {
+	clearScreen()
+
+	for {
+		list, err := c.GetPendingRequests()
+		if err != nil {
+			return fmt.Errorf("failed to fetch message requests: %w", err)
+		}
+
+		displayRequests(list)
+
+		fmt.Printf("\n%s─────────────────────────────────────────────────────────%s\n", colorDim, colorReset)
+		fmt.Printf("%sCommands:%s [number] Preview • %sa <n>%s Approve • %sd <n>%s Decline • %sD%s Decline all • %sb%s Back\n",
+			colorCyan, colorReset, colorGreen, colorReset, colorRed, colorReset, colorRed, colorReset, colorYellow, colorReset)
+		fmt.Printf("%s➜ %s", colorGreen, colorReset)
+
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		fields := strings.Fields(input)
+
+		switch {
+		case input == "":
+			clearScreen()
+		case input == "b" || input == "back":
+			return nil
+		case input == "D":
+			fmt.Printf("Decline all %d pending request(s)? [y/N]: ", len(list.Conversations))
+			answer, _ := reader.ReadString('\n')
+			if isYes(answer) {
+				reportRequestAction(c.DeclineAllThreads(), "All requests declined")
+			}
+			clearScreen()
+		case len(fields) == 2 && (fields[0] == "a" || fields[0] == "d"):
+			conv, err := findRequest(list.Conversations, fields[1])
+			if err != nil {
+				reportRequestAction(err, "")
+			} else if fields[0] == "a" {
+				reportRequestAction(c.ApproveThread(conv.ThreadID), "Approved "+conv.Title)
+			} else {
+				reportRequestAction(c.DeclineThread(conv.ThreadID), "Declined "+conv.Title)
+			}
+			clearScreen()
+		default:
+			conv, err := findRequest(list.Conversations, input)
+			if err != nil {
+				reportRequestAction(err, "")
+				clearScreen()
+				continue
+			}
+
+			if err := openConversation(c, conv, reader); err != nil {
+				fmt.Printf("%s✗ Error: %v%s\n", colorRed, err, colorReset)
+				time.Sleep(2 * time.Second)
+			}
+			clearScreen()
+		}
+	}
+}
+
+func reportRequestAction(err error, success string) {
+	if err != nil {
+		fmt.Printf("%s✗ %v%s\n", colorRed, err, colorReset)
+		time.Sleep(2 * time.Second)
+		return
+	}
+	fmt.Printf("%s✓ %s%s\n", colorGreen, success, colorReset)
+	time.Sleep(1 * time.Second)
+}
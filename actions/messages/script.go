@@ -0,0 +1,329 @@
+package messages
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/storage"
+)
+
+// Exit codes returned by the scripting subcommands
+const (
+	exitFailure     = 1
+	exitUsage       = 2
+	exitNotFound    = 3
+	exitNotLoggedIn = 4
+	exitNoUnread    = 5
+)
+
+func jsonFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print machine-readable JSON",
+	}
+}
+
+var listCommand = &cli.Command{
+	Name:    "list",
+	Aliases: []string{"ls"},
+	Usage:   "Print the inbox",
+	Flags:   []cli.Flag{jsonFlag()},
+	Action:  listAction,
+}
+
+var showCommand = &cli.Command{
+	Name:      "show",
+	Usage:     "Print the latest messages of a conversation",
+	ArgsUsage: "<thread|@user>",
+	Flags: []cli.Flag{
+		jsonFlag(),
+		&cli.IntFlag{
+			Name:    "limit",
+			Aliases: []string{"n"},
+			Value:   20,
+			Usage:   "Number of messages to print",
+		},
+	},
+	Action: showAction,
+}
+
+var sendCommand = &cli.Command{
+	Name:      "send",
+	Usage:     "Send a text message (use - to read it from stdin)",
+	ArgsUsage: "<thread|@user> <text|->",
+	Flags: []cli.Flag{
+		jsonFlag(),
+		&cli.StringFlag{
+			Name:  "at",
+			Usage: "Queue the message for later: 15:04, 2006-01-02 15:04 or a delay like 30m (sent by 'messages flush')",
+		},
+	},
+	Action: sendAction,
+}
+
+var unreadCommand = &cli.Command{
+	Name:  "unread",
+	Usage: "Print conversations with unread messages",
+	Flags: []cli.Flag{
+		jsonFlag(),
+		&cli.BoolFlag{
+			Name:  "exit-code",
+			Usage: fmt.Sprintf("Exit with status %d when nothing is unread", exitNoUnread),
+		},
+	},
+	Action: unreadAction,
+}
+
+// scriptError maps errors to the exit codes scripts can rely on
+func scriptError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, errNotLoggedIn):
+		return cli.Exit(err.Error(), exitNotLoggedIn)
+	case errors.Is(err, errThreadNotFound), errors.Is(err, instagram.ErrUserNotFound):
+		return cli.Exit(err.Error(), exitNotFound)
+	default:
+		return cli.Exit(err.Error(), exitFailure)
+	}
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func listAction(ctx context.Context, cmd *cli.Command) error {
+	conversations, err := loadFilteredInbox(cmd)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Bool("json") {
+		return printJSON(conversations)
+	}
+
+	printConversationTable(conversations)
+	return nil
+}
+
+// loadFilteredInbox fetches the folder picked by the inbox flags and applies
+// their filter and order
+func loadFilteredInbox(cmd *cli.Command) ([]instagram.Conversation, error) {
+	folder, err := instagram.ParseFolder(cmd.String("folder"))
+	if err != nil {
+		return nil, cli.Exit(err.Error(), exitUsage)
+	}
+
+	filter, err := inboxFilterFromFlags(cmd)
+	if err != nil {
+		return nil, cli.Exit(err.Error(), exitUsage)
+	}
+
+	c, _, err := loadClient(cmd)
+	if err != nil {
+		return nil, scriptError(err)
+	}
+
+	list, err := fetchInbox(c, folder, cmd.Int("pages"))
+	if err != nil {
+		return nil, scriptError(fmt.Errorf("failed to fetch inbox: %w", err))
+	}
+
+	return filter.apply(list.Conversations), nil
+}
+
+func unreadAction(ctx context.Context, cmd *cli.Command) error {
+	conversations, err := loadFilteredInbox(cmd)
+	if err != nil {
+		return err
+	}
+
+	unread := []instagram.Conversation{}
+	for _, conv := range conversations {
+		if conv.UnreadCount > 0 {
+			unread = append(unread, conv)
+		}
+	}
+
+	if cmd.Bool("json") {
+		if err := printJSON(unread); err != nil {
+			return scriptError(err)
+		}
+	} else {
+		printConversationTable(unread)
+	}
+
+	if len(unread) == 0 && cmd.Bool("exit-code") {
+		return cli.Exit("", exitNoUnread)
+	}
+	return nil
+}
+
+func showAction(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() != 1 {
+		return cli.Exit("usage: messages show <thread|@user> [--limit N]", exitUsage)
+	}
+
+	c, _, err := loadClient(cmd)
+	if err != nil {
+		return scriptError(err)
+	}
+
+	conv, err := resolveThread(c, cmd.Args().First())
+	if err != nil {
+		return scriptError(err)
+	}
+
+	messages, _, err := c.GetMessages(conv.ThreadID, cmd.Int("limit"))
+	if err != nil {
+		return scriptError(fmt.Errorf("failed to fetch messages: %w", err))
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Timestamp.Before(messages[j].Timestamp)
+	})
+
+	if cmd.Bool("json") {
+		if messages == nil {
+			messages = []instagram.Message{}
+		}
+		return printJSON(messages)
+	}
+
+	for _, msg := range messages {
+		fmt.Println(formatPlainMessage(msg))
+	}
+	return nil
+}
+
+func sendAction(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() != 2 {
+		return cli.Exit("usage: messages send <thread|@user> <text|->", exitUsage)
+	}
+
+	text := cmd.Args().Get(1)
+	if text == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return scriptError(fmt.Errorf("failed to read stdin: %w", err))
+		}
+		text = strings.TrimRight(string(data), "\n")
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return cli.Exit("message text is empty", exitUsage)
+	}
+
+	var sendAt time.Time
+	if at := cmd.String("at"); at != "" {
+		var err error
+		if sendAt, err = parseSendAt(at, time.Now()); err != nil {
+			return cli.Exit(err.Error(), exitUsage)
+		}
+	}
+
+	c, store, err := loadClient(cmd)
+	if err != nil {
+		return scriptError(err)
+	}
+
+	arg := cmd.Args().First()
+	conv, err := resolveThread(c, arg)
+	if instagram.IsNetworkError(err) && isThreadID(arg) {
+		// Offline, but a thread ID is enough to queue for
+		conv, err = instagram.Conversation{ThreadID: arg, Title: arg}, nil
+		sendAt = time.Now()
+	}
+	if err != nil {
+		return scriptError(err)
+	}
+
+	if !sendAt.IsZero() {
+		return queueAndReport(cmd, c, store, conv, text, sendAt, instagram.SendOptions{})
+	}
+
+	opts := instagram.SendOptions{ClientContext: instagram.NewClientContext()}
+	resp, err := c.SendTextMessage(conv.ThreadID, text, opts)
+	if instagram.IsNetworkError(err) {
+		fmt.Fprintf(os.Stderr, "Instagram is unreachable (%v)\n", err)
+		return queueAndReport(cmd, c, store, conv, text, time.Now(), opts)
+	}
+	if err != nil {
+		return scriptError(fmt.Errorf("failed to send message: %w", err))
+	}
+
+	if cmd.Bool("json") {
+		return printJSON(resp.Payload)
+	}
+
+	fmt.Printf("sent %s to %s\n", resp.Payload.ItemID, conv.Title)
+	return nil
+}
+
+// queueAndReport puts a message in the outbox and prints where it went
+func queueAndReport(cmd *cli.Command, c *instagram.Client, store *storage.Storage, conv instagram.Conversation, text string, sendAt time.Time, opts instagram.SendOptions) error {
+	msg, err := queueMessage(c, store, conv, text, sendAt, opts)
+	if err != nil {
+		return scriptError(err)
+	}
+
+	if cmd.Bool("json") {
+		return printJSON(msg)
+	}
+
+	fmt.Printf("queued for %s at %s\n", conv.Title, sendAt.Format("2006-01-02 15:04"))
+	return nil
+}
+
+func printConversationTable(conversations []instagram.Conversation) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "#\tTHREAD\tTITLE\tUNREAD\tLAST\tPREVIEW")
+	for i, conv := range conversations {
+		last := ""
+		if !conv.LastMessageAt.IsZero() {
+			last = conv.LastMessageAt.Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\t%s\n",
+			i+1, conv.ThreadID, conv.Title, conv.UnreadCount, last, singleLine(conv.LastMessage))
+	}
+}
+
+// formatPlainMessage renders a message as one greppable line
+func formatPlainMessage(msg instagram.Message) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s  %s: %s", msg.Timestamp.Format(time.DateTime), msg.SenderName, singleLine(msg.Text))
+
+	if msg.ReplyTo != nil {
+		fmt.Fprintf(&b, "  (reply to %s: %s)", msg.ReplyTo.SenderName, truncateString(singleLine(msg.ReplyTo.Text), 40))
+	}
+
+	if len(msg.Reactions) > 0 {
+		reactions := make([]string, 0, len(msg.Reactions))
+		for _, r := range msg.Reactions {
+			reactions = append(reactions, r.Emoji+" "+r.SenderName)
+		}
+		fmt.Fprintf(&b, "  [%s]", strings.Join(reactions, ", "))
+	}
+
+	return b.String()
+}
+
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
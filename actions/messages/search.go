@@ -0,0 +1,488 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	"golang.org/x/term"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/storage"
+)
+
+var searchCommand = &cli.Command{
+	Name:      "search",
+	Usage:     "Search the locally synced conversations",
+	ArgsUsage: "<query>",
+	Flags: []cli.Flag{
+		jsonFlag(),
+		&cli.StringFlag{
+			Name:  "from",
+			Usage: "Only messages sent by @user (@me for your own)",
+		},
+		&cli.StringFlag{
+			Name:  "since",
+			Usage: "Only messages after a date (2006-01-02) or within a period (7d, 2w, 12h)",
+		},
+		&cli.StringFlag{
+			Name:  "type",
+			Usage: "Only one kind of message: text, link, post, reel, story, voice, media",
+		},
+		&cli.StringFlag{
+			Name:  "thread",
+			Usage: "Only search one conversation (number, thread ID or @user)",
+		},
+		&cli.IntFlag{
+			Name:    "limit",
+			Aliases: []string{"n"},
+			Value:   50,
+			Usage:   "Maximum number of matches to print",
+		},
+		&cli.BoolFlag{
+			Name:  "sync",
+			Usage: "Sync every conversation on the first inbox page before searching",
+		},
+	},
+	Action: searchAction,
+}
+
+// searchTypes maps the --type names to item types
+var searchTypes = map[string][]string{
+	"text":  {"text"},
+	"link":  {"link"},
+	"post":  {"media_share"},
+	"reel":  {"reel_share", "clip"},
+	"story": {"story_share"},
+	"voice": {"voice_media"},
+	"media": {"media", "raven_media", "visual_media"},
+}
+
+// searchQuery is a parsed search. Every term has to appear in a match.
+type searchQuery struct {
+	terms    []string
+	from     string
+	since    time.Time
+	types    map[string]bool
+	threadID string
+}
+
+// searchMatch is one matching message
+type searchMatch struct {
+	ThreadID    string    `json:"thread_id"`
+	ThreadTitle string    `json:"thread_title"`
+	ItemID      string    `json:"item_id"`
+	ItemType    string    `json:"item_type"`
+	Sender      string    `json:"sender"`
+	FromMe      bool      `json:"from_me"`
+	Text        string    `json:"text"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+func searchAction(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() == 0 {
+		return cli.Exit("usage: messages search <query> [--from @user] [--since date] [--type link]", exitUsage)
+	}
+
+	query := searchQuery{
+		terms: strings.Fields(strings.ToLower(strings.Join(cmd.Args().Slice(), " "))),
+		from:  strings.ToLower(strings.TrimPrefix(cmd.String("from"), "@")),
+	}
+
+	if since := cmd.String("since"); since != "" {
+		t, err := parseSince(since, time.Now())
+		if err != nil {
+			return cli.Exit(err.Error(), exitUsage)
+		}
+		query.since = t
+	}
+
+	if kind := strings.ToLower(cmd.String("type")); kind != "" {
+		types, ok := searchTypes[kind]
+		if !ok {
+			// Raw item types such as animated_media still work
+			types = []string{kind}
+		}
+		query.types = make(map[string]bool, len(types))
+		for _, t := range types {
+			query.types[t] = true
+		}
+	}
+
+	c, store, err := loadClient(cmd)
+	if err != nil {
+		return scriptError(err)
+	}
+
+	if arg := cmd.String("thread"); arg != "" {
+		conv, err := resolveThread(c, arg)
+		if err != nil {
+			return scriptError(err)
+		}
+		query.threadID = conv.ThreadID
+	}
+
+	if cmd.Bool("sync") {
+		if err := syncInbox(c, store, query.threadID); err != nil {
+			return scriptError(err)
+		}
+	}
+
+	index, err := updateSearchIndex(c, store)
+	if err != nil {
+		return scriptError(err)
+	}
+
+	if len(index.Threads) == 0 {
+		return cli.Exit("nothing synced yet, run 'messages export' or 'messages search --sync' first", exitNotFound)
+	}
+
+	matches := query.run(index)
+	if limit := cmd.Int("limit"); limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	if cmd.Bool("json") {
+		if matches == nil {
+			matches = []searchMatch{}
+		}
+		return printJSON(matches)
+	}
+
+	highlight := term.IsTerminal(int(os.Stdout.Fd()))
+	for _, m := range matches {
+		fmt.Println(formatSearchMatch(m, query.terms, highlight))
+	}
+
+	if len(matches) == 0 {
+		return cli.Exit("no matches", exitNotFound)
+	}
+	return nil
+}
+
+// syncInbox syncs the history of every conversation on the first inbox
+// page, or of just threadID when it's set
+func syncInbox(c *instagram.Client, store *storage.Storage, threadID string) error {
+	if threadID != "" {
+		_, err := syncHistory(c, store, threadID)
+		return err
+	}
+
+	list, err := c.GetConversations()
+	if err != nil {
+		return fmt.Errorf("failed to fetch inbox: %w", err)
+	}
+
+	for _, conv := range list.Conversations {
+		fmt.Fprintf(os.Stderr, "%sSyncing %s%s\n", colorDim, conv.Title, colorReset)
+		if _, err := syncHistory(c, store, conv.ThreadID); err != nil {
+			return fmt.Errorf("failed to sync %s: %w", conv.Title, err)
+		}
+	}
+	return nil
+}
+
+// updateSearchIndex reindexes every synced thread whose history changed
+// since it was last indexed
+func updateSearchIndex(c *instagram.Client, store *storage.Storage) (*storage.SearchIndex, error) {
+	index, err := store.LoadSearchIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	threadIDs, err := store.ListHistories()
+	if err != nil {
+		return nil, err
+	}
+
+	changed := false
+	synced := make(map[string]bool, len(threadIDs))
+
+	for _, threadID := range threadIDs {
+		synced[threadID] = true
+
+		thread, history, err := loadHistory(store, threadID)
+		if err != nil {
+			return nil, err
+		}
+		if thread == nil {
+			continue
+		}
+
+		if indexed := index.Threads[threadID]; indexed != nil && indexed.UpdatedAt == history.UpdatedAt {
+			continue
+		}
+
+		index.Threads[threadID] = indexThread(c, thread, history.UpdatedAt)
+		changed = true
+	}
+
+	for threadID := range index.Threads {
+		if !synced[threadID] {
+			delete(index.Threads, threadID)
+			changed = true
+		}
+	}
+
+	if changed {
+		if err := store.SaveSearchIndex(index); err != nil {
+			return nil, err
+		}
+	}
+	return index, nil
+}
+
+func indexThread(c *instagram.Client, thread *instagram.Thread, updatedAt int64) *storage.IndexedThread {
+	indexed := &storage.IndexedThread{
+		Title:     instagram.ThreadTitle(*thread),
+		UpdatedAt: updatedAt,
+	}
+
+	// ThreadMessages keeps the order of the items, so both line up
+	messages, _ := c.ThreadMessages(thread)
+	for i, msg := range messages {
+		text := thread.Items[i].SearchText()
+		if text == "" {
+			continue
+		}
+
+		sender := msg.SenderName
+		if msg.IsFromMe && c.Username != "" {
+			sender = c.Username
+		}
+
+		indexed.Entries = append(indexed.Entries, storage.SearchEntry{
+			ItemID:    msg.ID,
+			ItemType:  msg.Type,
+			Sender:    sender,
+			FromMe:    msg.IsFromMe,
+			Text:      text,
+			Timestamp: msg.Timestamp,
+		})
+	}
+
+	return indexed
+}
+
+// run returns the matches in index, newest first
+func (q searchQuery) run(index *storage.SearchIndex) []searchMatch {
+	var matches []searchMatch
+
+	for threadID, thread := range index.Threads {
+		if q.threadID != "" && threadID != q.threadID {
+			continue
+		}
+
+		for _, entry := range thread.Entries {
+			if !q.matches(entry) {
+				continue
+			}
+
+			sender := entry.Sender
+			if entry.FromMe {
+				sender = "You"
+			}
+
+			matches = append(matches, searchMatch{
+				ThreadID:    threadID,
+				ThreadTitle: thread.Title,
+				ItemID:      entry.ItemID,
+				ItemType:    entry.ItemType,
+				Sender:      sender,
+				FromMe:      entry.FromMe,
+				Text:        entry.Text,
+				Timestamp:   entry.Timestamp,
+			})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Timestamp.After(matches[j].Timestamp)
+	})
+	return matches
+}
+
+func (q searchQuery) matches(entry storage.SearchEntry) bool {
+	if q.types != nil && !q.types[entry.ItemType] {
+		return false
+	}
+
+	if !q.since.IsZero() && entry.Timestamp.Before(q.since) {
+		return false
+	}
+
+	if q.from != "" {
+		if q.from == "me" {
+			if !entry.FromMe {
+				return false
+			}
+		} else if strings.ToLower(entry.Sender) != q.from {
+			return false
+		}
+	}
+
+	text := strings.ToLower(entry.Text)
+	for _, t := range q.terms {
+		if !strings.Contains(text, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseSince accepts a date, a date and time, or a period back from now
+// such as 7d, 2w or 12h
+func parseSince(s string, now time.Time) (time.Time, error) {
+	for _, layout := range []string{time.DateOnly, "2006-01-02 15:04", time.DateTime} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	if n, err := strconv.Atoi(s[:len(s)-1]); err == nil && n >= 0 {
+		switch s[len(s)-1] {
+		case 'd':
+			return now.AddDate(0, 0, -n), nil
+		case 'w':
+			return now.AddDate(0, 0, -7*n), nil
+		}
+	}
+
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid --since %q (use 2006-01-02 or a period like 7d)", s)
+}
+
+// formatSearchMatch renders a match as one line, trimmed to the text around
+// the first hit
+func formatSearchMatch(m searchMatch, terms []string, highlight bool) string {
+	text := searchSnippet(singleLine(m.Text), terms, 120)
+	ts := m.Timestamp.Format("2006-01-02 15:04")
+
+	if !highlight {
+		return fmt.Sprintf("%s  [%s] %s: %s", ts, m.ThreadTitle, m.Sender, text)
+	}
+
+	return fmt.Sprintf("%s%s%s  %s[%s]%s %s%s:%s %s",
+		colorDim, ts, colorReset, colorCyan, m.ThreadTitle, colorReset,
+		colorBold, m.Sender, colorReset, highlightTerms(text, terms))
+}
+
+// searchSnippet cuts text down to about width runes, keeping the first
+// matching term in view
+func searchSnippet(text string, terms []string, width int) string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+
+	lower := []rune(strings.ToLower(text))
+	first := -1
+	for _, t := range terms {
+		if i := runeIndex(lower, []rune(t)); i >= 0 && (first < 0 || i < first) {
+			first = i
+		}
+	}
+
+	start := max(0, first-width/3)
+	end := min(len(runes), start+width)
+	start = max(0, end-width)
+
+	snippet := string(runes[start:end])
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+func highlightTerms(text string, terms []string) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		// Case folding changed the length; skip highlighting rather than
+		// mark the wrong characters
+		return text
+	}
+
+	marked := make([]bool, len(runes))
+	for _, t := range terms {
+		tr := []rune(t)
+		for i := 0; i+len(tr) <= len(lower); i++ {
+			if string(lower[i:i+len(tr)]) == t {
+				for j := i; j < i+len(tr); j++ {
+					marked[j] = true
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	for i, r := range runes {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			b.WriteString(colorYellow + colorBold)
+		}
+		b.WriteRune(r)
+		if marked[i] && (i == len(runes)-1 || !marked[i+1]) {
+			b.WriteString(colorReset)
+		}
+	}
+	return b.String()
+}
+
+func runeIndex(s, sub []rune) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if string(s[i:i+len(sub)]) == string(sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+// search syncs the open conversation and prints the messages matching args
+func (v *chatView) search(args string) error {
+	if args == "" {
+		return fmt.Errorf("usage: /search <query>")
+	}
+
+	store, err := storage.NewSessionStorage()
+	if err != nil {
+		return err
+	}
+
+	if _, err := syncHistory(v.c, store, v.conv.ThreadID); err != nil {
+		return err
+	}
+
+	index, err := updateSearchIndex(v.c, store)
+	if err != nil {
+		return err
+	}
+
+	query := searchQuery{
+		terms:    strings.Fields(strings.ToLower(args)),
+		threadID: v.conv.ThreadID,
+	}
+	matches := query.run(index)
+
+	fmt.Printf("\n%sSearch results for %q%s (%d)\n\n", colorBold, args, colorReset, len(matches))
+	for _, m := range matches {
+		fmt.Println(formatSearchMatch(m, query.terms, true))
+	}
+	if len(matches) == 0 {
+		fmt.Printf("%sNo matches%s\n", colorDim, colorReset)
+	}
+
+	fmt.Printf("\n%sPress Enter to continue%s", colorDim, colorReset)
+	v.reader.ReadString('\n')
+	return nil
+}
@@ -0,0 +1,448 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/storage"
+)
+
+// maxResponseGap is the longest pause still counted as a reply. Anything
+// longer starts a new exchange rather than answering the last message.
+const maxResponseGap = 7 * 24 * time.Hour
+
+var statsCommand = &cli.Command{
+	Name:  "stats",
+	Usage: "Message counts, response times and activity by hour from synced history",
+	Flags: []cli.Flag{
+		jsonFlag(),
+		&cli.StringFlag{
+			Name:  "thread",
+			Usage: "Sync and analyse one conversation (number, thread ID or @user)",
+		},
+		&cli.StringFlag{
+			Name:  "since",
+			Usage: "Only count messages after a date (2006-01-02) or within a period (30d, 2w)",
+		},
+		&cli.BoolFlag{
+			Name:  "sync",
+			Usage: "Sync every conversation on the first inbox page first",
+		},
+	},
+	Action: statsAction,
+}
+
+// conversationStats summarizes the messages of one thread, or of several
+// when merged
+type conversationStats struct {
+	ThreadID string        `json:"thread_id,omitempty"`
+	Title    string        `json:"title"`
+	Messages int           `json:"messages"`
+	First    time.Time     `json:"first,omitempty"`
+	Last     time.Time     `json:"last,omitempty"`
+	Senders  []senderStats `json:"senders"`
+	Types    []typeCount   `json:"types"`
+
+	// Heatmap counts messages by local weekday (Sunday first) and hour
+	Heatmap [7][24]int `json:"heatmap"`
+
+	senders map[string]*senderStats
+	types   map[string]int
+}
+
+type senderStats struct {
+	Username  string `json:"username"`
+	FromMe    bool   `json:"from_me"`
+	Messages  int    `json:"messages"`
+	Responses int    `json:"responses"`
+
+	// MedianResponse is how long they usually take to answer, in seconds
+	MedianResponse float64 `json:"median_response_seconds,omitempty"`
+
+	responseTimes []time.Duration
+}
+
+type typeCount struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+func newConversationStats(threadID, title string) *conversationStats {
+	return &conversationStats{
+		ThreadID: threadID,
+		Title:    title,
+		senders:  make(map[string]*senderStats),
+		types:    make(map[string]int),
+	}
+}
+
+// collectStats counts the messages of a thread sent after since. A reply is
+// the first message after someone else's, timed from that message.
+func collectStats(c *instagram.Client, thread *instagram.Thread, since time.Time) *conversationStats {
+	stats := newConversationStats(thread.ThreadID, instagram.ThreadTitle(*thread))
+
+	messages, _ := c.ThreadMessages(thread)
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Timestamp.Before(messages[j].Timestamp)
+	})
+
+	var prev *instagram.Message
+	for i := range messages {
+		msg := &messages[i]
+		if msg.Timestamp.Before(since) {
+			continue
+		}
+
+		sender := stats.sender(msg.SenderName, msg.IsFromMe)
+		sender.Messages++
+
+		if prev != nil && prev.SenderName != msg.SenderName {
+			if gap := msg.Timestamp.Sub(prev.Timestamp); gap <= maxResponseGap {
+				sender.responseTimes = append(sender.responseTimes, gap)
+			}
+		}
+		prev = msg
+
+		stats.Messages++
+		stats.types[msg.Type]++
+
+		local := msg.Timestamp.Local()
+		stats.Heatmap[local.Weekday()][local.Hour()]++
+
+		if stats.First.IsZero() {
+			stats.First = msg.Timestamp
+		}
+		stats.Last = msg.Timestamp
+	}
+
+	stats.finish()
+	return stats
+}
+
+func (s *conversationStats) sender(username string, fromMe bool) *senderStats {
+	sender, ok := s.senders[username]
+	if !ok {
+		sender = &senderStats{Username: username, FromMe: fromMe}
+		s.senders[username] = sender
+	}
+	return sender
+}
+
+// merge adds another thread's numbers, for the all-conversations summary
+func (s *conversationStats) merge(other *conversationStats) {
+	s.Messages += other.Messages
+
+	if !other.First.IsZero() && (s.First.IsZero() || other.First.Before(s.First)) {
+		s.First = other.First
+	}
+	if other.Last.After(s.Last) {
+		s.Last = other.Last
+	}
+
+	for _, o := range other.senders {
+		sender := s.sender(o.Username, o.FromMe)
+		sender.Messages += o.Messages
+		sender.responseTimes = append(sender.responseTimes, o.responseTimes...)
+	}
+
+	for t, n := range other.types {
+		s.types[t] += n
+	}
+
+	for day := range s.Heatmap {
+		for hour := range s.Heatmap[day] {
+			s.Heatmap[day][hour] += other.Heatmap[day][hour]
+		}
+	}
+
+	s.finish()
+}
+
+// finish fills in the sorted, exported fields from the running totals
+func (s *conversationStats) finish() {
+	s.Senders = s.Senders[:0]
+	for _, sender := range s.senders {
+		sender.Responses = len(sender.responseTimes)
+		sender.MedianResponse = median(sender.responseTimes).Seconds()
+		s.Senders = append(s.Senders, *sender)
+	}
+	sort.Slice(s.Senders, func(i, j int) bool {
+		if s.Senders[i].Messages != s.Senders[j].Messages {
+			return s.Senders[i].Messages > s.Senders[j].Messages
+		}
+		return s.Senders[i].Username < s.Senders[j].Username
+	})
+
+	s.Types = s.Types[:0]
+	for t, n := range s.types {
+		s.Types = append(s.Types, typeCount{Type: t, Count: n})
+	}
+	sort.Slice(s.Types, func(i, j int) bool {
+		if s.Types[i].Count != s.Types[j].Count {
+			return s.Types[i].Count > s.Types[j].Count
+		}
+		return s.Types[i].Type < s.Types[j].Type
+	})
+}
+
+// responseTimes returns the reply delays of me or of everyone else
+func (s *conversationStats) responseTimes(fromMe bool) []time.Duration {
+	var times []time.Duration
+	for _, sender := range s.senders {
+		if sender.FromMe == fromMe {
+			times = append(times, sender.responseTimes...)
+		}
+	}
+	return times
+}
+
+func median(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func statsAction(ctx context.Context, cmd *cli.Command) error {
+	var since time.Time
+	if s := cmd.String("since"); s != "" {
+		t, err := parseSince(s, time.Now())
+		if err != nil {
+			return cli.Exit(err.Error(), exitUsage)
+		}
+		since = t
+	}
+
+	c, store, err := loadClient(cmd)
+	if err != nil {
+		return scriptError(err)
+	}
+
+	if arg := cmd.String("thread"); arg != "" {
+		conv, err := resolveThread(c, arg)
+		if err != nil {
+			return scriptError(err)
+		}
+
+		thread, err := syncHistory(c, store, conv.ThreadID)
+		if err != nil {
+			return scriptError(fmt.Errorf("failed to sync %s: %w", conv.Title, err))
+		}
+
+		stats := collectStats(c, thread, since)
+		if cmd.Bool("json") {
+			return printJSON(stats)
+		}
+
+		printStats(stats)
+		return nil
+	}
+
+	if cmd.Bool("sync") {
+		if err := syncInbox(c, store, ""); err != nil {
+			return scriptError(err)
+		}
+	}
+
+	threads, err := collectSyncedStats(c, store, since)
+	if err != nil {
+		return scriptError(err)
+	}
+	if len(threads) == 0 {
+		return cli.Exit("nothing synced yet, use --thread, --sync or 'messages export' first", exitNotFound)
+	}
+
+	total := newConversationStats("", fmt.Sprintf("All synced conversations (%d)", len(threads)))
+	for _, stats := range threads {
+		total.merge(stats)
+	}
+
+	if cmd.Bool("json") {
+		return printJSON(struct {
+			Total   *conversationStats   `json:"total"`
+			Threads []*conversationStats `json:"threads"`
+		}{total, threads})
+	}
+
+	printStats(total)
+	printThreadTable(threads)
+	return nil
+}
+
+// collectSyncedStats analyses every locally synced thread, busiest first
+func collectSyncedStats(c *instagram.Client, store *storage.Storage, since time.Time) ([]*conversationStats, error) {
+	threadIDs, err := store.ListHistories()
+	if err != nil {
+		return nil, err
+	}
+
+	var threads []*conversationStats
+	for _, threadID := range threadIDs {
+		thread, _, err := loadHistory(store, threadID)
+		if err != nil {
+			return nil, err
+		}
+		if thread == nil {
+			continue
+		}
+
+		if stats := collectStats(c, thread, since); stats.Messages > 0 {
+			threads = append(threads, stats)
+		}
+	}
+
+	sort.SliceStable(threads, func(i, j int) bool {
+		return threads[i].Messages > threads[j].Messages
+	})
+	return threads, nil
+}
+
+func printStats(s *conversationStats) {
+	fmt.Printf("%s📊 %s%s %s(%d messages", colorBold, s.Title, colorReset, colorDim, s.Messages)
+	if !s.First.IsZero() {
+		fmt.Printf(", %s → %s", s.First.Local().Format(time.DateOnly), s.Last.Local().Format(time.DateOnly))
+	}
+	fmt.Printf(")%s\n", colorReset)
+
+	if s.Messages == 0 {
+		return
+	}
+
+	fmt.Printf("\n%sMessages by sender%s\n", colorBold, colorReset)
+	senders := s.Senders
+	if len(senders) > 10 {
+		senders = senders[:10]
+	}
+	width := 0
+	for _, sender := range senders {
+		width = max(width, len(sender.Username))
+	}
+	for _, sender := range senders {
+		bar := strings.Repeat("█", max(1, sender.Messages*30/s.Messages))
+		line := fmt.Sprintf("  %-*s %s%-30s%s %5d (%2d%%)", width, sender.Username, colorCyan, bar, colorReset,
+			sender.Messages, sender.Messages*100/s.Messages)
+		if sender.Responses > 0 {
+			line += fmt.Sprintf("  %smedian reply %s%s", colorDim, formatElapsed(time.Duration(sender.MedianResponse*float64(time.Second))), colorReset)
+		}
+		fmt.Println(line)
+	}
+	if len(s.Senders) > len(senders) {
+		fmt.Printf("  %s...and %d more%s\n", colorDim, len(s.Senders)-len(senders), colorReset)
+	}
+
+	fmt.Printf("\n%sActivity by hour%s\n", colorBold, colorReset)
+	printHeatmap(s.Heatmap)
+
+	fmt.Printf("\n%sMost shared%s\n  ", colorBold, colorReset)
+	types := s.Types
+	if len(types) > 6 {
+		types = types[:6]
+	}
+	parts := make([]string, 0, len(types))
+	for _, t := range types {
+		parts = append(parts, fmt.Sprintf("%s %s%d%s", t.Type, colorDim, t.Count, colorReset))
+	}
+	fmt.Println(strings.Join(parts, " · "))
+}
+
+// heatShades go from no messages to the busiest hour
+var heatShades = []string{"··", "░░", "▒▒", "▓▓", "██"}
+
+// printHeatmap draws messages per weekday and hour, Monday first
+func printHeatmap(heatmap [7][24]int) {
+	busiest, busiestDay, busiestHour := 0, 0, 0
+	var dayTotals [7]int
+	for day := range heatmap {
+		for hour, n := range heatmap[day] {
+			dayTotals[day] += n
+			if n > busiest {
+				busiest, busiestDay, busiestHour = n, day, hour
+			}
+		}
+	}
+
+	fmt.Printf("      %s", colorDim)
+	for hour := 0; hour < 24; hour += 3 {
+		fmt.Printf("%-6d", hour)
+	}
+	fmt.Printf("%s\n", colorReset)
+
+	for i := 0; i < 7; i++ {
+		day := (i + 1) % 7
+		fmt.Printf("  %s ", time.Weekday(day).String()[:3])
+		for _, n := range heatmap[day] {
+			level := 0
+			if n > 0 {
+				level = 1 + n*(len(heatShades)-2)/busiest
+			}
+			color := colorMagenta
+			if level == 0 {
+				color = colorDim
+			}
+			fmt.Printf("%s%s%s", color, heatShades[level], colorReset)
+		}
+		fmt.Printf(" %s%d%s\n", colorDim, dayTotals[day], colorReset)
+	}
+
+	if busiest > 0 {
+		busiestTotal, topDay := 0, 0
+		for day, n := range dayTotals {
+			if n > busiestTotal {
+				busiestTotal, topDay = n, day
+			}
+		}
+		fmt.Printf("  %sBusiest hour: %s %02d:00-%02d:00 · busiest day: %s%s\n", colorDim,
+			time.Weekday(busiestDay).String()[:3], busiestHour, (busiestHour+1)%24, time.Weekday(topDay), colorReset)
+	}
+}
+
+func printThreadTable(threads []*conversationStats) {
+	fmt.Printf("\n%sBy conversation%s\n", colorBold, colorReset)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "  THREAD\tMESSAGES\tYOURS\tYOUR MEDIAN REPLY\tTHEIR MEDIAN REPLY")
+	for _, s := range threads {
+		mine := 0
+		for _, sender := range s.Senders {
+			if sender.FromMe {
+				mine += sender.Messages
+			}
+		}
+		fmt.Fprintf(w, "  %s\t%d\t%d\t%s\t%s\n", truncateString(s.Title, 30), s.Messages, mine,
+			formatElapsed(median(s.responseTimes(true))), formatElapsed(median(s.responseTimes(false))))
+	}
+}
+
+// formatElapsed renders a duration at a readable precision, e.g. 4m or 2d 3h
+func formatElapsed(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "-"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd %dh", int(d.Hours())/24, int(d.Hours())%24)
+	}
+}
@@ -0,0 +1,367 @@
+package messages
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/storage"
+)
+
+const templatesFile = "templates.json"
+
+var templatesCommand = &cli.Command{
+	Name:    "templates",
+	Aliases: []string{"template", "tpl"},
+	Usage:   "Manage saved replies for /t in chats",
+	Commands: []*cli.Command{
+		{
+			Name:    "list",
+			Aliases: []string{"ls"},
+			Usage:   "List saved templates",
+			Flags:   []cli.Flag{jsonFlag()},
+			Action:  templatesListAction,
+		},
+		{
+			Name:      "add",
+			Usage:     "Save a new template (use - to read it from stdin, or leave it out to write it in $EDITOR)",
+			ArgsUsage: "<name> [text|-]",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "force",
+					Aliases: []string{"f"},
+					Usage:   "Replace a template with the same name",
+				},
+			},
+			Action: templatesAddAction,
+		},
+		{
+			Name:      "edit",
+			Usage:     "Change a template in $EDITOR, or replace it with the given text",
+			ArgsUsage: "<name> [text|-]",
+			Action:    templatesEditAction,
+		},
+		{
+			Name:      "delete",
+			Aliases:   []string{"rm"},
+			Usage:     "Delete a template",
+			ArgsUsage: "<name>",
+			Action:    templatesDeleteAction,
+		},
+	},
+	Action: templatesListAction,
+}
+
+// replyTemplates maps template names to their text
+type replyTemplates map[string]string
+
+func templatesPath() (string, error) {
+	store, err := storage.NewSessionStorage()
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize session storage: %w", err)
+	}
+	return store.ConfigPath(templatesFile), nil
+}
+
+// loadTemplates reads the templates file, empty if there is none yet
+func loadTemplates() (replyTemplates, error) {
+	path, err := templatesPath()
+	if err != nil {
+		return nil, err
+	}
+
+	templates := replyTemplates{}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return templates, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read templates: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return templates, nil
+}
+
+func saveTemplates(templates replyTemplates) error {
+	path, err := templatesPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(templates, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal templates: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write templates: %w", err)
+	}
+	return nil
+}
+
+// names returns the template names in alphabetical order
+func (t replyTemplates) names() []string {
+	names := make([]string, 0, len(t))
+	for name := range t {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookup finds a template by name, or by a prefix only it starts with
+func (t replyTemplates) lookup(name string) (string, error) {
+	name = strings.ToLower(name)
+	if text, ok := t[name]; ok {
+		return text, nil
+	}
+
+	var matches []string
+	for _, n := range t.names() {
+		if strings.HasPrefix(n, name) {
+			matches = append(matches, n)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no template %q (saved: %s)", name, strings.Join(t.names(), ", "))
+	case 1:
+		return t[matches[0]], nil
+	default:
+		return "", fmt.Errorf("%q matches %s", name, strings.Join(matches, ", "))
+	}
+}
+
+// templateName checks a name given on the command line
+func templateName(arg string) (string, error) {
+	name := strings.ToLower(strings.TrimSpace(arg))
+	if name == "" || strings.ContainsAny(name, " \t\n") {
+		return "", fmt.Errorf("template names are single words, not %q", arg)
+	}
+	return name, nil
+}
+
+// templateText reads the text argument: the text itself, - for stdin, or
+// the editor when it was left out
+func templateText(cmd *cli.Command, current string) (string, error) {
+	var text string
+	switch arg := strings.Join(cmd.Args().Tail(), " "); arg {
+	case "":
+		edited, err := editText(current)
+		if err != nil {
+			return "", err
+		}
+		text = edited
+	case "-":
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read stdin: %w", err)
+		}
+		text = strings.TrimRight(string(data), "\n")
+	default:
+		text = arg
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("template text is empty")
+	}
+	return text, nil
+}
+
+func templatesListAction(ctx context.Context, cmd *cli.Command) error {
+	templates, err := loadTemplates()
+	if err != nil {
+		return scriptError(err)
+	}
+
+	if cmd.Bool("json") {
+		return printJSON(templates)
+	}
+
+	if len(templates) == 0 {
+		fmt.Println("No templates yet. Add one with: messages templates add <name> <text>")
+		return nil
+	}
+
+	for _, name := range templates.names() {
+		fmt.Printf("%s%s%s\n", colorCyan, name, colorReset)
+		for _, line := range strings.Split(templates[name], "\n") {
+			fmt.Printf("  %s%s%s\n", colorDim, line, colorReset)
+		}
+	}
+	return nil
+}
+
+func templatesAddAction(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() < 1 {
+		return cli.Exit("usage: messages templates add <name> [text|-]", exitUsage)
+	}
+
+	name, err := templateName(cmd.Args().First())
+	if err != nil {
+		return cli.Exit(err.Error(), exitUsage)
+	}
+
+	templates, err := loadTemplates()
+	if err != nil {
+		return scriptError(err)
+	}
+
+	if _, exists := templates[name]; exists && !cmd.Bool("force") {
+		return cli.Exit(fmt.Sprintf("template %q already exists (use edit or --force)", name), exitUsage)
+	}
+
+	text, err := templateText(cmd, "")
+	if err != nil {
+		return cli.Exit(err.Error(), exitUsage)
+	}
+
+	templates[name] = text
+	if err := saveTemplates(templates); err != nil {
+		return scriptError(err)
+	}
+
+	fmt.Printf("%s✓ Saved template %s%s\n", colorGreen, name, colorReset)
+	return nil
+}
+
+func templatesEditAction(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() < 1 {
+		return cli.Exit("usage: messages templates edit <name> [text|-]", exitUsage)
+	}
+
+	name, err := templateName(cmd.Args().First())
+	if err != nil {
+		return cli.Exit(err.Error(), exitUsage)
+	}
+
+	templates, err := loadTemplates()
+	if err != nil {
+		return scriptError(err)
+	}
+
+	current, ok := templates[name]
+	if !ok {
+		return cli.Exit(fmt.Sprintf("no template %q", name), exitNotFound)
+	}
+
+	text, err := templateText(cmd, current)
+	if err != nil {
+		return cli.Exit(err.Error(), exitUsage)
+	}
+
+	if text == current {
+		fmt.Println("No changes")
+		return nil
+	}
+
+	templates[name] = text
+	if err := saveTemplates(templates); err != nil {
+		return scriptError(err)
+	}
+
+	fmt.Printf("%s✓ Updated template %s%s\n", colorGreen, name, colorReset)
+	return nil
+}
+
+func templatesDeleteAction(ctx context.Context, cmd *cli.Command) error {
+	if cmd.NArg() != 1 {
+		return cli.Exit("usage: messages templates delete <name>", exitUsage)
+	}
+
+	templates, err := loadTemplates()
+	if err != nil {
+		return scriptError(err)
+	}
+
+	name := strings.ToLower(cmd.Args().First())
+	if _, ok := templates[name]; !ok {
+		return cli.Exit(fmt.Sprintf("no template %q", name), exitNotFound)
+	}
+
+	delete(templates, name)
+	if err := saveTemplates(templates); err != nil {
+		return scriptError(err)
+	}
+
+	fmt.Printf("%s✓ Deleted template %s%s\n", colorGreen, name, colorReset)
+	return nil
+}
+
+// expandTemplate fills in the named template for conv. The other person's
+// full name is only looked up when the template uses it.
+func expandTemplate(c *instagram.Client, conv instagram.Conversation, name string) (string, error) {
+	templates, err := loadTemplates()
+	if err != nil {
+		return "", err
+	}
+
+	text, err := templates.lookup(name)
+	if err != nil {
+		return "", err
+	}
+
+	username := strings.Join(conv.Users, ", ")
+	fullName := ""
+	if !conv.IsGroup && len(conv.Users) == 1 && (strings.Contains(text, "full_name") || strings.Contains(text, "first_name")) {
+		if user, err := c.GetUserByUsername(conv.Users[0]); err == nil {
+			fullName = user.FullName
+		}
+	}
+
+	return expandPlaceholders(text, replyVars(username, fullName, conv.Title)), nil
+}
+
+// sendTemplate is /t: it fills in a saved template and shows it before
+// sending, or lists them without a name
+func (v *chatView) sendTemplate(args string) error {
+	if args == "" {
+		templates, err := loadTemplates()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("\n%sTemplates:%s\n", colorBold, colorReset)
+		if len(templates) == 0 {
+			fmt.Printf("  %sNone yet, add one with: messages templates add <name> <text>%s\n", colorDim, colorReset)
+		}
+		for _, name := range templates.names() {
+			fmt.Printf("  %s%-12s%s %s%s%s\n", colorCyan, name, colorReset, colorDim, truncateString(singleLine(templates[name]), 60), colorReset)
+		}
+
+		fmt.Printf("\n%sPress Enter to continue%s", colorDim, colorReset)
+		v.reader.ReadString('\n')
+		return nil
+	}
+
+	text, err := expandTemplate(v.c, v.conv, args)
+	if err != nil {
+		return err
+	}
+
+	// Placeholders can come out empty when a lookup fails, so the text is
+	// checked before it goes out
+	fmt.Printf("\n%sTo %s:%s\n\n%s\n\n", colorBold, v.conv.Title, colorReset, text)
+	fmt.Printf("Send it? [Y]es, [e]dit, [n]o: ")
+
+	answer, _ := v.reader.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "", "y", "yes":
+		v.sendText(text, instagram.SendOptions{}, nil)
+	case "e", "edit":
+		return v.editFrom(text)
+	}
+	return nil
+}
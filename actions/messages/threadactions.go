@@ -0,0 +1,274 @@
+package messages
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/storage"
+)
+
+// threadAction changes a conversation's state on Instagram. update applies
+// the same change to a listed conversation; it is nil for actions that take
+// the thread out of the inbox.
+type threadAction struct {
+	name    string
+	usage   string
+	done    string
+	confirm bool
+	run     func(c *instagram.Client, store *storage.Storage, threadID string) error
+	update  func(conv *instagram.Conversation)
+}
+
+var threadActions = []*threadAction{
+	{
+		name:  "mute",
+		usage: "Turn off notifications for a conversation",
+		done:  "Muted",
+		run: func(c *instagram.Client, _ *storage.Storage, threadID string) error {
+			return c.MuteThread(threadID, true)
+		},
+		update: func(conv *instagram.Conversation) { conv.IsMuted = true },
+	},
+	{
+		name:  "unmute",
+		usage: "Turn notifications for a conversation back on",
+		done:  "Unmuted",
+		run: func(c *instagram.Client, _ *storage.Storage, threadID string) error {
+			return c.MuteThread(threadID, false)
+		},
+		update: func(conv *instagram.Conversation) { conv.IsMuted = false },
+	},
+	{
+		name:  "pin",
+		usage: "Pin a conversation to the top of the inbox",
+		done:  "Pinned",
+		run: func(c *instagram.Client, _ *storage.Storage, threadID string) error {
+			return c.PinThread(threadID, true)
+		},
+		update: func(conv *instagram.Conversation) { conv.IsPinned = true },
+	},
+	{
+		name:  "unpin",
+		usage: "Unpin a conversation",
+		done:  "Unpinned",
+		run: func(c *instagram.Client, _ *storage.Storage, threadID string) error {
+			return c.PinThread(threadID, false)
+		},
+		update: func(conv *instagram.Conversation) { conv.IsPinned = false },
+	},
+	{
+		name:  "hide",
+		usage: "Archive a conversation: hide it from the inbox until someone writes again",
+		done:  "Archived",
+		run: func(c *instagram.Client, _ *storage.Storage, threadID string) error {
+			return c.HideThread(threadID)
+		},
+	},
+	{
+		name:    "delete",
+		usage:   "Delete a conversation for you, along with its synced history, draft and queued messages",
+		done:    "Deleted",
+		confirm: true,
+		run:     deleteThread,
+	},
+}
+
+// threadActionCommands returns a subcommand for every thread action
+func threadActionCommands() []*cli.Command {
+	commands := make([]*cli.Command, 0, len(threadActions))
+	for _, action := range threadActions {
+		cmd := &cli.Command{
+			Name:      action.name,
+			Usage:     action.usage,
+			ArgsUsage: "<thread|@user>",
+			Action:    threadActionAction(action),
+		}
+		if action.confirm {
+			cmd.Flags = []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "yes",
+					Aliases: []string{"y"},
+					Usage:   "Skip the confirmation prompt",
+				},
+			}
+		}
+		commands = append(commands, cmd)
+	}
+	return commands
+}
+
+func findThreadAction(name string) *threadAction {
+	for _, action := range threadActions {
+		if action.name == name {
+			return action
+		}
+	}
+	return nil
+}
+
+// threadActionFor maps an inbox key or action name to an action. M and p
+// toggle, so the same key undoes them.
+func threadActionFor(key string, conv instagram.Conversation) *threadAction {
+	switch key {
+	case "M":
+		if conv.IsMuted {
+			return findThreadAction("unmute")
+		}
+		return findThreadAction("mute")
+	case "p":
+		if conv.IsPinned {
+			return findThreadAction("unpin")
+		}
+		return findThreadAction("pin")
+	case "a", "archive":
+		return findThreadAction("hide")
+	case "D":
+		return findThreadAction("delete")
+	}
+	return findThreadAction(key)
+}
+
+// deleteThread hides the thread and forgets everything kept about it
+// locally. Instagram has no way to delete a conversation for the other
+// members, so this is as far as deleting goes.
+func deleteThread(c *instagram.Client, store *storage.Storage, threadID string) error {
+	if err := c.HideThread(threadID); err != nil {
+		return err
+	}
+
+	if err := store.DeleteHistory(threadID); err != nil {
+		return err
+	}
+
+	if err := store.SaveDraft(threadID, ""); err != nil {
+		return err
+	}
+
+	return store.UpdateOutbox(func(outbox *storage.Outbox) {
+		kept := outbox.Messages[:0]
+		for _, msg := range outbox.Messages {
+			if msg.ThreadID != threadID {
+				kept = append(kept, msg)
+			}
+		}
+		outbox.Messages = kept
+	})
+}
+
+// question asks to confirm the action, e.g. "Delete alice? [y/N]: "
+func (a *threadAction) question(title string) string {
+	return fmt.Sprintf("%s %s? [y/N]: ", strings.ToUpper(a.name[:1])+a.name[1:], title)
+}
+
+// updateList returns a copy of conversations with the action applied to
+// threadID, leaving the original untouched so it can be restored
+func (a *threadAction) updateList(conversations []instagram.Conversation, threadID string) []instagram.Conversation {
+	out := make([]instagram.Conversation, 0, len(conversations))
+	for _, conv := range conversations {
+		if conv.ThreadID == threadID {
+			if a.update == nil {
+				continue
+			}
+			a.update(&conv)
+		}
+		out = append(out, conv)
+	}
+	return out
+}
+
+// apply updates the cached inbox ahead of the request and returns a function
+// that puts it back if the request fails
+func (cc *conversationCache) apply(a *threadAction, threadID string) (undo func()) {
+	previous := cc.conversations
+	cc.conversations = a.updateList(previous, threadID)
+	return func() {
+		cc.conversations = previous
+	}
+}
+
+func threadActionAction(action *threadAction) cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		if cmd.NArg() != 1 {
+			return cli.Exit(fmt.Sprintf("usage: messages %s <thread|@user>", action.name), exitUsage)
+		}
+
+		c, store, err := loadClient(cmd)
+		if err != nil {
+			return scriptError(err)
+		}
+
+		conv, err := resolveThread(c, cmd.Args().First())
+		if err != nil {
+			return scriptError(err)
+		}
+
+		if action.confirm && !cmd.Bool("yes") {
+			fmt.Print(action.question(conv.Title))
+			answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			if !isYes(answer) {
+				fmt.Println("Cancelled")
+				return nil
+			}
+		}
+
+		if err := action.run(c, store, conv.ThreadID); err != nil {
+			return scriptError(fmt.Errorf("failed to %s %s: %w", action.name, conv.Title, err))
+		}
+
+		fmt.Printf("%s✓ %s %s%s\n", colorGreen, action.done, conv.Title, colorReset)
+		return nil
+	}
+}
+
+// cutThreadAction recognizes an action typed at the line-mode inbox prompt,
+// like "M 3" or "pin 3"
+func cutThreadAction(input string) (key string, num string, ok bool) {
+	fields := strings.Fields(input)
+	if len(fields) != 2 {
+		return "", "", false
+	}
+
+	switch fields[0] {
+	case "M", "p", "a", "D", "archive":
+		return fields[0], fields[1], true
+	}
+	if findThreadAction(strings.ToLower(fields[0])) != nil {
+		return strings.ToLower(fields[0]), fields[1], true
+	}
+	return "", "", false
+}
+
+// runInboxAction applies an action to the numbered conversation of the
+// line-mode inbox, updating the cache first
+func runInboxAction(c *instagram.Client, store *storage.Storage, reader *bufio.Reader, visible []instagram.Conversation, key string, arg string) (string, error) {
+	num, err := strconv.Atoi(arg)
+	if err != nil || num < 1 || num > len(visible) {
+		return "", fmt.Errorf("invalid selection %q, enter a number 1-%d", arg, len(visible))
+	}
+
+	conv := visible[num-1]
+	action := threadActionFor(key, conv)
+
+	if action.confirm {
+		fmt.Print(action.question(conv.Title))
+		answer, _ := reader.ReadString('\n')
+		if !isYes(answer) {
+			return "", nil
+		}
+	}
+
+	undo := cache.apply(action, conv.ThreadID)
+	if err := action.run(c, store, conv.ThreadID); err != nil {
+		undo()
+		return "", fmt.Errorf("failed to %s %s: %w", action.name, conv.Title, err)
+	}
+
+	return action.done + " " + conv.Title, nil
+}
@@ -0,0 +1,110 @@
+package messages
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+)
+
+var errThreadNotFound = errors.New("no conversation")
+
+// resolveThread turns a command-line argument into a conversation. It accepts
+// an inbox number as shown by the interactive mode, a thread ID, or @username
+// for the 1:1 conversation with that user.
+func resolveThread(c *instagram.Client, arg string) (instagram.Conversation, error) {
+	list, err := c.GetConversations()
+	if err != nil {
+		return instagram.Conversation{}, fmt.Errorf("failed to fetch inbox: %w", err)
+	}
+
+	if username, ok := strings.CutPrefix(arg, "@"); ok {
+		return findConversationWith(list.Conversations, username)
+	}
+
+	if num, err := strconv.Atoi(arg); err == nil && num >= 1 && num <= len(list.Conversations) {
+		return list.Conversations[num-1], nil
+	}
+
+	for _, conv := range list.Conversations {
+		if conv.ThreadID == arg {
+			return conv, nil
+		}
+	}
+
+	// Older threads aren't in the first inbox page but are still addressable
+	if isThreadID(arg) {
+		return instagram.Conversation{ThreadID: arg, Title: arg}, nil
+	}
+
+	return instagram.Conversation{}, fmt.Errorf("%w matching %q", errThreadNotFound, arg)
+}
+
+// findConversationWith prefers the 1:1 thread with username and falls back
+// to the first group that includes them
+func findConversationWith(conversations []instagram.Conversation, username string) (instagram.Conversation, error) {
+	var group *instagram.Conversation
+
+	for i, conv := range conversations {
+		for _, u := range conv.Users {
+			if !strings.EqualFold(u, username) {
+				continue
+			}
+			if len(conv.Users) == 1 {
+				return conv, nil
+			}
+			if group == nil {
+				group = &conversations[i]
+			}
+		}
+	}
+
+	if group != nil {
+		return *group, nil
+	}
+
+	return instagram.Conversation{}, fmt.Errorf("%w with @%s", errThreadNotFound, username)
+}
+
+func isThreadID(s string) bool {
+	if len(s) < 15 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// startConversation returns the 1:1 conversation with @username, creating
+// the thread if the two of you have never talked
+func startConversation(c *instagram.Client, username string) (instagram.Conversation, error) {
+	username = strings.TrimPrefix(strings.TrimSpace(username), "@")
+
+	if list, err := c.GetConversations(); err == nil {
+		for _, conv := range list.Conversations {
+			if len(conv.Users) == 1 && strings.EqualFold(conv.Users[0], username) {
+				return conv, nil
+			}
+		}
+	}
+
+	user, err := c.GetUserByUsername(username)
+	if err != nil {
+		return instagram.Conversation{}, err
+	}
+
+	thread, err := c.GetOrCreateDirectThread(user.Pk.String())
+	if err != nil {
+		return instagram.Conversation{}, fmt.Errorf("failed to open a conversation with @%s: %w", user.Username, err)
+	}
+
+	if len(thread.Users) == 0 {
+		thread.Users = []instagram.ThreadUser{*user}
+	}
+	return instagram.ThreadToConversation(*thread), nil
+}
@@ -0,0 +1,530 @@
+package messages
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/storage"
+)
+
+// Triage decisions
+const (
+	decisionApprove = "approve"
+	decisionDecline = "decline"
+	decisionIgnore  = "ignore"
+)
+
+var triageCommand = &cli.Command{
+	Name:  "triage",
+	Usage: "Approve or decline pending requests in bulk by filters, in paced batches after a summary",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "decline-links",
+			Usage: "Decline requests whose messages contain a link",
+		},
+		&cli.IntFlag{
+			Name:  "min-followers",
+			Usage: "Decline requests from accounts with fewer followers (looks up every sender)",
+		},
+		&cli.BoolFlag{
+			Name:  "decline-no-picture",
+			Usage: "Decline requests from accounts without a profile picture",
+		},
+		&cli.StringSliceFlag{
+			Name:  "decline-keywords",
+			Usage: "Decline requests mentioning any of these words (@file reads one per line)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "approve-keywords",
+			Usage: "Approve requests mentioning any of these words (@file reads one per line)",
+		},
+		&cli.StringFlag{
+			Name:  "otherwise",
+			Value: decisionIgnore,
+			Usage: "What to do with requests no filter decides: ignore, approve or decline",
+		},
+		&cli.IntFlag{
+			Name:  "limit",
+			Usage: "Most requests to triage, oldest last (0 for all)",
+		},
+		&cli.IntFlag{
+			Name:  "batch",
+			Value: 10,
+			Usage: "Requests to approve or decline between pauses",
+		},
+		&cli.DurationFlag{
+			Name:  "pause",
+			Value: 30 * time.Second,
+			Usage: "Wait between batches",
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Only print the summary",
+		},
+		&cli.BoolFlag{
+			Name:    "yes",
+			Aliases: []string{"y"},
+			Usage:   "Skip the confirmation prompt",
+		},
+	},
+	Commands: []*cli.Command{
+		{
+			Name:  "log",
+			Usage: "Print past triage decisions",
+			Flags: []cli.Flag{
+				jsonFlag(),
+				&cli.IntFlag{
+					Name:    "limit",
+					Aliases: []string{"n"},
+					Value:   50,
+					Usage:   "Number of entries to print (0 for all)",
+				},
+			},
+			Action: triageLogAction,
+		},
+	},
+	Action: triageAction,
+}
+
+// triageFilters decide what happens to each request. Decline filters are
+// checked first; a request that also mentions an approve keyword is left
+// alone for a person to look at.
+type triageFilters struct {
+	links        bool
+	minFollowers int
+	noPicture    bool
+	declineWords []string
+	approveWords []string
+	otherwise    string
+}
+
+// triageRequest is a pending request and what triage decided for it
+type triageRequest struct {
+	conv     instagram.Conversation
+	sender   instagram.ThreadUser
+	text     string
+	hasLink  bool
+	decision string
+	reasons  []string
+}
+
+func triageFiltersFromFlags(cmd *cli.Command) (triageFilters, error) {
+	f := triageFilters{
+		links:        cmd.Bool("decline-links"),
+		minFollowers: cmd.Int("min-followers"),
+		noPicture:    cmd.Bool("decline-no-picture"),
+		otherwise:    strings.ToLower(cmd.String("otherwise")),
+	}
+
+	switch f.otherwise {
+	case decisionIgnore, decisionApprove, decisionDecline:
+	default:
+		return f, fmt.Errorf("--otherwise must be ignore, approve or decline, not %q", f.otherwise)
+	}
+
+	var err error
+	if f.declineWords, err = keywordList(cmd.StringSlice("decline-keywords")); err != nil {
+		return f, err
+	}
+	if f.approveWords, err = keywordList(cmd.StringSlice("approve-keywords")); err != nil {
+		return f, err
+	}
+
+	if !f.links && f.minFollowers <= 0 && !f.noPicture && len(f.declineWords) == 0 && len(f.approveWords) == 0 && f.otherwise == decisionIgnore {
+		return f, fmt.Errorf("no filters given, so every request would be ignored")
+	}
+	return f, nil
+}
+
+// keywordList lowercases keywords, reading @file arguments one word or
+// phrase per line and skipping blank lines and # comments
+func keywordList(values []string) ([]string, error) {
+	var words []string
+	for _, v := range values {
+		path, ok := strings.CutPrefix(v, "@")
+		if !ok {
+			if v = strings.TrimSpace(v); v != "" {
+				words = append(words, strings.ToLower(v))
+			}
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read keywords: %w", err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" && !strings.HasPrefix(line, "#") {
+				words = append(words, strings.ToLower(line))
+			}
+		}
+	}
+	return words, nil
+}
+
+// matchKeyword returns the first keyword text contains
+func matchKeyword(text string, words []string) string {
+	text = strings.ToLower(text)
+	for _, w := range words {
+		if strings.Contains(text, w) {
+			return w
+		}
+	}
+	return ""
+}
+
+// fetchRequests loads pending requests with their latest messages, up to
+// limit when it's above 0
+func fetchRequests(c *instagram.Client, limit int) ([]*triageRequest, error) {
+	var (
+		requests []*triageRequest
+		cursor   string
+	)
+
+	for {
+		inbox, err := c.GetPendingInbox(cursor, 50)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch message requests: %w", err)
+		}
+
+		for i := range inbox.Inbox.Threads {
+			thread := &inbox.Inbox.Threads[i]
+			if len(thread.Users) == 0 {
+				continue
+			}
+
+			r := &triageRequest{
+				conv:   instagram.ThreadToConversation(*thread),
+				sender: thread.Users[0],
+			}
+			if thread.Inviter != nil {
+				r.sender = *thread.Inviter
+			}
+
+			messages, _ := c.ThreadMessages(thread)
+			var texts []string
+			for _, msg := range messages {
+				if msg.IsFromMe {
+					continue
+				}
+				if msg.Type == "link" {
+					r.hasLink = true
+				}
+				texts = append(texts, msg.Text)
+			}
+			r.text = strings.Join(texts, "\n")
+			r.hasLink = r.hasLink || instagram.ContainsLink(r.text)
+
+			requests = append(requests, r)
+			if limit > 0 && len(requests) >= limit {
+				return requests, nil
+			}
+		}
+
+		if !inbox.Inbox.HasOlder || inbox.Inbox.OldestCursor == "" || inbox.Inbox.OldestCursor == cursor {
+			return requests, nil
+		}
+		cursor = inbox.Inbox.OldestCursor
+
+		// Stay well under the request rate Instagram tolerates
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// decide applies the filters to r. Follower counts cost a profile lookup, so
+// they're only checked when nothing cheaper declined the request already;
+// decide reports whether it made one.
+func (f triageFilters) decide(c *instagram.Client, r *triageRequest) (lookedUp bool) {
+	var declines []string
+
+	if f.links && r.hasLink {
+		declines = append(declines, "link")
+	}
+	if f.noPicture && r.sender.HasAnonymousPicture {
+		declines = append(declines, "no picture")
+	}
+	if word := matchKeyword(r.text, f.declineWords); word != "" {
+		declines = append(declines, fmt.Sprintf("says %q", word))
+	}
+
+	if f.minFollowers > 0 && len(declines) == 0 {
+		lookedUp = true
+		user, err := c.GetUserByUsername(r.sender.Username)
+		if err != nil {
+			r.decision = decisionIgnore
+			r.reasons = []string{"lookup failed"}
+			return lookedUp
+		}
+		r.sender.FollowerCount = user.FollowerCount
+		if user.FollowerCount < f.minFollowers {
+			declines = append(declines, fmt.Sprintf("%d followers", user.FollowerCount))
+		}
+	}
+
+	approve := matchKeyword(r.text, f.approveWords)
+
+	switch {
+	case len(declines) > 0 && approve != "":
+		r.decision = decisionIgnore
+		r.reasons = append(declines, fmt.Sprintf("but says %q", approve))
+	case len(declines) > 0:
+		r.decision = decisionDecline
+		r.reasons = declines
+	case approve != "":
+		r.decision = decisionApprove
+		r.reasons = []string{fmt.Sprintf("says %q", approve)}
+	default:
+		r.decision = f.otherwise
+		r.reasons = []string{"no filter matched"}
+	}
+	return lookedUp
+}
+
+func triageAction(ctx context.Context, cmd *cli.Command) error {
+	filters, err := triageFiltersFromFlags(cmd)
+	if err != nil {
+		return cli.Exit(err.Error(), exitUsage)
+	}
+
+	batch, pause := cmd.Int("batch"), cmd.Duration("pause")
+	if batch < 1 {
+		return cli.Exit("batch must be at least 1", exitUsage)
+	}
+
+	c, store, err := loadClient(cmd)
+	if err != nil {
+		return scriptError(err)
+	}
+
+	fmt.Printf("%s⏳ Fetching message requests...%s\n", colorDim, colorReset)
+	requests, err := fetchRequests(c, cmd.Int("limit"))
+	if err != nil {
+		return scriptError(err)
+	}
+
+	if len(requests) == 0 {
+		fmt.Printf("%s📭 No pending message requests.%s\n", colorDim, colorReset)
+		return nil
+	}
+
+	for i, r := range requests {
+		if filters.minFollowers > 0 {
+			fmt.Printf("\r%s🔎 Checking %d/%d%s", colorDim, i+1, len(requests), colorReset)
+		}
+		if filters.decide(c, r) && i < len(requests)-1 {
+			time.Sleep(500 * time.Millisecond)
+		}
+	}
+	if filters.minFollowers > 0 {
+		fmt.Print("\r\033[K")
+	}
+
+	actionable := printTriageSummary(requests)
+
+	if cmd.Bool("dry-run") {
+		return nil
+	}
+
+	if len(actionable) == 0 {
+		fmt.Println("Nothing to approve or decline")
+		return nil
+	}
+
+	if !cmd.Bool("yes") {
+		fmt.Printf("\nApply %d decisions in batches of %d? [y/N]: ", len(actionable), batch)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if !isYes(answer) {
+			fmt.Println("Cancelled")
+			return nil
+		}
+	}
+
+	// Ignored requests are only logged once the run goes ahead
+	ignored, err := newlyIgnored(store, requests)
+	if err != nil {
+		return scriptError(err)
+	}
+	if err := store.AppendTriageLog(ignored...); err != nil {
+		return scriptError(fmt.Errorf("failed to save triage log: %w", err))
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	failed, err := runTriage(ctx, c, store, actionable, batch, pause)
+	if err != nil {
+		return scriptError(err)
+	}
+	if failed > 0 {
+		return cli.Exit(fmt.Sprintf("%d request(s) failed", failed), exitFailure)
+	}
+	return nil
+}
+
+// runTriage approves and declines requests, pausing after every batch and
+// logging each batch as it completes
+func runTriage(ctx context.Context, c *instagram.Client, store *storage.Storage, requests []*triageRequest, batch int, pause time.Duration) (failed int, err error) {
+	for start := 0; start < len(requests); start += batch {
+		if start > 0 {
+			fmt.Printf("%s⏸ Waiting %s before the next batch (%d left)%s\n", colorDim, pause, len(requests)-start, colorReset)
+			select {
+			case <-ctx.Done():
+				fmt.Println("Stopped")
+				return failed, nil
+			case <-time.After(pause):
+			}
+		}
+
+		end := min(start+batch, len(requests))
+		entries := make([]storage.TriageEntry, 0, end-start)
+
+		for _, r := range requests[start:end] {
+			var err error
+			if r.decision == decisionApprove {
+				err = c.ApproveThread(r.conv.ThreadID)
+			} else {
+				err = c.DeclineThread(r.conv.ThreadID)
+			}
+
+			entries = append(entries, triageEntry(r, err))
+			if err != nil {
+				failed++
+				fmt.Printf("%s✗ %s @%s: %v%s\n", colorRed, r.decision, r.sender.Username, err, colorReset)
+				continue
+			}
+
+			if r.decision == decisionApprove {
+				fmt.Printf("%s✓ Approved @%s%s\n", colorGreen, r.sender.Username, colorReset)
+			} else {
+				fmt.Printf("%s✓ Declined @%s%s\n", colorGreen, r.sender.Username, colorReset)
+			}
+		}
+
+		if err := store.AppendTriageLog(entries...); err != nil {
+			return failed, fmt.Errorf("failed to save triage log: %w", err)
+		}
+	}
+
+	return failed, nil
+}
+
+// newlyIgnored returns log entries for the ignored requests. A request
+// stays pending when ignored, so one whose last entry is already an ignore
+// isn't logged again on every run.
+func newlyIgnored(store *storage.Storage, requests []*triageRequest) ([]storage.TriageEntry, error) {
+	log, err := store.LoadTriageLog()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load triage log: %w", err)
+	}
+
+	last := make(map[string]string, len(log.Entries))
+	for _, e := range log.Entries {
+		last[e.ThreadID] = e.Decision
+	}
+
+	var ignored []storage.TriageEntry
+	for _, r := range requests {
+		if r.decision == decisionIgnore && last[r.conv.ThreadID] != decisionIgnore {
+			ignored = append(ignored, triageEntry(r, nil))
+		}
+	}
+	return ignored, nil
+}
+
+func triageEntry(r *triageRequest, err error) storage.TriageEntry {
+	entry := storage.TriageEntry{
+		ThreadID:  r.conv.ThreadID,
+		Sender:    r.sender.Username,
+		Decision:  r.decision,
+		Reasons:   r.reasons,
+		Timestamp: time.Now(),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	return entry
+}
+
+// printTriageSummary lists every decision and returns the requests to
+// approve or decline, approvals first
+func printTriageSummary(requests []*triageRequest) []*triageRequest {
+	counts := make(map[string]int)
+	var approve, decline []*triageRequest
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tFROM\tDECISION\tWHY\tMESSAGE")
+	for i, r := range requests {
+		counts[r.decision]++
+		switch r.decision {
+		case decisionApprove:
+			approve = append(approve, r)
+		case decisionDecline:
+			decline = append(decline, r)
+		}
+
+		fmt.Fprintf(w, "%d\t@%s\t%s\t%s\t%s\n",
+			i+1, r.sender.Username, r.decision, strings.Join(r.reasons, ", "), truncateString(singleLine(r.text), 40))
+	}
+	w.Flush()
+
+	fmt.Printf("\n%s📨 %d requests:%s %s%d to approve%s · %s%d to decline%s · %s%d to ignore%s\n",
+		colorBold, len(requests), colorReset,
+		colorGreen, counts[decisionApprove], colorReset,
+		colorRed, counts[decisionDecline], colorReset,
+		colorDim, counts[decisionIgnore], colorReset)
+
+	return append(approve, decline...)
+}
+
+func triageLogAction(ctx context.Context, cmd *cli.Command) error {
+	store, err := storage.NewSessionStorage()
+	if err != nil {
+		return scriptError(fmt.Errorf("failed to initialize session storage: %w", err))
+	}
+
+	log, err := store.LoadTriageLog()
+	if err != nil {
+		return scriptError(fmt.Errorf("failed to load triage log: %w", err))
+	}
+
+	entries := log.Entries
+	if limit := cmd.Int("limit"); limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+
+	if cmd.Bool("json") {
+		if entries == nil {
+			entries = []storage.TriageEntry{}
+		}
+		return printJSON(entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No requests were triaged yet")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "TIME\tFROM\tDECISION\tWHY")
+	for _, e := range entries {
+		decision := e.Decision
+		if e.Error != "" {
+			decision += " (failed: " + truncateString(e.Error, 40) + ")"
+		}
+		fmt.Fprintf(w, "%s\t@%s\t%s\t%s\n", e.Timestamp.Format(time.DateTime), e.Sender, decision, strings.Join(e.Reasons, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,1372 @@
+package messages
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/storage"
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/tui"
+)
+
+const (
+	tuiPageSize      = 30
+	tuiMaxInputLines = 6
+	tuiStatusTimeout = 5 * time.Second
+)
+
+type tuiFocus int
+
+const (
+	focusList tuiFocus = iota
+	focusInput
+)
+
+// tuiApp is the full-screen interface. All state is owned by the loop in run;
+// network calls happen in goroutines that post their results back through
+// results.
+type tuiApp struct {
+	ctx    context.Context
+	c      *instagram.Client
+	store  *storage.Storage
+	screen *tui.Screen
+	live   bool
+
+	// all is the loaded part of the folder and conversations the part of it
+	// the filter shows
+	all             []instagram.Conversation
+	conversations   []instagram.Conversation
+	pendingRequests int
+	selected        int
+	listTop         int
+	loadingList     bool
+	cursor          string
+	hasOlder        bool
+	loadingMore     bool
+
+	chat  *tuiChat
+	input tui.Input
+	focus tuiFocus
+	help  bool
+
+	// drafts are the unsent compose box contents by thread ID, saved when
+	// leaving a chat
+	drafts map[string]storage.Draft
+
+	status    string
+	statusErr bool
+	statusAt  time.Time
+
+	stdin   chan []byte
+	keys    tui.Decoder
+	results chan func()
+	events  <-chan instagram.Event
+	quit    bool
+}
+
+// tuiChat is the open conversation
+type tuiChat struct {
+	view         *chatView
+	cursor       string
+	hasOlder     bool
+	loaded       bool
+	loadingOlder bool
+	scroll       int
+	lastSeenID   string
+
+	// typingSent is when our typing indicator was last sent, zero when off
+	typingSent time.Time
+}
+
+// runTUI runs the full-screen inbox. When initial is set that conversation
+// opens right away.
+func runTUI(ctx context.Context, c *instagram.Client, store *storage.Storage, opts instagram.StreamOptions, initial *instagram.Conversation) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	screen, err := tui.Open()
+	if err != nil {
+		return err
+	}
+	defer screen.Close()
+
+	app := &tuiApp{
+		ctx:     ctx,
+		c:       c,
+		store:   store,
+		screen:  screen,
+		live:    opts.RealtimeAddr != "",
+		stdin:   make(chan []byte, 16),
+		results: make(chan func(), 64),
+		drafts:  loadDrafts(),
+	}
+
+	opts.OnError = func(err error, retryIn time.Duration) {
+		app.post(func() {
+			app.setError(fmt.Errorf("%v (retrying in %s)", err, retryIn.Round(time.Second)))
+		})
+	}
+	app.events = c.StreamEvents(ctx, opts)
+
+	if cache.conversations != nil && cache.folder == inboxView.folder {
+		app.all = cache.conversations
+		app.pendingRequests = cache.pendingRequests
+		app.cursor = cache.cursor
+		app.hasOlder = cache.hasOlder
+		app.refilter()
+	}
+	app.loadConversations()
+
+	if initial != nil {
+		app.openChat(*initial)
+	}
+
+	go screen.ReadInput(app.stdin)
+
+	err = app.run()
+	app.keepDraft()
+	return err
+}
+
+func (a *tuiApp) run() error {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for !a.quit {
+		a.draw()
+
+		select {
+		case chunk, ok := <-a.stdin:
+			if !ok {
+				return nil
+			}
+			for _, key := range a.keys.Feed(chunk) {
+				a.handleKey(key)
+			}
+		case fn := <-a.results:
+			fn()
+		case e, ok := <-a.events:
+			if !ok {
+				a.events = nil
+				continue
+			}
+			a.handleEvent(e)
+		case <-ticker.C:
+			// Picks up resizes and expires typing indicators and statuses
+		}
+	}
+
+	return nil
+}
+
+// post hands fn to the loop from a background goroutine
+func (a *tuiApp) post(fn func()) {
+	select {
+	case a.results <- fn:
+	case <-a.ctx.Done():
+	}
+}
+
+func (a *tuiApp) setStatus(format string, args ...any) {
+	a.status = fmt.Sprintf(format, args...)
+	a.statusErr = false
+	a.statusAt = time.Now()
+}
+
+func (a *tuiApp) setError(err error) {
+	a.status = err.Error()
+	a.statusErr = true
+	a.statusAt = time.Now()
+}
+
+func (a *tuiApp) loadConversations() {
+	if a.loadingList {
+		return
+	}
+	a.loadingList = true
+
+	folder, pages := inboxView.folder, inboxView.pages
+	go func() {
+		list, err := fetchInbox(a.c, folder, pages)
+		a.post(func() {
+			a.loadingList = false
+			if folder != inboxView.folder {
+				// Switched folders while this one loaded
+				a.loadConversations()
+				return
+			}
+			if err != nil {
+				a.setError(fmt.Errorf("failed to fetch inbox: %w", err))
+				return
+			}
+
+			a.setConversations(list)
+		})
+	}()
+}
+
+// loadMore fetches the next page of the folder
+func (a *tuiApp) loadMore() {
+	if !a.hasOlder || a.loadingMore || a.loadingList {
+		return
+	}
+	a.loadingMore = true
+
+	folder := inboxView.folder
+	list := &instagram.ConversationList{
+		Conversations: a.all,
+		OldestCursor:  a.cursor,
+		HasOlder:      a.hasOlder,
+	}
+
+	go func() {
+		err := fetchMoreConversations(a.c, folder, list)
+		a.post(func() {
+			a.loadingMore = false
+			if folder != inboxView.folder {
+				return
+			}
+			if err != nil {
+				a.setError(fmt.Errorf("failed to load more conversations: %w", err))
+				return
+			}
+
+			a.all = list.Conversations
+			a.cursor = list.OldestCursor
+			a.hasOlder = list.HasOlder
+			cache.conversations = a.all
+			cache.cursor = a.cursor
+			cache.hasOlder = a.hasOlder
+			a.refilter()
+		})
+	}()
+}
+
+// setConversations replaces the inbox while keeping the same thread selected
+func (a *tuiApp) setConversations(list *instagram.ConversationList) {
+	a.all = list.Conversations
+	a.pendingRequests = list.PendingRequests
+	a.cursor = list.OldestCursor
+	a.hasOlder = list.HasOlder
+
+	cache.store(inboxView.folder, list)
+
+	a.refilter()
+
+	// The open thread is read, whatever the inbox says
+	if a.chat != nil {
+		a.clearUnread(a.chat.view.conv.ThreadID)
+	}
+}
+
+// refilter applies the inbox filter again, keeping the same thread selected
+func (a *tuiApp) refilter() {
+	var selectedID string
+	if a.selected < len(a.conversations) {
+		selectedID = a.conversations[a.selected].ThreadID
+	}
+
+	a.conversations = inboxView.filter.apply(a.all)
+
+	a.selected = 0
+	for i, conv := range a.conversations {
+		if conv.ThreadID == selectedID {
+			a.selected = i
+			break
+		}
+	}
+}
+
+// switchFolder shows another inbox folder
+func (a *tuiApp) switchFolder(folder string) {
+	if folder == inboxView.folder {
+		return
+	}
+
+	inboxView.folder = folder
+	a.all, a.conversations = nil, nil
+	a.selected, a.listTop = 0, 0
+	a.cursor, a.hasOlder = "", false
+	a.loadConversations()
+}
+
+func (a *tuiApp) clearUnread(threadID string) {
+	for _, list := range [][]instagram.Conversation{a.all, a.conversations} {
+		for i := range list {
+			if list[i].ThreadID == threadID {
+				list[i].UnreadCount = 0
+			}
+		}
+	}
+}
+
+func (a *tuiApp) openChat(conv instagram.Conversation) {
+	a.keepDraft()
+
+	a.chat = &tuiChat{view: &chatView{c: a.c, conv: conv}}
+	a.focus = focusInput
+	a.input.Set(a.drafts[conv.ThreadID].Text)
+	a.clearUnread(conv.ThreadID)
+	a.loadLatest()
+}
+
+// keepDraft saves what's left in the compose box of the open chat, or drops
+// the saved draft once the box is empty
+func (a *tuiApp) keepDraft() {
+	if a.chat == nil {
+		return
+	}
+
+	threadID := a.chat.view.conv.ThreadID
+	text := a.input.String()
+	if text == a.drafts[threadID].Text {
+		return
+	}
+
+	if strings.TrimSpace(text) == "" {
+		delete(a.drafts, threadID)
+	} else {
+		a.drafts[threadID] = storage.Draft{Text: text, UpdatedAt: time.Now()}
+	}
+
+	if err := saveDraft(threadID, text); err != nil {
+		a.setError(err)
+	}
+}
+
+// startChat opens the 1:1 conversation with username, creating the thread
+// when there is none yet
+func (a *tuiApp) startChat(username string) {
+	username = strings.TrimPrefix(strings.TrimSpace(username), "@")
+	if username == "" {
+		a.setError(fmt.Errorf("usage: /new @username"))
+		return
+	}
+
+	a.setStatus("⏳ Opening @%s...", username)
+	go func() {
+		conv, err := startConversation(a.c, username)
+		a.post(func() {
+			if err != nil {
+				a.setError(err)
+				return
+			}
+
+			found := false
+			for i, c := range a.conversations {
+				if c.ThreadID == conv.ThreadID {
+					a.selected = i
+					found = true
+					break
+				}
+			}
+			if !found {
+				a.all = append([]instagram.Conversation{conv}, a.all...)
+				a.conversations = append([]instagram.Conversation{conv}, a.conversations...)
+				a.selected = 0
+			}
+
+			a.setStatus("")
+			a.openChat(conv)
+		})
+	}()
+}
+
+// loadLatest fetches the newest page of the open thread and merges it in
+func (a *tuiApp) loadLatest() {
+	chat := a.chat
+	if chat == nil {
+		return
+	}
+
+	go func() {
+		threadResp, err := a.c.GetThread(chat.view.conv.ThreadID, "", tuiPageSize)
+		a.post(func() {
+			if a.chat != chat {
+				return
+			}
+			if err != nil {
+				a.setError(fmt.Errorf("failed to fetch messages: %w", err))
+				return
+			}
+
+			messages, _ := a.c.ThreadMessages(&threadResp.Thread)
+			chat.view.setMessages(mergeLatest(chat.view.messages, messages))
+			chat.view.updateSeen(a.c.SeenReceipts(&threadResp.Thread))
+
+			if !chat.loaded {
+				chat.loaded = true
+				chat.cursor = threadResp.Thread.OldestCursor
+				chat.hasOlder = threadResp.Thread.HasOlder
+			}
+
+			a.markSeen(chat)
+		})
+	}()
+}
+
+// loadOlder fetches the page before the oldest loaded message
+func (a *tuiApp) loadOlder() {
+	chat := a.chat
+	if chat == nil || !chat.loaded || !chat.hasOlder || chat.loadingOlder || chat.cursor == "" {
+		return
+	}
+	chat.loadingOlder = true
+
+	go func() {
+		threadResp, err := a.c.GetThread(chat.view.conv.ThreadID, chat.cursor, tuiPageSize)
+		a.post(func() {
+			chat.loadingOlder = false
+			if err != nil {
+				a.setError(fmt.Errorf("failed to fetch older messages: %w", err))
+				return
+			}
+
+			older, _ := a.c.ThreadMessages(&threadResp.Thread)
+			chat.view.setMessages(mergeOlder(chat.view.messages, older))
+			chat.cursor = threadResp.Thread.OldestCursor
+			chat.hasOlder = threadResp.Thread.HasOlder && chat.cursor != ""
+		})
+	}()
+}
+
+func (a *tuiApp) markSeen(chat *tuiChat) {
+	if chat.view.conv.IsPending {
+		return
+	}
+
+	messages := append([]instagram.Message(nil), chat.view.messages...)
+	lastSeenID := chat.lastSeenID
+
+	go func() {
+		seenID := markLatestSeen(a.c, chat.view.conv.ThreadID, messages, lastSeenID)
+		a.post(func() { chat.lastSeenID = seenID })
+	}()
+}
+
+// mergeLatest replaces everything from the oldest message of the newest page
+// onwards, so unsent messages disappear while loaded history stays
+func mergeLatest(existing []instagram.Message, latest []instagram.Message) []instagram.Message {
+	if len(latest) == 0 {
+		return existing
+	}
+
+	oldest := latest[0].Timestamp
+	for _, m := range latest {
+		if m.Timestamp.Before(oldest) {
+			oldest = m.Timestamp
+		}
+	}
+
+	merged := make([]instagram.Message, 0, len(existing)+len(latest))
+	for _, m := range existing {
+		if m.Timestamp.Before(oldest) {
+			merged = append(merged, m)
+		}
+	}
+	return append(merged, latest...)
+}
+
+func mergeOlder(existing []instagram.Message, older []instagram.Message) []instagram.Message {
+	known := make(map[string]bool, len(existing))
+	for _, m := range existing {
+		known[m.ID] = true
+	}
+
+	merged := make([]instagram.Message, 0, len(existing)+len(older))
+	for _, m := range older {
+		if !known[m.ID] {
+			merged = append(merged, m)
+		}
+	}
+	return append(merged, existing...)
+}
+
+func (a *tuiApp) handleEvent(e instagram.Event) {
+	if a.chat != nil {
+		if _, refetch := a.chat.view.handleEvent(e); refetch {
+			a.loadLatest()
+		}
+	}
+
+	switch e.Type {
+	case instagram.EventMessage, instagram.EventRequest:
+		if !e.FromMe && (a.chat == nil || e.ThreadID != a.chat.view.conv.ThreadID) {
+			a.setStatus("💬 %s: %s", e.SenderName, singleLine(e.Text))
+		}
+		a.loadConversations()
+	case instagram.EventReaction:
+		a.loadConversations()
+	}
+}
+
+func (a *tuiApp) handleKey(key tui.Key) {
+	if a.help {
+		a.help = false
+		return
+	}
+
+	switch {
+	case key.Type == tui.KeyCtrl && (key.Rune == 'c' || key.Rune == 'q'):
+		a.quit = true
+		return
+	case key.Type == tui.KeyCtrl && key.Rune == 'r':
+		a.setStatus("🔄 Refreshing...")
+		a.loadConversations()
+		a.loadLatest()
+		return
+	}
+
+	if a.focus == focusInput && a.chat != nil {
+		a.handleInputKey(key)
+	} else {
+		a.handleListKey(key)
+	}
+}
+
+func (a *tuiApp) handleListKey(key tui.Key) {
+	_, height := a.screen.Size()
+	page := max(1, (height-4)/2)
+
+	switch key.Type {
+	case tui.KeyUp:
+		a.moveSelection(-1)
+	case tui.KeyDown:
+		a.moveSelection(1)
+	case tui.KeyPgUp:
+		a.moveSelection(-page)
+	case tui.KeyPgDn:
+		a.moveSelection(page)
+	case tui.KeyHome:
+		a.selected = 0
+	case tui.KeyEnd:
+		a.selected = max(0, len(a.conversations)-1)
+		a.loadMore()
+	case tui.KeyEnter, tui.KeyRight:
+		if a.selected < len(a.conversations) {
+			a.openChat(a.conversations[a.selected])
+		}
+	case tui.KeyTab, tui.KeyShiftTab:
+		if a.chat != nil {
+			a.focus = focusInput
+		}
+	case tui.KeyRune:
+		switch key.Rune {
+		case 'k':
+			a.moveSelection(-1)
+		case 'j':
+			a.moveSelection(1)
+		case 'l':
+			if a.selected < len(a.conversations) {
+				a.openChat(a.conversations[a.selected])
+			}
+		case 'r':
+			a.setStatus("🔄 Refreshing...")
+			a.loadConversations()
+		case 'f':
+			var terms string
+			a.runSuspended(func(reader *bufio.Reader) error {
+				fmt.Printf("%sFilter%s (unread, muted, pinned, group, direct, @user, all): ", colorBold, colorReset)
+				terms, _ = reader.ReadString('\n')
+				return nil
+			}, false)
+			a.applyFilter(strings.Fields(terms)...)
+		case 'u':
+			a.applyFilter("unread")
+		case 's':
+			a.applyFilter("sort:" + nextOf(inboxSorts, inboxView.filter.sort))
+		case 'm':
+			a.loadMore()
+		case '1', '2', '3', '4':
+			a.switchFolder(instagram.InboxFolders[key.Rune-'1'])
+		case 'M', 'p', 'a', 'D':
+			a.threadAction(string(key.Rune))
+		case 'n':
+			var username string
+			a.runSuspended(func(reader *bufio.Reader) error {
+				fmt.Printf("%sStart a conversation with:%s @", colorBold, colorReset)
+				username, _ = reader.ReadString('\n')
+				return nil
+			}, false)
+			a.startChat(username)
+		case '?':
+			a.help = true
+		case 'q':
+			a.quit = true
+		}
+	}
+}
+
+func (a *tuiApp) moveSelection(delta int) {
+	a.selected = min(max(a.selected+delta, 0), max(0, len(a.conversations)-1))
+	if a.selected >= len(a.conversations)-1 {
+		a.loadMore()
+	}
+}
+
+// threadAction applies the action bound to key to the selected conversation.
+// The list changes right away and is fetched again if the request fails.
+func (a *tuiApp) threadAction(key string) {
+	if a.selected >= len(a.conversations) {
+		return
+	}
+	conv := a.conversations[a.selected]
+	action := threadActionFor(key, conv)
+
+	if action.confirm {
+		var answer string
+		a.runSuspended(func(reader *bufio.Reader) error {
+			fmt.Print(action.question(conv.Title))
+			answer, _ = reader.ReadString('\n')
+			return nil
+		}, false)
+		if !isYes(answer) {
+			return
+		}
+	}
+
+	selected := a.selected
+	a.all = action.updateList(a.all, conv.ThreadID)
+	cache.conversations = a.all
+	a.refilter()
+
+	if action.update == nil {
+		a.selected = min(selected, max(0, len(a.conversations)-1))
+		if a.chat != nil && a.chat.view.conv.ThreadID == conv.ThreadID {
+			a.keepDraft()
+			a.chat = nil
+			a.focus = focusList
+			a.input.Clear()
+		}
+	}
+
+	go func() {
+		err := action.run(a.c, a.store, conv.ThreadID)
+		a.post(func() {
+			if err != nil {
+				a.setError(fmt.Errorf("failed to %s %s: %w", action.name, conv.Title, err))
+				a.loadConversations()
+				return
+			}
+
+			// Deleting drops the draft along with the thread
+			if action.name == "delete" {
+				delete(a.drafts, conv.ThreadID)
+			}
+			a.setStatus("✓ %s %s", action.done, conv.Title)
+		})
+	}()
+}
+
+// applyFilter changes the inbox filter by the given terms
+func (a *tuiApp) applyFilter(terms ...string) {
+	for _, term := range terms {
+		if err := inboxView.filter.set(term); err != nil {
+			a.setError(err)
+			return
+		}
+	}
+
+	a.refilter()
+	a.setStatus("📂 %s", inboxView.describe())
+}
+
+// nextOf returns the item after current in list, wrapping around
+func nextOf(list []string, current string) string {
+	for i, item := range list {
+		if item == current {
+			return list[(i+1)%len(list)]
+		}
+	}
+	return list[0]
+}
+
+func (a *tuiApp) handleInputKey(key tui.Key) {
+	chat := a.chat
+
+	switch key.Type {
+	case tui.KeyRune:
+		a.input.Insert(string(key.Rune))
+		a.indicateTyping()
+	case tui.KeyPaste:
+		a.input.Insert(key.Text)
+		a.indicateTyping()
+	case tui.KeyNewline:
+		a.input.Insert("\n")
+	case tui.KeyEnter:
+		a.submit()
+	case tui.KeyBackspace:
+		a.input.Backspace()
+	case tui.KeyDelete:
+		a.input.Delete()
+	case tui.KeyLeft:
+		a.input.Left()
+	case tui.KeyRight:
+		a.input.Right()
+	case tui.KeyHome:
+		a.input.Home()
+	case tui.KeyEnd:
+		a.input.End()
+	case tui.KeyUp:
+		if !a.input.Up() {
+			chat.scroll++
+		}
+	case tui.KeyDown:
+		if !a.input.Down() {
+			chat.scroll = max(0, chat.scroll-1)
+		}
+	case tui.KeyPgUp:
+		chat.scroll += a.messageRows() - 2
+	case tui.KeyPgDn:
+		chat.scroll = max(0, chat.scroll-(a.messageRows()-2))
+	case tui.KeyEsc, tui.KeyTab, tui.KeyShiftTab:
+		a.focus = focusList
+	case tui.KeyCtrl:
+		switch key.Rune {
+		case 'a':
+			a.input.Home()
+		case 'e':
+			a.input.End()
+		case 'u':
+			a.input.DeleteToStart()
+		}
+	}
+}
+
+func (a *tuiApp) submit() {
+	text := strings.TrimSpace(a.input.String())
+	if text == "" {
+		return
+	}
+	a.input.Clear()
+	a.keepDraft()
+
+	chat := a.chat
+	v := chat.view
+	chat.scroll = 0
+	a.stopTyping()
+
+	if strings.HasPrefix(text, "/") {
+		name, _, _ := strings.Cut(strings.TrimPrefix(text, "/"), " ")
+		switch strings.ToLower(name) {
+		case "help":
+			a.help = true
+			return
+		case "new":
+			_, username, _ := strings.Cut(text, " ")
+			a.startChat(username)
+			return
+		case "edit":
+			a.editInEditor(strings.TrimSpace(strings.TrimPrefix(text, "/"+name)))
+			return
+		case "t":
+			// Templates land in the compose box so they can be adjusted
+			// before sending
+			if _, name, _ := strings.Cut(text, " "); strings.TrimSpace(name) != "" {
+				expanded, err := expandTemplate(a.c, v.conv, strings.TrimSpace(name))
+				if err != nil {
+					a.setError(err)
+					return
+				}
+				a.input.Insert(expanded)
+				return
+			}
+		}
+
+		cmd := chatCommands[strings.ToLower(name)]
+		a.runSuspended(func(*bufio.Reader) error { return v.runCommand(text) }, !cmd.pauses)
+		a.loadLatest()
+		return
+	}
+
+	clientContext := instagram.NewClientContext()
+	v.queueOutgoing(instagram.Message{Text: text, ClientContext: clientContext})
+
+	go func() {
+		resp, err := a.c.SendTextMessage(v.conv.ThreadID, text, instagram.SendOptions{ClientContext: clientContext})
+		a.post(func() {
+			if instagram.IsNetworkError(err) {
+				if msg := v.outgoingMessage(clientContext); msg != nil && v.queueOffline(msg, instagram.SendOptions{}) {
+					a.setStatus("📤 Offline, queued for 'messages flush'")
+					return
+				}
+			}
+
+			v.settleOutgoing(clientContext, resp, err)
+			if err != nil {
+				a.setError(fmt.Errorf("failed to send: %w", err))
+				return
+			}
+			if a.chat == chat {
+				a.loadLatest()
+			}
+		})
+	}()
+}
+
+// indicateTyping tells the thread we're composing, renewing the indicator
+// every few seconds while keys keep coming. Commands don't count.
+func (a *tuiApp) indicateTyping() {
+	chat := a.chat
+	if !sendReceipts || chat == nil || chat.view.conv.IsPending || strings.HasPrefix(a.input.String(), "/") {
+		return
+	}
+	if time.Since(chat.typingSent) < typingInterval {
+		return
+	}
+
+	chat.typingSent = time.Now()
+	go a.sendTyping(chat.view.conv.ThreadID, true)
+}
+
+// stopTyping clears our indicator once the message is sent
+func (a *tuiApp) stopTyping() {
+	chat := a.chat
+	if chat == nil || chat.typingSent.IsZero() {
+		return
+	}
+
+	chat.typingSent = time.Time{}
+	go a.sendTyping(chat.view.conv.ThreadID, false)
+}
+
+func (a *tuiApp) sendTyping(threadID string, typing bool) {
+	if err := a.c.IndicateTyping(threadID, typing); err != nil && a.c.Debug {
+		a.post(func() { a.setError(fmt.Errorf("failed to send typing indicator: %w", err)) })
+	}
+}
+
+// editInEditor is /edit: it writes the message in $EDITOR and puts the result
+// in the compose box, kept as the thread's draft until it's sent
+func (a *tuiApp) editInEditor(initial string) {
+	// The editor reads the terminal itself, so the key reader has to let go
+	if err := a.screen.PauseInput(); err != nil {
+		a.input.Set(initial)
+		a.setStatus("✎ Write here, alt+⏎ starts a new line ($EDITOR unavailable: %v)", err)
+		return
+	}
+
+	var edited string
+	a.runSuspended(func(*bufio.Reader) error {
+		var err error
+		stopTyping := typingWhileEditing(a.c, a.chat.view.conv)
+		edited, err = editText(initial)
+		stopTyping()
+		a.screen.ResumeInput()
+		return err
+	}, false)
+
+	if strings.TrimSpace(edited) == "" {
+		a.setStatus("✎ Nothing written")
+		return
+	}
+
+	a.input.Set(edited)
+	a.keepDraft()
+	a.setStatus("✎ Saved as a draft, ⏎ sends it")
+}
+
+// runSuspended leaves the full-screen view to run fn with the line-mode
+// output, feeding it keyboard input through reader until it returns. With
+// pause set it waits for Enter before going back.
+func (a *tuiApp) runSuspended(fn func(reader *bufio.Reader) error, pause bool) {
+	a.screen.Suspend()
+	clearScreen()
+
+	feed := make(chan []byte, 64)
+	reader := bufio.NewReader(&chanReader{ch: feed})
+	if a.chat != nil {
+		a.chat.view.reader = reader
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		err := fn(reader)
+		if err != nil {
+			fmt.Printf("%s✗ %v%s\n", colorRed, err, colorReset)
+		}
+		if pause || err != nil {
+			fmt.Printf("\n%sPress Enter to return%s", colorDim, colorReset)
+			reader.ReadString('\n')
+		}
+	}()
+
+	for waiting := true; waiting; {
+		select {
+		case chunk, ok := <-a.stdin:
+			if !ok {
+				close(feed)
+				<-done
+				waiting = false
+				a.quit = true
+				break
+			}
+			select {
+			case feed <- chunk:
+			default:
+			}
+		case <-done:
+			waiting = false
+		}
+	}
+
+	if err := a.screen.Resume(); err != nil {
+		a.quit = true
+	}
+}
+
+// chanReader adapts the input channel to an io.Reader
+type chanReader struct {
+	ch   chan []byte
+	rest []byte
+}
+
+func (r *chanReader) Read(p []byte) (int, error) {
+	if len(r.rest) == 0 {
+		chunk, ok := <-r.ch
+		if !ok {
+			return 0, io.EOF
+		}
+		r.rest = chunk
+	}
+
+	n := copy(p, r.rest)
+	r.rest = r.rest[n:]
+	return n, nil
+}
+
+// Layout
+
+func (a *tuiApp) layout() (width, height, listWidth int) {
+	width, height = a.screen.Size()
+
+	switch {
+	case a.chat == nil:
+		listWidth = width
+		if width >= 70 {
+			listWidth = min(36, width/3)
+		}
+	case width >= 70:
+		listWidth = min(36, width/3)
+	case a.focus == focusList:
+		listWidth = width
+	}
+
+	return width, height, listWidth
+}
+
+func (a *tuiApp) inputLines(width int) ([]string, int, int) {
+	lines, row, col := a.input.Layout(max(1, width-3))
+
+	// Keep the cursor's line in view when the message is taller than the box
+	start := 0
+	if len(lines) > tuiMaxInputLines {
+		start = min(max(0, row-tuiMaxInputLines+1), len(lines)-tuiMaxInputLines)
+		lines = lines[start : start+tuiMaxInputLines]
+	}
+
+	return lines, row - start, col
+}
+
+// messageRows is the height of the message area
+func (a *tuiApp) messageRows() int {
+	width, height, listWidth := a.layout()
+	chatWidth := width - listWidth - 1
+	if listWidth == 0 {
+		chatWidth = width
+	}
+
+	lines, _, _ := a.inputLines(chatWidth)
+
+	// title, status bar, chat header, typing line, input separator
+	return max(1, height-5-len(lines))
+}
+
+// Drawing
+
+func (a *tuiApp) draw() {
+	width, height, listWidth := a.layout()
+	body := height - 2
+
+	frame := make([]string, 0, height)
+	frame = append(frame, a.titleBar(width))
+
+	var left, right []string
+	cursorRow, cursorCol := -1, 0
+
+	chatWidth := width
+	if listWidth > 0 {
+		left = a.listPane(listWidth, body)
+		chatWidth = width - listWidth - 1
+	}
+
+	if chatWidth > 0 && listWidth < width {
+		var row, col int
+		right, row, col = a.chatPane(chatWidth, body)
+		if row >= 0 && a.focus == focusInput {
+			cursorRow = row + 1
+			cursorCol = col
+			if listWidth > 0 {
+				cursorCol += listWidth + 1
+			}
+		}
+	}
+
+	for i := 0; i < body; i++ {
+		switch {
+		case left != nil && right != nil:
+			frame = append(frame, left[i]+colorDim+"│"+colorReset+right[i])
+		case left != nil:
+			frame = append(frame, left[i])
+		default:
+			frame = append(frame, right[i])
+		}
+	}
+
+	frame = append(frame, a.statusBar(width))
+
+	if a.help {
+		frame = a.overlayHelp(frame, width)
+		cursorRow = -1
+	}
+
+	a.screen.Draw(frame, cursorRow, cursorCol)
+}
+
+func (a *tuiApp) titleBar(width int) string {
+	left := " 💬 Instagram Direct"
+	if a.c.Username != "" {
+		left += " · @" + a.c.Username
+	}
+
+	mode := "polling"
+	if a.live {
+		mode = "● realtime"
+	}
+	if a.loadingList {
+		mode = "⏳ " + mode
+	}
+
+	gap := max(1, width-tui.Width(left)-tui.Width(mode)-1)
+	return colorBold + colorBgBlue + tui.Pad(left+strings.Repeat(" ", gap)+mode+" ", width) + colorReset
+}
+
+func (a *tuiApp) statusBar(width int) string {
+	if a.status != "" && time.Since(a.statusAt) < tuiStatusTimeout {
+		color := colorGreen
+		if a.statusErr {
+			color = colorRed
+		}
+		return color + tui.Pad(" "+a.status, width) + colorReset
+	}
+
+	hints := " ↑↓ select · ⏎ open · n new chat · f filter · 1-4 folder · tab compose · ? help · q quit"
+	if a.focus == focusInput && a.chat != nil {
+		hints = " ⏎ send · alt+⏎ newline · pgup/pgdn scroll · esc chats · /help · ctrl+c quit"
+	}
+	return colorDim + tui.Pad(hints, width) + colorReset
+}
+
+func (a *tuiApp) listPane(width int, height int) []string {
+	lines := make([]string, 0, height)
+
+	count := fmt.Sprint(len(a.conversations))
+	if a.hasOlder {
+		count += "+"
+	}
+	header := fmt.Sprintf(" %s (%s)", inboxView.describe(), count)
+	if a.loadingMore {
+		header += " ⏳"
+	}
+	if a.pendingRequests > 0 {
+		header += fmt.Sprintf(" · 📨 %d", a.pendingRequests)
+	}
+	lines = append(lines, colorBold+tui.Pad(header, width)+colorReset)
+
+	// Two lines per conversation: title and time, then the preview
+	visible := max(1, (height-1)/2)
+	if a.selected < a.listTop {
+		a.listTop = a.selected
+	}
+	if a.selected >= a.listTop+visible {
+		a.listTop = a.selected - visible + 1
+	}
+
+	if len(a.conversations) == 0 {
+		text := "📭 No conversations"
+		if len(a.all) > 0 {
+			text = "📭 Nothing matches the filter (f to change)"
+		}
+		if a.loadingList {
+			text = "⏳ Loading..."
+		}
+		lines = append(lines, colorDim+tui.Pad(" "+text, width)+colorReset)
+	}
+
+	for i := a.listTop; i < len(a.conversations) && len(lines)+2 <= height; i++ {
+		conv := a.conversations[i]
+
+		marker := "  "
+		if conv.UnreadCount > 0 {
+			marker = colorGreen + "● " + colorReset
+		}
+
+		when := formatTimeAgo(conv.LastMessageAt)
+		badges := ""
+		if _, ok := a.drafts[conv.ThreadID]; ok {
+			badges += "✎"
+		}
+		if conv.IsPinned {
+			badges += "📌"
+		}
+		if conv.IsMuted {
+			badges += "🔇"
+		}
+		if conv.UnreadCount > 0 {
+			badges += fmt.Sprintf("(%d)", conv.UnreadCount)
+		}
+
+		right := strings.TrimSpace(badges + " " + when)
+		titleWidth := max(1, width-4-tui.Width(right))
+
+		titleStyle, previewStyle := "", colorDim
+		if conv.UnreadCount > 0 {
+			titleStyle, previewStyle = colorBold, colorWhite
+		}
+
+		selected := i == a.selected
+		if selected {
+			style := "\033[7m"
+			if a.focus != focusList {
+				style = colorBgGray
+			}
+			titleStyle += style
+			previewStyle += style
+		}
+
+		title := tui.Pad(conv.Title, titleWidth) + " " + right + " "
+		lines = append(lines, marker+titleStyle+tui.Pad(title, width-2)+colorReset)
+
+		preview := conv.LastMessage
+		if preview == "" {
+			preview = "[No messages]"
+		}
+		lines = append(lines, "  "+previewStyle+tui.Pad(singleLine(preview), width-2)+colorReset)
+	}
+
+	for len(lines) < height {
+		lines = append(lines, strings.Repeat(" ", width))
+	}
+	return lines[:height]
+}
+
+// chatPane renders the open conversation and returns the input cursor
+// position within it
+func (a *tuiApp) chatPane(width int, height int) ([]string, int, int) {
+	lines := make([]string, 0, height)
+	blank := strings.Repeat(" ", width)
+
+	if a.chat == nil {
+		for len(lines) < height {
+			lines = append(lines, blank)
+		}
+		hint := "Select a conversation and press Enter"
+		lines[height/2] = colorDim + tui.Pad(strings.Repeat(" ", max(0, (width-tui.Width(hint))/2))+hint, width) + colorReset
+		return lines, -1, 0
+	}
+
+	chat := a.chat
+	v := chat.view
+
+	header := " " + v.conv.Title
+	if members := memberSummary(v.conv); members != "" {
+		header += " · " + members
+	}
+	lines = append(lines, colorBold+colorMagenta+tui.Pad(header, width)+colorReset)
+
+	input, cursorRow, cursorCol := a.inputLines(width)
+	rows := max(1, height-3-len(input))
+
+	// Messages, bottom-anchored, scrolled up by chat.scroll lines
+	content := a.messageLines(width)
+	maxScroll := max(0, len(content)-rows)
+	if chat.scroll >= maxScroll {
+		chat.scroll = maxScroll
+		a.loadOlder()
+	}
+
+	start := max(0, len(content)-rows-chat.scroll)
+	end := min(len(content), start+rows)
+
+	var top string
+	switch {
+	case chat.loadingOlder:
+		top = "⏳ Loading older messages..."
+	case chat.loaded && !chat.hasOlder && start == 0:
+		top = "· start of conversation ·"
+	case !chat.loaded:
+		top = "⏳ Loading..."
+	}
+
+	view := content[start:end]
+	pad := rows - len(view)
+	for i := 0; i < pad; i++ {
+		if i == 0 && top != "" {
+			lines = append(lines, colorDim+tui.Pad(strings.Repeat(" ", max(0, (width-tui.Width(top))/2))+top, width)+colorReset)
+			continue
+		}
+		lines = append(lines, blank)
+	}
+	lines = append(lines, view...)
+
+	status := ""
+	if chat.scroll > 0 {
+		status = fmt.Sprintf("↓ %d more lines", chat.scroll)
+	}
+	if s := v.statusLines(); len(s) > 0 {
+		status = strings.Join(s, " · ")
+	}
+	lines = append(lines, colorDim+tui.Pad(" "+status, width)+colorReset)
+
+	lines = append(lines, colorDim+strings.Repeat("─", width)+colorReset)
+
+	inputTop := len(lines)
+	for i, line := range input {
+		prompt := "   "
+		if i == 0 {
+			prompt = colorGreen + " ➜ " + colorReset
+		}
+		lines = append(lines, prompt+tui.Pad(line, width-3))
+	}
+
+	for len(lines) < height {
+		lines = append(lines, blank)
+	}
+
+	return lines[:height], inputTop + cursorRow, cursorCol + 3
+}
+
+// messageLines lays out the whole loaded conversation at the given width
+func (a *tuiApp) messageLines(width int) []string {
+	v := a.chat.view
+	messages := v.arrange()
+
+	bubble := max(10, width*3/4)
+	var lines []string
+	var lastDate string
+
+	for i, msg := range messages {
+		if date := msg.Timestamp.Format("Mon, Jan 2 2006"); date != lastDate {
+			label := "── " + date + " ──"
+			lines = append(lines, colorDim+tui.Pad(strings.Repeat(" ", max(0, (width-tui.Width(label))/2))+label, width)+colorReset)
+			lastDate = date
+		}
+
+		meta := fmt.Sprintf("#%d %s · %s", i+1, msg.SenderName, msg.Timestamp.Format("15:04"))
+		if msg.IsFromMe {
+			meta = fmt.Sprintf("%s · %s #%d", msg.Timestamp.Format("15:04"), msg.SenderName, i+1)
+			switch msg.State {
+			case instagram.SendPending:
+				meta = "⏳ " + meta
+			case instagram.SendFailed:
+				meta = "✗ failed · " + meta
+			case instagram.SendQueued:
+				meta = "📤 " + queuedStatus(msg) + " · " + msg.SenderName + fmt.Sprintf(" #%d", i+1)
+			}
+		}
+
+		lines = append(lines, a.messageLine(msg, meta, width, colorDim))
+
+		if msg.ReplyTo != nil {
+			quote := fmt.Sprintf("↪ %s: %s", msg.ReplyTo.SenderName, singleLine(msg.ReplyTo.Text))
+			lines = append(lines, a.messageLine(msg, tui.Truncate(quote, bubble), width, colorDim))
+		}
+
+		style := colorWhite
+		if msg.IsFromMe {
+			style = colorCyan
+		}
+		for _, text := range tui.Wrap(msg.Text, bubble) {
+			lines = append(lines, a.messageLine(msg, text, width, style))
+		}
+
+		if len(msg.Reactions) > 0 {
+			reactions := make([]string, 0, len(msg.Reactions))
+			for _, r := range msg.Reactions {
+				reactions = append(reactions, r.Emoji+" "+r.SenderName)
+			}
+			lines = append(lines, a.messageLine(msg, tui.Truncate(strings.Join(reactions, " · "), bubble), width, colorYellow))
+		}
+
+		if len(msg.SeenBy) > 0 {
+			lines = append(lines, a.messageLine(msg, tui.Truncate("👁 Seen by "+strings.Join(msg.SeenBy, ", "), bubble), width, colorDim))
+		}
+
+		lines = append(lines, strings.Repeat(" ", width))
+	}
+
+	return lines
+}
+
+// messageLine aligns my messages right and everyone else's left
+func (a *tuiApp) messageLine(msg instagram.Message, text string, width int, style string) string {
+	if msg.IsFromMe {
+		return style + tui.PadLeft(text+" ", width) + colorReset
+	}
+	return style + tui.Pad(" "+text, width) + colorReset
+}
+
+func (a *tuiApp) overlayHelp(frame []string, width int) []string {
+	help := []string{
+		"Keys",
+		"  ↑/↓ j/k     Move in the chat list",
+		"  ⏎ / l       Open conversation",
+		"  n           Start a conversation by username",
+		"  f / u / s   Filter, toggle unread only, change sort order",
+		"  1 2 3 4     All, primary, general, requests folders",
+		"  m           Load more conversations",
+		"  M / p       Mute or pin the selected chat (again to undo)",
+		"  a / D       Archive or delete the selected chat",
+		"  tab / esc   Switch between list and compose box",
+		"  ⏎           Send message",
+		"  alt+⏎ ctrl+j New line",
+		"  pgup/pgdn   Scroll messages (older load automatically)",
+		"  ctrl+r      Refresh",
+		"  ctrl+c      Quit",
+		"",
+		"Chat commands",
+	}
+
+	names := make([]string, 0, len(chatCommands))
+	for name := range chatCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cmd := chatCommands[name]
+		help = append(help, fmt.Sprintf("  /%-10s %-14s %s", name, cmd.args, cmd.usage))
+	}
+	help = append(help, "", "Press any key to close")
+
+	boxWidth := min(width-4, 76)
+	top := 2
+	left := max(0, (width-boxWidth)/2)
+	pad := strings.Repeat(" ", left)
+
+	for i, line := range help {
+		row := top + i
+		if row >= len(frame)-1 {
+			break
+		}
+		frame[row] = pad + colorBgGray + colorWhite + tui.Pad(" "+line, boxWidth) + colorReset
+	}
+
+	return frame
+}
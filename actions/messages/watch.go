@@ -0,0 +1,133 @@
+package messages
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+)
+
+var watchCommand = &cli.Command{
+	Name:  "watch",
+	Usage: "Stream new messages, reactions and requests as JSON lines",
+	Flags: []cli.Flag{
+		&cli.DurationFlag{
+			Name:    "interval",
+			Aliases: []string{"i"},
+			Value:   15 * time.Second,
+			Usage:   "Time between inbox polls when realtime is off or disconnected",
+		},
+		&cli.StringFlag{
+			Name:    "hook",
+			Usage:   "Shell command to run for every event, with the event JSON on stdin",
+			Sources: cli.EnvVars("IG_WATCH_HOOK"),
+		},
+		&cli.StringSliceFlag{
+			Name:  "types",
+			Value: []string{"message", "reaction", "request"},
+			Usage: "Event types to emit (typing and seen need --realtime)",
+		},
+		&cli.BoolFlag{
+			Name:  "include-own",
+			Usage: "Also emit messages and reactions you sent",
+		},
+	},
+	Action: watchAction,
+}
+
+func watchAction(ctx context.Context, cmd *cli.Command) error {
+	types := make(map[instagram.EventType]bool)
+	for _, t := range cmd.StringSlice("types") {
+		for _, part := range strings.Split(t, ",") {
+			eventType := instagram.EventType(strings.ToLower(strings.TrimSpace(part)))
+			switch eventType {
+			case instagram.EventMessage, instagram.EventReaction, instagram.EventRequest,
+				instagram.EventTyping, instagram.EventSeen:
+				types[eventType] = true
+			default:
+				return cli.Exit(fmt.Sprintf("unknown event type %q (use message, reaction, request, typing or seen)", part), exitUsage)
+			}
+		}
+	}
+
+	interval := cmd.Duration("interval")
+	if interval < time.Second {
+		return cli.Exit("interval must be at least 1s", exitUsage)
+	}
+
+	c, _, err := loadClient(cmd)
+	if err != nil {
+		return scriptError(err)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	opts := streamOptions(cmd)
+	opts.Interval = interval
+	opts.OnError = func(err error, retryIn time.Duration) {
+		fmt.Fprintf(os.Stderr, "watch: %v (retrying in %s)\n", err, retryIn.Round(time.Second))
+	}
+
+	hook := cmd.String("hook")
+	includeOwn := cmd.Bool("include-own")
+	enc := json.NewEncoder(os.Stdout)
+
+	for event := range c.StreamEvents(ctx, opts) {
+		if !types[event.Type] || (event.FromMe && !includeOwn) {
+			continue
+		}
+
+		if err := enc.Encode(event); err != nil {
+			return scriptError(fmt.Errorf("failed to write event: %w", err))
+		}
+
+		if hook != "" {
+			if err := runHook(ctx, hook, event); err != nil {
+				fmt.Fprintf(os.Stderr, "watch: hook failed: %v\n", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// runHook runs the user's hook through the shell with the event as JSON on
+// stdin and its main fields in the environment
+func runHook(ctx context.Context, hook string, event instagram.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var hookCmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		hookCmd = exec.CommandContext(ctx, "cmd", "/C", hook)
+	} else {
+		hookCmd = exec.CommandContext(ctx, "sh", "-c", hook)
+	}
+
+	hookCmd.Stdin = bytes.NewReader(data)
+	hookCmd.Stdout = os.Stderr
+	hookCmd.Stderr = os.Stderr
+	hookCmd.Env = append(os.Environ(),
+		"IG_EVENT_TYPE="+string(event.Type),
+		"IG_THREAD_ID="+event.ThreadID,
+		"IG_THREAD_TITLE="+event.ThreadTitle,
+		"IG_SENDER="+event.SenderName,
+		"IG_TEXT="+event.Text,
+	)
+
+	return hookCmd.Run()
+}
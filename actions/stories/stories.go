@@ -7,6 +7,7 @@ import (
 	"github.com/urfave/cli/v3"
 
 	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/progress"
 	"github.com/PiotrWarzachowski/go-instagram-cli/internal/storage"
 	"github.com/PiotrWarzachowski/go-instagram-cli/providers"
 )
@@ -146,7 +147,7 @@ func postStoryAction(ctx context.Context, cmd *cli.Command) error {
 	}
 
 	// Create the UI observer
-	reporter := NewCLIReporter()
+	reporter := progress.NewCLIReporter(instagram.ProgressStory)
 
 	result, err := provider.UploadWithProgress(ctx, videoPath, reporter)
 
@@ -4,7 +4,9 @@ go 1.24.0
 
 require (
 	github.com/google/uuid v1.6.0
+	github.com/mattn/go-runewidth v0.0.19
 	github.com/urfave/cli/v3 v3.6.2
+	github.com/vbauerster/mpb/v8 v8.11.3
 	golang.org/x/sync v0.19.0
 	golang.org/x/term v0.35.0
 )
@@ -14,7 +16,5 @@ require (
 	github.com/acarl005/stripansi v0.0.0-20180116102854-5a71ef0e047d // indirect
 	github.com/clipperhouse/stringish v0.1.1 // indirect
 	github.com/clipperhouse/uax29/v2 v2.3.1 // indirect
-	github.com/mattn/go-runewidth v0.0.19 // indirect
-	github.com/vbauerster/mpb/v8 v8.11.3 // indirect
 	golang.org/x/sys v0.40.0 // indirect
 )
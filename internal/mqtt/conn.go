@@ -0,0 +1,279 @@
+// Package mqtt is a small MQTT 3.1.1 client covering what Instagram's realtime
+// edge needs: a connect with a custom payload, QoS 0/1 publishes, subscribes
+// and keepalive pings.
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ConnectOptions describes the CONNECT packet. When Payload is set it is sent
+// verbatim instead of the standard client ID / username / password fields,
+// which is how MQTToT carries its Thrift-encoded login.
+type ConnectOptions struct {
+	ProtocolName  string
+	ProtocolLevel byte
+	ClientID      string
+	Username      string
+	Password      string
+	KeepAlive     time.Duration
+	Payload       []byte
+}
+
+// Message is a PUBLISH received from the server
+type Message struct {
+	Topic   string
+	Payload []byte
+}
+
+type Conn struct {
+	conn net.Conn
+	r    *bufio.Reader
+
+	wmu    sync.Mutex
+	nextID uint16
+
+	keepAlive time.Duration
+}
+
+// Dial opens a connection to addr. tls:// (or ssl://) connects over TLS,
+// tcp:// (or mqtt://) in plain text.
+func Dial(ctx context.Context, addr string) (*Conn, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+
+	dialer := &net.Dialer{Timeout: 15 * time.Second}
+
+	var conn net.Conn
+	switch u.Scheme {
+	case "tls", "ssl":
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: u.Hostname()}}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", u.Host)
+	case "tcp", "mqtt":
+		conn, err = dialer.DialContext(ctx, "tcp", u.Host)
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q (use tls:// or tcp://)", u.Scheme)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", u.Host, err)
+	}
+
+	return NewConn(conn), nil
+}
+
+func NewConn(conn net.Conn) *Conn {
+	return &Conn{conn: conn, r: bufio.NewReader(conn)}
+}
+
+// Connect sends CONNECT and waits for the CONNACK
+func (c *Conn) Connect(opts ConnectOptions) error {
+	if opts.ProtocolName == "" {
+		opts.ProtocolName = "MQTT"
+	}
+	if opts.ProtocolLevel == 0 {
+		opts.ProtocolLevel = 4
+	}
+	c.keepAlive = opts.KeepAlive
+
+	var flags byte = 0x02 // clean session
+	if opts.Payload != nil || opts.Username != "" {
+		flags |= 0x80
+	}
+	if opts.Payload != nil || opts.Password != "" {
+		flags |= 0x40
+	}
+
+	body := appendString(nil, opts.ProtocolName)
+	body = append(body, opts.ProtocolLevel, flags)
+	body = binary.BigEndian.AppendUint16(body, uint16(opts.KeepAlive/time.Second))
+
+	if opts.Payload != nil {
+		body = append(body, opts.Payload...)
+	} else {
+		body = appendString(body, opts.ClientID)
+		if opts.Username != "" {
+			body = appendString(body, opts.Username)
+		}
+		if opts.Password != "" {
+			body = appendString(body, opts.Password)
+		}
+	}
+
+	if err := c.write(typeConnect, 0, body); err != nil {
+		return fmt.Errorf("failed to send connect: %w", err)
+	}
+
+	c.conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+	defer c.conn.SetReadDeadline(time.Time{})
+
+	p, err := readPacket(c.r)
+	if err != nil {
+		return fmt.Errorf("failed to read connack: %w", err)
+	}
+	if p.kind != typeConnack || len(p.body) < 2 {
+		return fmt.Errorf("unexpected packet type %d instead of connack", p.kind)
+	}
+	if code := p.body[1]; code != 0 {
+		return fmt.Errorf("connection refused: %s", connackReason(code))
+	}
+
+	return nil
+}
+
+func connackReason(code byte) string {
+	switch code {
+	case 1:
+		return "unacceptable protocol version"
+	case 2:
+		return "identifier rejected"
+	case 3:
+		return "server unavailable"
+	case 4:
+		return "bad username or password"
+	case 5:
+		return "not authorized"
+	default:
+		return fmt.Sprintf("code %d", code)
+	}
+}
+
+// Subscribe asks for topics at QoS 0. The SUBACK is consumed by Run.
+func (c *Conn) Subscribe(topics ...string) error {
+	body := binary.BigEndian.AppendUint16(nil, c.packetID())
+	for _, topic := range topics {
+		body = appendString(body, topic)
+		body = append(body, 0)
+	}
+
+	return c.write(typeSubscribe, 0x02, body)
+}
+
+// Publish sends payload to topic at QoS 0 or 1
+func (c *Conn) Publish(topic string, payload []byte, qos byte) error {
+	body := appendString(nil, topic)
+	if qos > 0 {
+		body = binary.BigEndian.AppendUint16(body, c.packetID())
+	}
+	body = append(body, payload...)
+
+	return c.write(typePublish, (qos&0x03)<<1, body)
+}
+
+// Run reads packets until the connection drops or ctx is cancelled, passing
+// every PUBLISH to handle and keeping the connection alive with pings
+func (c *Conn) Run(ctx context.Context, handle func(Message)) error {
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.Close()
+		case <-done:
+		}
+	}()
+
+	if c.keepAlive > 0 {
+		go c.ping(done)
+	}
+
+	for {
+		if c.keepAlive > 0 {
+			c.conn.SetReadDeadline(time.Now().Add(c.keepAlive * 3 / 2))
+		}
+
+		p, err := readPacket(c.r)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("connection lost: %w", err)
+		}
+
+		switch p.kind {
+		case typePublish:
+			msg, id, err := parsePublish(p)
+			if err != nil {
+				return err
+			}
+			if id != 0 {
+				if err := c.write(typePuback, 0, binary.BigEndian.AppendUint16(nil, id)); err != nil {
+					return err
+				}
+			}
+			handle(msg)
+		case typeDisconnect:
+			return errors.New("server closed the connection")
+		}
+	}
+}
+
+func (c *Conn) ping(done chan struct{}) {
+	ticker := time.NewTicker(c.keepAlive / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := c.write(typePingreq, 0, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func parsePublish(p *packet) (Message, uint16, error) {
+	topic, rest, err := readString(p.body)
+	if err != nil {
+		return Message{}, 0, fmt.Errorf("malformed publish: %w", err)
+	}
+
+	var id uint16
+	if qos := (p.flags >> 1) & 0x03; qos > 0 {
+		if len(rest) < 2 {
+			return Message{}, 0, errors.New("malformed publish: missing packet ID")
+		}
+		id = binary.BigEndian.Uint16(rest)
+		rest = rest[2:]
+	}
+
+	return Message{Topic: topic, Payload: rest}, id, nil
+}
+
+func (c *Conn) packetID() uint16 {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	c.nextID++
+	if c.nextID == 0 {
+		c.nextID = 1
+	}
+	return c.nextID
+}
+
+func (c *Conn) write(kind byte, flags byte, body []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	c.conn.SetWriteDeadline(time.Now().Add(15 * time.Second))
+	return writePacket(c.conn, kind, flags, body)
+}
+
+// Close sends DISCONNECT and closes the connection
+func (c *Conn) Close() error {
+	c.write(typeDisconnect, 0, nil)
+	return c.conn.Close()
+}
@@ -0,0 +1,210 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// standIn is the server end of a connection, playing the broker
+type standIn struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func newStandIn(t *testing.T, conn net.Conn) *standIn {
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	return &standIn{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+func (s *standIn) expect(kind byte) *packet {
+	p, err := readPacket(s.r)
+	if err != nil {
+		s.t.Errorf("stand-in: failed to read packet %d: %v", kind, err)
+		return nil
+	}
+	if p.kind != kind {
+		s.t.Errorf("stand-in: got packet %d, want %d", p.kind, kind)
+		return nil
+	}
+	return p
+}
+
+func (s *standIn) send(kind byte, flags byte, body []byte) {
+	if err := writePacket(s.conn, kind, flags, body); err != nil {
+		s.t.Errorf("stand-in: failed to send packet %d: %v", kind, err)
+	}
+}
+
+func TestConnectPacket(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ConnectOptions
+		want []byte
+	}{
+		{
+			name: "standard",
+			opts: ConnectOptions{ClientID: "cli", Username: "u", Password: "p", KeepAlive: 60 * time.Second},
+			want: []byte{
+				0x00, 0x04, 'M', 'Q', 'T', 'T', 0x04, 0xc2, 0x00, 0x3c,
+				0x00, 0x03, 'c', 'l', 'i',
+				0x00, 0x01, 'u',
+				0x00, 0x01, 'p',
+			},
+		},
+		{
+			name: "client ID only",
+			opts: ConnectOptions{ClientID: "cli"},
+			want: []byte{0x00, 0x04, 'M', 'Q', 'T', 'T', 0x04, 0x02, 0x00, 0x00, 0x00, 0x03, 'c', 'l', 'i'},
+		},
+		{
+			name: "custom payload",
+			opts: ConnectOptions{ProtocolName: "MQTToT", ProtocolLevel: 3, KeepAlive: 20 * time.Second, Payload: []byte{0xde, 0xad}},
+			want: []byte{0x00, 0x06, 'M', 'Q', 'T', 'T', 'o', 'T', 0x03, 0xc2, 0x00, 0x14, 0xde, 0xad},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+			defer server.Close()
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				s := newStandIn(t, server)
+				if p := s.expect(typeConnect); p != nil && !bytes.Equal(p.body, tt.want) {
+					t.Errorf("connect body\n got % x\nwant % x", p.body, tt.want)
+				}
+				s.send(typeConnack, 0, []byte{0x00, 0x00})
+			}()
+
+			if err := NewConn(client).Connect(tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			<-done
+		})
+	}
+}
+
+func TestConnectRefused(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go func() {
+		s := newStandIn(t, server)
+		s.expect(typeConnect)
+		s.send(typeConnack, 0, []byte{0x00, 0x05})
+	}()
+
+	err := NewConn(client).Connect(ConnectOptions{ClientID: "cli"})
+	if err == nil || !strings.Contains(err.Error(), "not authorized") {
+		t.Fatalf("err = %v, want a not authorized refusal", err)
+	}
+}
+
+// TestStandIn runs connect, subscribe and publish against a local broker
+// stand-in over tcp://, the way --realtime-addr is used for testing
+func TestStandIn(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	serverDone := make(chan struct{})
+	go func() {
+		defer close(serverDone)
+
+		conn, err := ln.Accept()
+		if err != nil {
+			t.Errorf("stand-in: %v", err)
+			return
+		}
+		defer conn.Close()
+		s := newStandIn(t, conn)
+
+		s.expect(typeConnect)
+		s.send(typeConnack, 0, []byte{0x00, 0x00})
+
+		sub := s.expect(typeSubscribe)
+		if sub == nil {
+			return
+		}
+		if sub.flags != 0x02 {
+			t.Errorf("subscribe flags = %#x, want 0x02", sub.flags)
+		}
+		id := sub.body[:2]
+		topic, rest, err := readString(sub.body[2:])
+		if err != nil || topic != "146" || !bytes.Equal(rest, []byte{0x00}) {
+			t.Errorf("subscribe = %q % x %v, want topic 146 at QoS 0", topic, rest, err)
+		}
+		s.send(typeSuback, 0, append(id, 0x00))
+
+		pub := s.expect(typePublish)
+		if pub != nil {
+			msg, _, err := parsePublish(pub)
+			if err != nil || msg.Topic != "134" || string(msg.Payload) != "sync" {
+				t.Errorf("client publish = %q %q %v", msg.Topic, msg.Payload, err)
+			}
+			s.send(typePuback, 0, pub.body[5:7])
+		}
+
+		body := append(appendString(nil, "146"), 0x00, 0x2a)
+		s.send(typePublish, 0x02, append(body, `{"event":"patch"}`...))
+
+		ack := s.expect(typePuback)
+		if ack != nil && binary.BigEndian.Uint16(ack.body) != 0x2a {
+			t.Errorf("puback for packet %d, want 42", binary.BigEndian.Uint16(ack.body))
+		}
+
+		s.send(typeDisconnect, 0, nil)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := Dial(ctx, "tcp://"+ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := conn.Connect(ConnectOptions{ClientID: "cli"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Subscribe("146"); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Publish("134", []byte("sync"), 1); err != nil {
+		t.Fatal(err)
+	}
+
+	var received []Message
+	err = conn.Run(ctx, func(msg Message) {
+		received = append(received, msg)
+	})
+	if err == nil || !strings.Contains(err.Error(), "server closed") {
+		t.Errorf("Run = %v, want the server's disconnect", err)
+	}
+
+	<-serverDone
+
+	if len(received) != 1 || received[0].Topic != "146" || string(received[0].Payload) != `{"event":"patch"}` {
+		t.Errorf("received %+v, want one message on 146", received)
+	}
+}
+
+func TestDialScheme(t *testing.T) {
+	if _, err := Dial(context.Background(), "http://localhost:1883"); err == nil {
+		t.Error("expected an error for an unsupported scheme")
+	}
+}
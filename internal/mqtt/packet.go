@@ -0,0 +1,114 @@
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Control packet types
+const (
+	typeConnect    byte = 1
+	typeConnack    byte = 2
+	typePublish    byte = 3
+	typePuback     byte = 4
+	typeSubscribe  byte = 8
+	typeSuback     byte = 9
+	typePingreq    byte = 12
+	typePingresp   byte = 13
+	typeDisconnect byte = 14
+)
+
+const maxRemainingLength = 268435455
+
+type packet struct {
+	kind  byte
+	flags byte
+	body  []byte
+}
+
+func writePacket(w io.Writer, kind byte, flags byte, body []byte) error {
+	if len(body) > maxRemainingLength {
+		return fmt.Errorf("packet too large: %d bytes", len(body))
+	}
+
+	header := []byte{kind<<4 | flags&0x0f}
+	header = appendRemainingLength(header, len(body))
+
+	if _, err := w.Write(append(header, body...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func readPacket(r *bufio.Reader) (*packet, error) {
+	first, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := readRemainingLength(r)
+	if err != nil {
+		return nil, err
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+
+	return &packet{kind: first >> 4, flags: first & 0x0f, body: body}, nil
+}
+
+func appendRemainingLength(b []byte, n int) []byte {
+	for {
+		digit := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			digit |= 0x80
+		}
+		b = append(b, digit)
+		if n == 0 {
+			return b
+		}
+	}
+}
+
+func readRemainingLength(r *bufio.Reader) (int, error) {
+	length, multiplier := 0, 1
+
+	for i := 0; i < 4; i++ {
+		digit, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		length += int(digit&0x7f) * multiplier
+		if digit&0x80 == 0 {
+			return length, nil
+		}
+		multiplier *= 128
+	}
+
+	return 0, errors.New("malformed remaining length")
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+func readString(b []byte) (string, []byte, error) {
+	if len(b) < 2 {
+		return "", nil, errors.New("truncated string")
+	}
+
+	n := int(binary.BigEndian.Uint16(b))
+	if len(b) < 2+n {
+		return "", nil, errors.New("truncated string")
+	}
+
+	return string(b[2 : 2+n]), b[2+n:], nil
+}
@@ -0,0 +1,151 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestRemainingLength(t *testing.T) {
+	tests := []struct {
+		n    int
+		want []byte
+	}{
+		{0, []byte{0x00}},
+		{127, []byte{0x7f}},
+		{128, []byte{0x80, 0x01}},
+		{16383, []byte{0xff, 0x7f}},
+		{16384, []byte{0x80, 0x80, 0x01}},
+		{2097151, []byte{0xff, 0xff, 0x7f}},
+		{2097152, []byte{0x80, 0x80, 0x80, 0x01}},
+		{maxRemainingLength, []byte{0xff, 0xff, 0xff, 0x7f}},
+	}
+
+	for _, tt := range tests {
+		got := appendRemainingLength(nil, tt.n)
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("appendRemainingLength(%d) = % x, want % x", tt.n, got, tt.want)
+		}
+
+		n, err := readRemainingLength(bufio.NewReader(bytes.NewReader(got)))
+		if err != nil || n != tt.n {
+			t.Errorf("readRemainingLength(% x) = %d, %v, want %d", got, n, err, tt.n)
+		}
+	}
+}
+
+func TestReadRemainingLengthMalformed(t *testing.T) {
+	r := bufio.NewReader(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff, 0x01}))
+	if _, err := readRemainingLength(r); err == nil {
+		t.Error("expected an error for a five-byte length")
+	}
+}
+
+func TestPacketRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		kind  byte
+		flags byte
+		body  []byte
+	}{
+		{"empty", typePingreq, 0, nil},
+		{"flags", typeSubscribe, 0x02, []byte{0x00, 0x01}},
+		{"long body", typePublish, 0x02, bytes.Repeat([]byte{'x'}, 300)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writePacket(&buf, tt.kind, tt.flags, tt.body); err != nil {
+				t.Fatal(err)
+			}
+
+			p, err := readPacket(bufio.NewReader(&buf))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.kind != tt.kind || p.flags != tt.flags || !bytes.Equal(p.body, tt.body) {
+				t.Errorf("got kind %d flags %#x body %d bytes, want kind %d flags %#x body %d bytes",
+					p.kind, p.flags, len(p.body), tt.kind, tt.flags, len(tt.body))
+			}
+		})
+	}
+}
+
+func TestReadString(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      []byte
+		want    string
+		rest    []byte
+		wantErr bool
+	}{
+		{"string", []byte{0x00, 0x02, 'h', 'i', 0x09}, "hi", []byte{0x09}, false},
+		{"empty", []byte{0x00, 0x00}, "", []byte{}, false},
+		{"short length", []byte{0x00}, "", nil, true},
+		{"short data", []byte{0x00, 0x03, 'h', 'i'}, "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := readString(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want || !bytes.Equal(rest, tt.rest) {
+				t.Errorf("readString(% x) = %q, % x, want %q, % x", tt.in, got, rest, tt.want, tt.rest)
+			}
+		})
+	}
+}
+
+func TestParsePublish(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   byte
+		body    []byte
+		want    Message
+		id      uint16
+		wantErr bool
+	}{
+		{
+			name:  "qos 0",
+			body:  append(appendString(nil, "146"), "payload"...),
+			want:  Message{Topic: "146", Payload: []byte("payload")},
+			flags: 0,
+		},
+		{
+			name:  "qos 1",
+			flags: 0x02,
+			body:  append(appendString(nil, "146"), 0x00, 0x07, 'p'),
+			want:  Message{Topic: "146", Payload: []byte("p")},
+			id:    7,
+		},
+		{
+			name:    "qos 1 without packet ID",
+			flags:   0x02,
+			body:    appendString(nil, "146"),
+			wantErr: true,
+		},
+		{
+			name:    "truncated topic",
+			body:    []byte{0x00, 0x05, '1'},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, id, err := parsePublish(&packet{kind: typePublish, flags: tt.flags, body: tt.body})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if msg.Topic != tt.want.Topic || !bytes.Equal(msg.Payload, tt.want.Payload) || id != tt.id {
+				t.Errorf("got %q % x id %d, want %q % x id %d", msg.Topic, msg.Payload, id, tt.want.Topic, tt.want.Payload, tt.id)
+			}
+		})
+	}
+}
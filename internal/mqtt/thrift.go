@@ -0,0 +1,171 @@
+package mqtt
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Thrift compact protocol type IDs
+const (
+	ThriftTrue   byte = 1
+	ThriftFalse  byte = 2
+	ThriftByte   byte = 3
+	ThriftI16    byte = 4
+	ThriftI32    byte = 5
+	ThriftI64    byte = 6
+	ThriftBinary byte = 8
+	ThriftList   byte = 9
+	ThriftMap    byte = 11
+	ThriftStruct byte = 12
+)
+
+// ThriftWriter encodes structs in Thrift's compact protocol, the format
+// MQTToT uses for its connect payload
+type ThriftWriter struct {
+	buf    []byte
+	last   int16
+	parent []int16
+}
+
+func (w *ThriftWriter) Bytes() []byte {
+	return w.buf
+}
+
+func (w *ThriftWriter) field(id int16, kind byte) {
+	if delta := id - w.last; delta > 0 && delta <= 15 {
+		w.buf = append(w.buf, byte(delta)<<4|kind)
+	} else {
+		w.buf = append(w.buf, kind)
+		w.buf = binary.AppendVarint(w.buf, int64(id))
+	}
+	w.last = id
+}
+
+func (w *ThriftWriter) Bool(id int16, v bool) {
+	if v {
+		w.field(id, ThriftTrue)
+	} else {
+		w.field(id, ThriftFalse)
+	}
+}
+
+func (w *ThriftWriter) Byte(id int16, v byte) {
+	w.field(id, ThriftByte)
+	w.buf = append(w.buf, v)
+}
+
+func (w *ThriftWriter) I32(id int16, v int32) {
+	w.field(id, ThriftI32)
+	w.buf = binary.AppendVarint(w.buf, int64(v))
+}
+
+func (w *ThriftWriter) I64(id int16, v int64) {
+	w.field(id, ThriftI64)
+	w.buf = binary.AppendVarint(w.buf, v)
+}
+
+func (w *ThriftWriter) String(id int16, v string) {
+	w.field(id, ThriftBinary)
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+func (w *ThriftWriter) I32List(id int16, v []int32) {
+	w.field(id, ThriftList)
+	if len(v) < 15 {
+		w.buf = append(w.buf, byte(len(v))<<4|ThriftI32)
+	} else {
+		w.buf = append(w.buf, 0xf0|ThriftI32)
+		w.buf = binary.AppendUvarint(w.buf, uint64(len(v)))
+	}
+	for _, n := range v {
+		w.buf = binary.AppendVarint(w.buf, int64(n))
+	}
+}
+
+func (w *ThriftWriter) StringMap(id int16, v map[string]string, keys []string) {
+	w.field(id, ThriftMap)
+	if len(keys) == 0 {
+		w.buf = append(w.buf, 0)
+		return
+	}
+
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(keys)))
+	w.buf = append(w.buf, ThriftBinary<<4|ThriftBinary)
+	for _, k := range keys {
+		w.buf = binary.AppendUvarint(w.buf, uint64(len(k)))
+		w.buf = append(w.buf, k...)
+		w.buf = binary.AppendUvarint(w.buf, uint64(len(v[k])))
+		w.buf = append(w.buf, v[k]...)
+	}
+}
+
+func (w *ThriftWriter) BeginStruct(id int16) {
+	w.field(id, ThriftStruct)
+	w.parent = append(w.parent, w.last)
+	w.last = 0
+}
+
+// EndStruct closes the innermost open struct, or the top-level one when none
+// is open
+func (w *ThriftWriter) EndStruct() {
+	w.buf = append(w.buf, 0)
+	if n := len(w.parent); n > 0 {
+		w.last = w.parent[n-1]
+		w.parent = w.parent[:n-1]
+	}
+}
+
+// ReadThriftStrings decodes a flat compact-protocol struct and returns its
+// string fields by ID. Integer and bool fields are skipped; anything nested
+// is rejected.
+func ReadThriftStrings(b []byte) (map[int16]string, error) {
+	fields := make(map[int16]string)
+	var last int16
+
+	for len(b) > 0 {
+		header := b[0]
+		b = b[1:]
+		if header == 0 {
+			return fields, nil
+		}
+
+		kind := header & 0x0f
+		if delta := int16(header >> 4); delta != 0 {
+			last += delta
+		} else {
+			id, n := binary.Varint(b)
+			if n <= 0 {
+				return nil, errors.New("malformed field id")
+			}
+			last = int16(id)
+			b = b[n:]
+		}
+
+		switch kind {
+		case ThriftTrue, ThriftFalse:
+		case ThriftByte:
+			if len(b) < 1 {
+				return nil, errors.New("truncated byte")
+			}
+			b = b[1:]
+		case ThriftI16, ThriftI32, ThriftI64:
+			_, n := binary.Varint(b)
+			if n <= 0 {
+				return nil, errors.New("malformed integer")
+			}
+			b = b[n:]
+		case ThriftBinary:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < size {
+				return nil, errors.New("truncated string")
+			}
+			fields[last] = string(b[n : n+int(size)])
+			b = b[n+int(size):]
+		default:
+			return nil, errors.New("unsupported field type")
+		}
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,159 @@
+package mqtt
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestThriftWriter(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(w *ThriftWriter)
+		want  []byte
+	}{
+		{
+			name:  "string",
+			write: func(w *ThriftWriter) { w.String(1, "hi") },
+			want:  []byte{0x18, 0x02, 'h', 'i'},
+		},
+		{
+			name: "field deltas",
+			write: func(w *ThriftWriter) {
+				w.String(1, "a")
+				w.I32(3, -1)
+				w.Bool(4, true)
+				w.Bool(5, false)
+			},
+			want: []byte{0x18, 0x01, 'a', 0x25, 0x01, 0x11, 0x12},
+		},
+		{
+			name: "long field id",
+			write: func(w *ThriftWriter) {
+				w.I32(3, 1)
+				w.I64(20, 300)
+			},
+			// Delta 17 doesn't fit in the header, so the id follows as a
+			// zigzag varint
+			want: []byte{0x35, 0x02, 0x06, 0x28, 0xd8, 0x04},
+		},
+		{
+			name: "backwards field id",
+			write: func(w *ThriftWriter) {
+				w.Byte(5, 7)
+				w.Byte(2, 8)
+			},
+			want: []byte{0x53, 0x07, 0x03, 0x04, 0x08},
+		},
+		{
+			name: "nested struct",
+			write: func(w *ThriftWriter) {
+				w.BeginStruct(1)
+				w.String(1, "a")
+				w.EndStruct()
+				w.String(2, "b")
+				w.EndStruct()
+			},
+			want: []byte{0x1c, 0x18, 0x01, 'a', 0x00, 0x18, 0x01, 'b', 0x00},
+		},
+		{
+			name:  "i32 list",
+			write: func(w *ThriftWriter) { w.I32List(1, []int32{1, 2}) },
+			want:  []byte{0x19, 0x25, 0x02, 0x04},
+		},
+		{
+			name:  "string map",
+			write: func(w *ThriftWriter) { w.StringMap(1, map[string]string{"k": "v"}, []string{"k"}) },
+			want:  []byte{0x1b, 0x01, 0x88, 0x01, 'k', 0x01, 'v'},
+		},
+		{
+			name:  "empty map",
+			write: func(w *ThriftWriter) { w.StringMap(1, nil, nil) },
+			want:  []byte{0x1b, 0x00},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &ThriftWriter{}
+			tt.write(w)
+			if got := w.Bytes(); !bytes.Equal(got, tt.want) {
+				t.Errorf("got % x, want % x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadThriftStrings(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      func() []byte
+		want    map[int16]string
+		wantErr bool
+	}{
+		{
+			name: "strings among other fields",
+			in: func() []byte {
+				w := &ThriftWriter{}
+				w.String(1, "topic")
+				w.I32(2, 42)
+				w.String(3, "payload")
+				w.Bool(4, true)
+				w.Byte(5, 1)
+				w.I64(6, -7)
+				w.EndStruct()
+				return w.Bytes()
+			},
+			want: map[int16]string{1: "topic", 3: "payload"},
+		},
+		{
+			name: "long field ids",
+			in: func() []byte {
+				w := &ThriftWriter{}
+				w.String(2, "a")
+				w.String(40, "b")
+				w.String(41, "c")
+				w.EndStruct()
+				return w.Bytes()
+			},
+			want: map[int16]string{2: "a", 40: "b", 41: "c"},
+		},
+		{
+			name: "no stop byte",
+			in:   func() []byte { return []byte{0x18, 0x01, 'x'} },
+			want: map[int16]string{1: "x"},
+		},
+		{
+			name:    "nested struct",
+			in:      func() []byte { return []byte{0x1c, 0x00, 0x00} },
+			wantErr: true,
+		},
+		{
+			name:    "truncated string",
+			in:      func() []byte { return []byte{0x18, 0x05, 'x'} },
+			wantErr: true,
+		},
+		{
+			name:    "truncated byte",
+			in:      func() []byte { return []byte{0x13} },
+			wantErr: true,
+		},
+		{
+			name:    "malformed field id",
+			in:      func() []byte { return []byte{0x08} },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadThriftStrings(tt.in())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package instagram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Downloads have no overall deadline since large videos take a while, but
+// give up when the CDN stops answering or stops sending
+const (
+	downloadHeaderTimeout = 30 * time.Second
+	downloadStallTimeout  = 60 * time.Second
+)
+
+var errDownloadStalled = errors.New("download stalled")
+
+// ErrMediaExpired is returned when the CDN refuses a media URL, which happens
+// once its signature expires
+var ErrMediaExpired = errors.New("media URL expired")
+
+// MediaURLExpired reports whether a signed CDN URL is past its expiry, read
+// from the oe (hex seconds) or url_expire_at_secs parameter
+func MediaURLExpired(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	query := u.Query()
+
+	var expires int64
+	if oe := query.Get("oe"); oe != "" {
+		expires, err = strconv.ParseInt(oe, 16, 64)
+	} else if secs := query.Get("url_expire_at_secs"); secs != "" {
+		expires, err = strconv.ParseInt(secs, 10, 64)
+	}
+	if err != nil || expires == 0 {
+		return false
+	}
+	return time.Now().Unix() >= expires
+}
+
+// BestImageURL returns the highest resolution image candidate
+func (m *DirectMedia) BestImageURL() string {
+	best, bestArea := "", -1
+	for _, c := range m.ImageVersions2.Candidates {
+		if area := c.Width * c.Height; area > bestArea {
+			best, bestArea = c.URL, area
+		}
+	}
+	return best
+}
+
+// ThumbnailURL returns the smallest image candidate that is still good for a
+// preview, falling back to the first frame of a carousel
+func (m *DirectMedia) ThumbnailURL() string {
+	if len(m.CarouselMedia) > 0 {
+		return m.CarouselMedia[0].ThumbnailURL()
+	}
+
+	best, bestWidth := "", 0
+	for _, c := range m.ImageVersions2.Candidates {
+		switch {
+		case best == "",
+			c.Width >= 320 && (bestWidth < 320 || c.Width < bestWidth),
+			bestWidth < 320 && c.Width > bestWidth:
+			best, bestWidth = c.URL, c.Width
+		}
+	}
+	return best
+}
+
+// BestVideoURL returns the highest resolution video version
+func (m *DirectMedia) BestVideoURL() string {
+	best, bestArea := "", -1
+	for _, v := range m.VideoVersions {
+		if area := v.Width * v.Height; area > bestArea {
+			best, bestArea = v.URL, area
+		}
+	}
+	return best
+}
+
+// attachments lists the files of a media object, expanding carousels
+func (m *DirectMedia) attachments(shared bool) []Attachment {
+	if len(m.CarouselMedia) > 0 {
+		var out []Attachment
+		for i := range m.CarouselMedia {
+			for _, a := range m.CarouselMedia[i].attachments(shared) {
+				a.Index = i + 1
+				out = append(out, a)
+			}
+		}
+		return out
+	}
+
+	if m.Audio != nil && m.Audio.AudioSrc != "" {
+		return []Attachment{{Kind: "voice", URL: m.Audio.AudioSrc, Ext: "m4a", Shared: shared}}
+	}
+
+	if url := m.BestVideoURL(); url != "" {
+		return []Attachment{{Kind: "video", URL: url, Ext: "mp4", Shared: shared}}
+	}
+
+	if url := m.BestImageURL(); url != "" {
+		return []Attachment{{Kind: "photo", URL: url, Ext: "jpg", Shared: shared}}
+	}
+
+	return nil
+}
+
+// Attachments returns every downloadable file carried by the item
+func (item *MessageItem) Attachments() []Attachment {
+	switch {
+	case item.VisualMedia != nil:
+		return item.VisualMedia.Media.attachments(false)
+	case item.VoiceMedia != nil:
+		return item.VoiceMedia.Media.attachments(false)
+	case item.MediaShare != nil:
+		return item.MediaShare.attachments(true)
+	case item.Clip != nil:
+		return item.Clip.Clip.attachments(true)
+	case item.ReelShare != nil && item.ReelShare.Media != nil:
+		return item.ReelShare.Media.attachments(true)
+	case item.StoryShare != nil && item.StoryShare.Media != nil:
+		return item.StoryShare.Media.attachments(true)
+	}
+	return nil
+}
+
+// DownloadMedia streams a CDN media URL into w. It stops when ctx is done or
+// when no data arrived for a while.
+func (c *Client) DownloadMedia(ctx context.Context, mediaURL string, w io.Writer) (int64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var stalled atomic.Bool
+	watchdog := time.AfterFunc(downloadStallTimeout, func() {
+		stalled.Store(true)
+		cancel()
+	})
+	defer watchdog.Stop()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", mediaURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", c.getWebUserAgent())
+	req.Header.Set("Referer", "https://www.instagram.com/")
+
+	resp, err := c.downloadClient().Do(req)
+	if err != nil {
+		if stalled.Load() {
+			return 0, errDownloadStalled
+		}
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusForbidden, http.StatusGone:
+		return 0, fmt.Errorf("%w: status %d", ErrMediaExpired, resp.StatusCode)
+	default:
+		return 0, fmt.Errorf("download failed: status %d", resp.StatusCode)
+	}
+
+	n, err := io.Copy(w, &stallReader{r: resp.Body, watchdog: watchdog})
+	if err != nil && stalled.Load() {
+		return n, errDownloadStalled
+	}
+	return n, err
+}
+
+// downloadClient returns the client media downloads share. Large videos
+// easily outlast the API client's 30s timeout, so only the wait for headers
+// is bounded.
+func (c *Client) downloadClient() *http.Client {
+	c.downloadOnce.Do(func() {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.ResponseHeaderTimeout = downloadHeaderTimeout
+		c.downloader = &http.Client{Jar: c.httpClient.Jar, Transport: transport}
+	})
+	return c.downloader
+}
+
+// stallReader pushes the watchdog back whenever data arrives
+type stallReader struct {
+	r        io.Reader
+	watchdog *time.Timer
+}
+
+func (s *stallReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if n > 0 {
+		s.watchdog.Reset(downloadStallTimeout)
+	}
+	return n, err
+}
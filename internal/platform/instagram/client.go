@@ -5,7 +5,9 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
@@ -520,3 +522,29 @@ func (c *Client) FromJSON(data []byte) error {
 func (c *Client) getWebUserAgent() string {
 	return "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
 }
+
+// IsPermanentError reports whether Instagram rejected a request in a way
+// that sending it again won't change: a 4xx answer that isn't a rate limit
+func IsPermanentError(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode < 400 || apiErr.StatusCode >= 500 {
+		return false
+	}
+	if apiErr.StatusCode == http.StatusTooManyRequests {
+		return false
+	}
+
+	switch apiErr.ErrorType {
+	case ErrRateLimited.ErrorType, "rate_limit_error", "feedback_required":
+		return false
+	}
+	return !strings.Contains(strings.ToLower(apiErr.Message), "wait a few minutes")
+}
+
+// IsNetworkError reports whether err means Instagram couldn't be reached at
+// all, as opposed to a request it answered with an error
+func IsNetworkError(err error) bool {
+	var urlErr *url.Error
+	var netErr net.Error
+	return errors.As(err, &urlErr) || errors.As(err, &netErr)
+}
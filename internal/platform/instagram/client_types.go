@@ -50,6 +50,11 @@ type Client struct {
 	httpClient *http.Client
 	csrfToken  string
 
+	// downloader fetches CDN media, created on first use and shared by every
+	// download so connections are reused
+	downloadOnce sync.Once
+	downloader   *http.Client
+
 	ReloginAttempt int `json:"-"`
 
 	Debug bool `json:"-"`
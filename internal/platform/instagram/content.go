@@ -0,0 +1,332 @@
+package instagram
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// formatMessageContent renders an item for display. The first line is a
+// headline; shares add their caption and link cards their title and summary
+// on the following lines.
+func formatMessageContent(item MessageItem) string {
+	switch item.ItemType {
+	case "text":
+		return item.Text
+	case "media_share":
+		if item.MediaShare != nil {
+			return sharedMediaContent("📷", "Post", item.MediaShare, item.Text)
+		}
+		return "📷 [Shared a post]"
+	case "clip":
+		if item.Clip != nil {
+			return sharedMediaContent("🎬", "Reel", &item.Clip.Clip, item.Text)
+		}
+		return "🎬 [Shared a reel]"
+	case "felix_share":
+		return "📺 [Shared a video]"
+	case "voice_media":
+		return voiceContent(item.VoiceMedia)
+	case "visual_media", "raven_media":
+		return visualContent(item.VisualMedia)
+	case "reel_share":
+		return reelShareContent(item.ReelShare)
+	case "story_share":
+		return storyShareContent(item.StoryShare)
+	case "link":
+		return linkContent(item.Link, item.Text)
+	case "like":
+		return "❤️"
+	case "animated_media":
+		if item.AnimatedMedia != nil && item.AnimatedMedia.IsSticker {
+			return "🏷️ [Sticker]"
+		}
+		return "🎭 [GIF]"
+	case "profile":
+		if item.Profile != nil {
+			return joinContent(fmt.Sprintf("👤 [Profile @%s]", item.Profile.Username), item.Profile.FullName)
+		}
+		return "👤 [Shared a profile]"
+	case "action_log":
+		if item.ActionLog != nil && item.ActionLog.Description != "" {
+			return "ℹ️ " + item.ActionLog.Description
+		}
+	case "video_call_event":
+		if item.VideoCallEvent != nil && item.VideoCallEvent.Description != "" {
+			return "📞 " + item.VideoCallEvent.Description
+		}
+		return "📞 [Call]"
+	case "placeholder":
+		if item.Placeholder != nil && item.Placeholder.Title != "" {
+			return joinContent(fmt.Sprintf("[%s]", item.Placeholder.Title), item.Placeholder.Message)
+		}
+		return "[Message unavailable]"
+	}
+
+	if xma := item.xma(); xma != nil {
+		return xmaContent(item.ItemType, xma, item.Text)
+	}
+
+	if item.Text != "" {
+		return item.Text
+	}
+	return fmt.Sprintf("[%s]", item.ItemType)
+}
+
+// xma returns the first extensible attachment of the item, if any
+func (item *MessageItem) xma() *XMA {
+	for _, list := range [][]XMA{
+		item.XMAMediaShare, item.XMAReelShare, item.XMAStoryShare,
+		item.XMAClip, item.XMALink, item.XMAShare, item.GenericXMA,
+	} {
+		if len(list) > 0 {
+			return &list[0]
+		}
+	}
+	return nil
+}
+
+func sharedMediaContent(icon, kind string, m *DirectMedia, text string) string {
+	headline := kind
+	if m.User != nil && m.User.Username != "" {
+		headline += " by @" + m.User.Username
+	}
+	if n := len(m.CarouselMedia); n > 0 {
+		headline += fmt.Sprintf(" · %d items", n)
+	}
+	if m.VideoDuration > 0 {
+		headline += " · " + formatDuration(m.VideoDuration)
+	}
+
+	var caption string
+	if m.Caption != nil {
+		caption = clipText(m.Caption.Text, 200)
+	}
+
+	return joinContent(fmt.Sprintf("%s [%s]", icon, headline), caption, text)
+}
+
+func voiceContent(v *VoiceMedia) string {
+	if v == nil || v.Media.Audio == nil || v.Media.Audio.Duration <= 0 {
+		return "🎤 [Voice message]"
+	}
+	return fmt.Sprintf("🎤 [Voice message · %s]", formatDuration(float64(v.Media.Audio.Duration)/1000))
+}
+
+func visualContent(v *VisualMedia) string {
+	if v == nil {
+		return "📸 [Photo/Video]"
+	}
+
+	headline := "📸 [Photo"
+	if len(v.Media.VideoVersions) > 0 {
+		headline = "🎥 [Video"
+		if v.Media.VideoDuration > 0 {
+			headline += " · " + formatDuration(v.Media.VideoDuration)
+		}
+	}
+
+	switch v.ViewMode {
+	case "once":
+		headline += " · view once"
+	case "replayable":
+		headline += " · replayable"
+	}
+
+	return headline + "]"
+}
+
+func reelShareContent(r *ReelShare) string {
+	if r == nil {
+		return "🎬 [Shared a reel]"
+	}
+
+	author := ""
+	if r.Media != nil && r.Media.User != nil {
+		author = "@" + r.Media.User.Username
+	}
+
+	var headline string
+	switch r.ReelType {
+	case "reply":
+		headline = "💬 [Replied to a story"
+		if author != "" {
+			headline = fmt.Sprintf("💬 [Replied to %s's story", author)
+		}
+	case "reaction":
+		headline = "😀 [Reacted to a story"
+		if author != "" {
+			headline = fmt.Sprintf("😀 [Reacted to %s's story", author)
+		}
+	case "mention":
+		headline = "📣 [Mentioned in a story"
+		if author != "" {
+			headline = fmt.Sprintf("📣 [Mentioned in %s's story", author)
+		}
+	default:
+		headline = "🎬 [Shared a reel"
+		if author != "" {
+			headline += " by " + author
+		}
+	}
+
+	if r.Media != nil {
+		if expiry := expiryText(r.Media.ExpiringAt); expiry != "" {
+			headline += " · " + expiry
+		}
+	}
+
+	return joinContent(headline+"]", r.Text)
+}
+
+func storyShareContent(s *StoryShare) string {
+	if s == nil {
+		return "📖 [Shared a story]"
+	}
+
+	if s.Media == nil {
+		title := s.Title
+		if title == "" {
+			title = "Story unavailable"
+		}
+		return joinContent(fmt.Sprintf("📖 [%s]", title), s.Message, s.Text)
+	}
+
+	headline := "Story"
+	if s.Media.User != nil && s.Media.User.Username != "" {
+		headline += " by @" + s.Media.User.Username
+	}
+	if expiry := expiryText(s.Media.ExpiringAt); expiry != "" {
+		headline += " · " + expiry
+	}
+
+	return joinContent(fmt.Sprintf("📖 [%s]", headline), s.Text)
+}
+
+func linkContent(link *LinkShare, text string) string {
+	if link == nil {
+		if text != "" {
+			return "🔗 " + text
+		}
+		return "🔗 [Shared a link]"
+	}
+
+	headline := link.Text
+	if headline == "" {
+		headline = link.LinkContext.LinkURL
+	}
+
+	var card []string
+	if title := clipText(link.LinkContext.LinkTitle, 100); title != "" {
+		card = append(card, "╰ "+title)
+	}
+	if summary := clipText(link.LinkContext.LinkSummary, 160); summary != "" {
+		card = append(card, "  "+summary)
+	}
+
+	return joinContent("🔗 "+headline, card...)
+}
+
+func xmaContent(itemType string, x *XMA, text string) string {
+	icon, kind := "📎", "Attachment"
+	switch itemType {
+	case "xma_media_share":
+		icon, kind = "📷", "Post"
+	case "xma_reel_share", "xma_clip":
+		icon, kind = "🎬", "Reel"
+	case "xma_story_share":
+		icon, kind = "📖", "Story"
+	case "xma_link":
+		icon, kind = "🔗", "Link"
+	}
+
+	headline := kind
+	if x.HeaderTitleText != "" {
+		headline += " · " + x.HeaderTitleText
+	}
+
+	body := x.CaptionBodyText
+	if body == "" {
+		body = x.SubtitleText
+	}
+
+	var target string
+	if kind == "Link" || kind == "Attachment" {
+		target = x.TargetURL
+	}
+
+	return joinContent(fmt.Sprintf("%s [%s]", icon, headline), clipText(x.TitleText, 100), clipText(body, 200), target, text)
+}
+
+// joinContent puts the non-empty parts on their own lines
+func joinContent(headline string, parts ...string) string {
+	lines := []string{headline}
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			lines = append(lines, p)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// clipText flattens s to one line of at most n runes
+func clipText(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
+
+func formatDuration(seconds float64) string {
+	total := int(seconds + 0.5)
+	return fmt.Sprintf("%d:%02d", total/60, total%60)
+}
+
+// expiryText describes when a story expires, given its expiring_at in seconds
+func expiryText(expiringAt json.Number) string {
+	secs, err := expiringAt.Int64()
+	if err != nil || secs <= 0 {
+		return ""
+	}
+
+	left := time.Until(time.Unix(secs, 0))
+	switch {
+	case left <= 0:
+		return "expired"
+	case left < time.Hour:
+		return fmt.Sprintf("expires in %dm", int(left.Minutes())+1)
+	default:
+		return fmt.Sprintf("expires in %dh", int(left.Hours()+0.5))
+	}
+}
+
+// PreviewImageURL returns a small image of whatever the item carries: a
+// sent photo or video, a shared post, reel or story, or a link card
+func (item *MessageItem) PreviewImageURL() string {
+	var media *DirectMedia
+	switch {
+	case item.VisualMedia != nil:
+		media = &item.VisualMedia.Media
+	case item.MediaShare != nil:
+		media = item.MediaShare
+	case item.Clip != nil:
+		media = &item.Clip.Clip
+	case item.ReelShare != nil && item.ReelShare.Media != nil:
+		media = item.ReelShare.Media
+	case item.StoryShare != nil && item.StoryShare.Media != nil:
+		media = item.StoryShare.Media
+	}
+	if media != nil {
+		return media.ThumbnailURL()
+	}
+
+	if xma := item.xma(); xma != nil {
+		return xma.PreviewURL
+	}
+	if item.Link != nil {
+		return item.Link.LinkContext.LinkImageURL
+	}
+	return ""
+}
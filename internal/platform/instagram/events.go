@@ -0,0 +1,242 @@
+package instagram
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventMessage  EventType = "message"
+	EventReaction EventType = "reaction"
+	EventRequest  EventType = "request"
+	EventTyping   EventType = "typing"
+	EventSeen     EventType = "seen"
+)
+
+// Event is something that happened in the inbox since the last check
+type Event struct {
+	Type        EventType `json:"type"`
+	Source      string    `json:"source,omitempty"`
+	ThreadID    string    `json:"thread_id"`
+	ThreadTitle string    `json:"thread_title"`
+	ItemID      string    `json:"item_id,omitempty"`
+	ItemType    string    `json:"item_type,omitempty"`
+	SenderID    int64     `json:"sender_id"`
+	SenderName  string    `json:"sender"`
+	Text        string    `json:"text,omitempty"`
+	Emoji       string    `json:"emoji,omitempty"`
+	FromMe      bool      `json:"from_me"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// InboxWatcher turns successive inbox snapshots into events. Only threads
+// whose last_activity_at moved are inspected; the inbox endpoint has no
+// seq_id delta, that is what Realtime subscribes with.
+type InboxWatcher struct {
+	c *Client
+
+	threads map[string]*watchedThread
+	pending map[string]bool
+	primed  bool
+
+	// When the previous poll started. Threads seen for the first time only
+	// report items newer than this, so an old conversation coming back to
+	// the top of the inbox doesn't replay its history.
+	lastPoll time.Time
+}
+
+type watchedThread struct {
+	activity  int64
+	items     map[string]bool
+	reactions map[string]bool
+}
+
+func (c *Client) NewInboxWatcher() *InboxWatcher {
+	return &InboxWatcher{
+		c:       c,
+		threads: make(map[string]*watchedThread),
+		pending: make(map[string]bool),
+	}
+}
+
+// Poll fetches the inbox and the requests folder and returns what changed.
+// The first call only records the current state.
+func (w *InboxWatcher) Poll() ([]Event, error) {
+	polledAt := time.Now()
+
+	inbox, err := w.c.GetInbox("", 20)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := w.c.GetPendingInbox("", 20)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	for _, thread := range inbox.Inbox.Threads {
+		threadEvents, err := w.updateThread(thread)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, threadEvents...)
+	}
+
+	current := make(map[string]bool, len(pending.Inbox.Threads))
+	for _, thread := range pending.Inbox.Threads {
+		current[thread.ThreadID] = true
+		if w.primed && !w.pending[thread.ThreadID] {
+			events = append(events, w.requestEvent(thread))
+		}
+	}
+	w.pending = current
+
+	w.primed = true
+	w.lastPoll = polledAt
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+
+	return events, nil
+}
+
+func (w *InboxWatcher) updateThread(thread Thread) ([]Event, error) {
+	activity, _ := thread.LastActivityAt.Int64()
+
+	state, known := w.threads[thread.ThreadID]
+	if known && activity <= state.activity {
+		return nil, nil
+	}
+
+	items := thread.Items
+
+	// The inbox only carries the last few items of a thread. If none of them
+	// were seen before, more may have arrived than fit, so fetch a full page.
+	if known && len(items) > 0 && !w.anyKnown(state, items) {
+		threadResp, err := w.c.GetThread(thread.ThreadID, "", 50)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch thread: %w", err)
+		}
+		items = threadResp.Thread.Items
+	}
+
+	next := &watchedThread{
+		activity:  activity,
+		items:     make(map[string]bool, len(items)),
+		reactions: make(map[string]bool),
+	}
+	w.threads[thread.ThreadID] = next
+
+	userMap := eventUserMap(w.c, thread)
+	title := ThreadTitle(thread)
+
+	var events []Event
+	for _, item := range items {
+		next.items[item.ItemID] = true
+
+		if w.primed && w.isNew(state, item) {
+			events = append(events, itemEvent(w.c, thread.ThreadID, title, item, userMap))
+		}
+
+		for _, r := range mapReactions(item.Reactions, userMap) {
+			key := fmt.Sprintf("%s|%d|%s", item.ItemID, r.SenderID, r.Emoji)
+			next.reactions[key] = true
+
+			if w.primed && known && state.items[item.ItemID] && !state.reactions[key] {
+				events = append(events, Event{
+					Type:        EventReaction,
+					ThreadID:    thread.ThreadID,
+					ThreadTitle: title,
+					ItemID:      item.ItemID,
+					ItemType:    item.ItemType,
+					SenderID:    r.SenderID,
+					SenderName:  r.SenderName,
+					Text:        formatMessageContent(item),
+					Emoji:       r.Emoji,
+					FromMe:      r.SenderID == w.c.UserID(),
+					Timestamp:   r.Timestamp,
+				})
+			}
+		}
+	}
+
+	return events, nil
+}
+
+// isNew reports whether item arrived since the last poll. state is nil for
+// threads the watcher hasn't seen yet.
+func (w *InboxWatcher) isNew(state *watchedThread, item MessageItem) bool {
+	if state != nil {
+		return !state.items[item.ItemID]
+	}
+
+	ts, err := item.Timestamp.Int64()
+	return err == nil && time.Unix(0, ts*1000).After(w.lastPoll)
+}
+
+func (w *InboxWatcher) anyKnown(state *watchedThread, items []MessageItem) bool {
+	for _, item := range items {
+		if state.items[item.ItemID] {
+			return true
+		}
+	}
+	return false
+}
+
+func eventUserMap(c *Client, thread Thread) map[int64]string {
+	userMap := make(map[int64]string, len(thread.Users)+1)
+	for _, user := range thread.Users {
+		pk, _ := user.Pk.Int64()
+		userMap[pk] = user.Username
+	}
+	userMap[c.UserID()] = c.Username
+	return userMap
+}
+
+func itemEvent(c *Client, threadID string, title string, item MessageItem, userMap map[int64]string) Event {
+	senderID, _ := item.UserID.Int64()
+	ts, _ := item.Timestamp.Int64()
+
+	e := Event{
+		Type:        EventMessage,
+		ThreadID:    threadID,
+		ThreadTitle: title,
+		ItemID:      item.ItemID,
+		ItemType:    item.ItemType,
+		SenderID:    senderID,
+		SenderName:  userMap[senderID],
+		Text:        formatMessageContent(item),
+		FromMe:      senderID == c.UserID(),
+		Timestamp:   time.Unix(0, ts*1000),
+	}
+	if e.SenderName == "" {
+		e.SenderName = fmt.Sprintf("User %d", senderID)
+	}
+
+	return e
+}
+
+func (w *InboxWatcher) requestEvent(thread Thread) Event {
+	e := Event{
+		Type:        EventRequest,
+		ThreadID:    thread.ThreadID,
+		ThreadTitle: ThreadTitle(thread),
+		Timestamp:   time.Now(),
+	}
+
+	last := thread.LastPermanentItem
+	if last.ItemType != "" {
+		e = itemEvent(w.c, thread.ThreadID, e.ThreadTitle, last, eventUserMap(w.c, thread))
+		e.Type = EventRequest
+	} else if thread.Inviter != nil {
+		e.SenderID, _ = thread.Inviter.Pk.Int64()
+		e.SenderName = thread.Inviter.Username
+	}
+
+	return e
+}
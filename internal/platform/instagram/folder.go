@@ -0,0 +1,57 @@
+package instagram
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Inbox folders. The default inbox blends primary and general.
+const (
+	FolderAll      = "all"
+	FolderPrimary  = "primary"
+	FolderGeneral  = "general"
+	FolderRequests = "requests"
+)
+
+// InboxFolders lists the folders in display order
+var InboxFolders = []string{FolderAll, FolderPrimary, FolderGeneral, FolderRequests}
+
+// ParseFolder normalizes a folder name, accepting "" and a few aliases
+func ParseFolder(name string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", FolderAll, "inbox":
+		return FolderAll, nil
+	case FolderPrimary:
+		return FolderPrimary, nil
+	case FolderGeneral:
+		return FolderGeneral, nil
+	case FolderRequests, "pending", "req":
+		return FolderRequests, nil
+	}
+	return "", fmt.Errorf("unknown folder %q (use all, primary, general or requests)", name)
+}
+
+// GetFolder fetches one page of conversations from a folder. Pass the
+// OldestCursor of the previous page to continue past it.
+func (c *Client) GetFolder(folder string, cursor string, limit int) (*ConversationList, error) {
+	var (
+		inbox *InboxResponse
+		err   error
+	)
+
+	switch folder {
+	case FolderPrimary:
+		inbox, err = c.getInbox("inbox", "0", cursor, limit)
+	case FolderGeneral:
+		inbox, err = c.getInbox("inbox", "1", cursor, limit)
+	case FolderRequests:
+		inbox, err = c.GetPendingInbox(cursor, limit)
+	default:
+		inbox, err = c.GetInbox(cursor, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newConversationList(inbox), nil
+}
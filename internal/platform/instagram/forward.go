@@ -0,0 +1,87 @@
+package instagram
+
+import "fmt"
+
+// SharedMedia returns the post, reel or story the item shares. Items that
+// only carry an xma card have no media ID and return nil, and so do story
+// replies and reactions: what they say is theirs, not the story's.
+func (item *MessageItem) SharedMedia() *SharedMedia {
+	switch {
+	case item.MediaShare != nil && item.MediaShare.ID != "":
+		kind := SharedPost
+		if item.MediaShare.ProductType == "clips" {
+			kind = SharedClip
+		}
+		return &SharedMedia{ID: item.MediaShare.ID, Kind: kind, MediaType: shareMediaType(item.MediaShare.MediaType)}
+	case item.Clip != nil && item.Clip.Clip.ID != "":
+		return &SharedMedia{ID: item.Clip.Clip.ID, Kind: SharedClip, MediaType: shareMediaType(item.Clip.Clip.MediaType)}
+	case item.ReelShare != nil && item.ReelShare.Media != nil && !item.ReelShare.IsResponse():
+		return storyMedia(item.ReelShare.Media)
+	case item.StoryShare != nil && item.StoryShare.Media != nil:
+		return storyMedia(item.StoryShare.Media)
+	}
+	return nil
+}
+
+// IsResponse reports whether the share is a reply or reaction to a story
+// rather than the story itself
+func (r *ReelShare) IsResponse() bool {
+	return r.ReelType == "reply" || r.ReelType == "reaction"
+}
+
+func storyMedia(media *DirectMedia) *SharedMedia {
+	if media.ID == "" || media.User == nil {
+		return nil
+	}
+	return &SharedMedia{ID: media.ID, Kind: SharedStory, MediaType: shareMediaType(media.MediaType), ReelID: media.User.Pk.String()}
+}
+
+// shareMediaType names a media_type number the way the share endpoints do
+func shareMediaType(mediaType int) string {
+	switch mediaType {
+	case 2:
+		return "video"
+	case 8:
+		return "carousel"
+	}
+	return "photo"
+}
+
+// ShareMedia shares a post, reel or story into a thread, the way the app's
+// share button does
+func (c *Client) ShareMedia(threadID string, media SharedMedia, opts SendOptions) (*SendMessageResponse, error) {
+	clientContext := opts.ClientContext
+	if clientContext == "" {
+		clientContext = NewClientContext()
+	}
+
+	data := c.broadcastForm(threadID, clientContext)
+
+	mediaType := media.MediaType
+	if mediaType == "" {
+		mediaType = "photo"
+	}
+
+	var endpoint string
+	switch media.Kind {
+	case SharedPost:
+		endpoint = "threads/broadcast/media_share/?media_type=" + mediaType
+		data.Set("media_id", media.ID)
+	case SharedClip:
+		endpoint = "threads/broadcast/clip_share/"
+		data.Set("media_id", media.ID)
+	case SharedStory:
+		endpoint = "threads/broadcast/story_share/?media_type=" + mediaType
+		data.Set("story_media_id", media.ID)
+		data.Set("reel_id", media.ReelID)
+	default:
+		return nil, fmt.Errorf("can't share %q media", media.Kind)
+	}
+
+	body, err := c.postDirect(endpoint, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeSendResponse(body, clientContext)
+}
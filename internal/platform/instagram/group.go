@@ -0,0 +1,146 @@
+package instagram
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// CreateGroupThread starts a group conversation with the given users
+func (c *Client) CreateGroupThread(userIDs []string, title string) (*Thread, error) {
+	if len(userIDs) < 2 {
+		return nil, fmt.Errorf("a group needs at least two other members")
+	}
+
+	return c.createThread(userIDs, title)
+}
+
+// GetThreadByParticipants returns the existing thread with exactly these
+// users, or nil when there is none
+func (c *Client) GetThreadByParticipants(userIDs []string) (*Thread, error) {
+	recipients, _ := json.Marshal(userIDs)
+
+	req, err := http.NewRequest("GET", "https://www.instagram.com/api/v1/direct_v2/threads/get_by_participants/?recipient_users="+url.QueryEscape(string(recipients)), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.setWebHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if c.Debug {
+		fmt.Printf("[DEBUG] Thread lookup status: %d\n", resp.StatusCode)
+		fmt.Printf("[DEBUG] Thread lookup response: %s\n", string(body))
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to look up thread: status %d", resp.StatusCode)
+	}
+
+	var threadResp ThreadResponse
+	if err := json.Unmarshal(body, &threadResp); err != nil {
+		return nil, fmt.Errorf("failed to parse thread response: %w", err)
+	}
+
+	if threadResp.Thread.ThreadID == "" {
+		return nil, nil
+	}
+	return &threadResp.Thread, nil
+}
+
+// GetOrCreateDirectThread returns the 1:1 thread with a user, creating it
+// when the two of you have never talked
+func (c *Client) GetOrCreateDirectThread(userID string) (*Thread, error) {
+	thread, err := c.GetThreadByParticipants([]string{userID})
+	if err != nil || thread != nil {
+		return thread, err
+	}
+
+	return c.createThread([]string{userID}, "")
+}
+
+func (c *Client) createThread(userIDs []string, title string) (*Thread, error) {
+	recipients, _ := json.Marshal(userIDs)
+
+	data := url.Values{}
+	data.Set("_uuid", c.UUID)
+	data.Set("recipient_users", string(recipients))
+	data.Set("client_context", NewClientContext())
+	if title != "" {
+		data.Set("thread_title", title)
+	}
+
+	body, err := c.postDirect("create_group_thread/", data)
+	if err != nil {
+		return nil, err
+	}
+
+	var thread Thread
+	if err := json.Unmarshal(body, &thread); err != nil {
+		return nil, fmt.Errorf("failed to parse thread: %w", err)
+	}
+	if thread.ThreadID == "" {
+		return nil, fmt.Errorf("instagram returned no thread")
+	}
+
+	return &thread, nil
+}
+
+// RenameThread sets the title of a group conversation
+func (c *Client) RenameThread(threadID string, title string) error {
+	data := url.Values{}
+	data.Set("_uuid", c.UUID)
+	data.Set("title", title)
+
+	_, err := c.postDirect(fmt.Sprintf("threads/%s/update_title/", threadID), data)
+	return err
+}
+
+// AddThreadUsers adds users to a group conversation
+func (c *Client) AddThreadUsers(threadID string, userIDs []string) error {
+	ids, _ := json.Marshal(userIDs)
+
+	data := url.Values{}
+	data.Set("_uuid", c.UUID)
+	data.Set("user_ids", string(ids))
+
+	_, err := c.postDirect(fmt.Sprintf("threads/%s/add_user/", threadID), data)
+	return err
+}
+
+// RemoveThreadUsers removes users from a group conversation. Only admins
+// can remove other members.
+func (c *Client) RemoveThreadUsers(threadID string, userIDs []string) error {
+	ids, _ := json.Marshal(userIDs)
+
+	data := url.Values{}
+	data.Set("_uuid", c.UUID)
+	data.Set("user_ids", string(ids))
+
+	_, err := c.postDirect(fmt.Sprintf("threads/%s/remove_users/", threadID), data)
+	return err
+}
+
+// LeaveThread leaves a group conversation
+func (c *Client) LeaveThread(threadID string) error {
+	data := url.Values{}
+	data.Set("_uuid", c.UUID)
+
+	_, err := c.postDirect(fmt.Sprintf("threads/%s/leave/", threadID), data)
+	return err
+}
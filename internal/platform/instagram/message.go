@@ -4,17 +4,42 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 )
 
+var linkPattern = regexp.MustCompile(`(?i)\b((?:https?://|www\.)[^\s<>"]+)`)
+
+// ContainsLink reports whether text has a link Instagram would preview: one
+// starting with a scheme or www.
+func ContainsLink(text string) bool {
+	return linkPattern.MatchString(text)
+}
+
 func (c *Client) GetInbox(cursor string, limit int) (*InboxResponse, error) {
+	return c.getInbox("inbox", "", cursor, limit)
+}
+
+// GetPendingInbox fetches the message requests folder
+func (c *Client) GetPendingInbox(cursor string, limit int) (*InboxResponse, error) {
+	return c.getInbox("pending_inbox", "", cursor, limit)
+}
+
+func (c *Client) getInbox(path string, folder string, cursor string, limit int) (*InboxResponse, error) {
 	if limit <= 0 {
 		limit = 20
 	}
 
-	url := fmt.Sprintf("https://www.instagram.com/api/v1/direct_v2/inbox/?limit=%d&thread_message_limit=10&persistentBadging=true&folder=", limit)
+	url := fmt.Sprintf("https://www.instagram.com/api/v1/direct_v2/%s/?limit=%d&thread_message_limit=10&persistentBadging=true", path, limit)
 
+	if folder != "" {
+		url += "&folder=" + folder
+	}
 	if cursor != "" {
 		url += "&cursor=" + cursor
 	}
@@ -100,60 +125,351 @@ func (c *Client) GetThread(threadID string, cursor string, limit int) (*ThreadRe
 	return &threadResp, nil
 }
 
+// NewClientContext generates a client_context (mutation token) used to
+// deduplicate sent items on Instagram's side
+func NewClientContext() string {
+	return strconv.FormatInt(6800000000000000000+rand.Int63n(99999999999999999), 10)
+}
+
+// postDirect sends a form-encoded POST to a direct_v2 endpoint and returns the raw body
+func (c *Client) postDirect(path string, data url.Values) ([]byte, error) {
+	url := "https://www.instagram.com/api/v1/direct_v2/" + path
+
+	req, err := http.NewRequest("POST", url, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.setWebHeaders(req)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if c.Debug {
+		fmt.Printf("[DEBUG] POST %s status: %d\n", path, resp.StatusCode)
+		fmt.Printf("[DEBUG] POST %s response: %s\n", path, string(body))
+	}
+
+	var apiResp APIResponse
+	_ = json.Unmarshal(body, &apiResp)
+
+	if resp.StatusCode != http.StatusOK || (apiResp.Status != "" && apiResp.Status != "ok") {
+		apiResp.RawBody = body
+		return nil, &APIError{
+			StatusCode: resp.StatusCode,
+			Message:    apiResp.Message,
+			ErrorType:  apiResp.ErrorType,
+			Response:   &apiResp,
+		}
+	}
+
+	return body, nil
+}
+
+// broadcastForm returns the fields shared by every broadcast/* endpoint
+func (c *Client) broadcastForm(threadID, clientContext string) url.Values {
+	data := url.Values{}
+	data.Set("action", "send_item")
+	data.Set("is_shh_mode", "0")
+	data.Set("send_attribution", "direct_thread")
+	data.Set("client_context", clientContext)
+	data.Set("mutation_token", clientContext)
+	data.Set("offline_threading_id", clientContext)
+	data.Set("_uuid", c.UUID)
+	data.Set("device_id", c.AndroidDeviceID)
+	data.Set("thread_ids", fmt.Sprintf("[%s]", threadID))
+	return data
+}
+
+// SendMessage sends a text message to a thread
 func (c *Client) SendMessage(threadID string, text string) (*SendMessageResponse, error) {
-	return nil, fmt.Errorf("not implemented")
+	return c.SendTextMessage(threadID, text, SendOptions{})
+}
+
+// SendTextMessage sends a text message, switching to the link endpoint when
+// the text contains URLs so Instagram renders a link preview
+func (c *Client) SendTextMessage(threadID string, text string, opts SendOptions) (*SendMessageResponse, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("message text cannot be empty")
+	}
+
+	clientContext := opts.ClientContext
+	if clientContext == "" {
+		clientContext = NewClientContext()
+	}
+
+	data := c.broadcastForm(threadID, clientContext)
+
+	if opts.ReplyToItemID != "" {
+		data.Set("replied_to_item_id", opts.ReplyToItemID)
+		data.Set("replied_to_client_context", opts.ReplyToClientContext)
+	}
+
+	endpoint := "threads/broadcast/text/"
+	if links := linkPattern.FindAllString(text, -1); len(links) > 0 {
+		endpoint = "threads/broadcast/link/"
+		linkURLs, _ := json.Marshal(links)
+		data.Set("link_text", text)
+		data.Set("link_urls", string(linkURLs))
+	} else {
+		data.Set("text", text)
+	}
+
+	body, err := c.postDirect(endpoint, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeSendResponse(body, clientContext)
+}
+
+func decodeSendResponse(body []byte, clientContext string) (*SendMessageResponse, error) {
+	var sendResp SendMessageResponse
+	if err := json.Unmarshal(body, &sendResp); err != nil {
+		return nil, fmt.Errorf("failed to parse send response: %w", err)
+	}
+
+	if sendResp.Payload.ClientContext == "" {
+		sendResp.Payload.ClientContext = clientContext
+	}
+
+	return &sendResp, nil
+}
+
+// SendReaction reacts to a message with an emoji
+func (c *Client) SendReaction(threadID string, itemID string, emoji string) error {
+	data := c.broadcastForm(threadID, NewClientContext())
+	data.Set("item_id", itemID)
+	data.Set("node_type", "item")
+	data.Set("reaction_type", "like")
+	data.Set("reaction_status", "created")
+	data.Set("emoji", emoji)
+
+	_, err := c.postDirect("threads/broadcast/reaction/", data)
+	return err
+}
+
+// UnsendMessage deletes one of our own messages for everyone in the thread
+func (c *Client) UnsendMessage(threadID string, itemID string) error {
+	data := url.Values{}
+	data.Set("_uuid", c.UUID)
+	data.Set("is_shh_mode", "0")
+
+	_, err := c.postDirect(fmt.Sprintf("threads/%s/items/%s/delete/", threadID, itemID), data)
+	return err
 }
 
 func (c *Client) MarkThreadSeen(threadID string, itemID string) error {
-	return fmt.Errorf("not implemented")
+	data := url.Values{}
+	data.Set("thread_id", threadID)
+	data.Set("action", "mark_seen")
+	data.Set("client_context", NewClientContext())
+	data.Set("_uuid", c.UUID)
+	data.Set("use_unified_inbox", "true")
+
+	_, err := c.postDirect(fmt.Sprintf("threads/%s/items/%s/seen/", threadID, itemID), data)
+	return err
+}
+
+// SeenReceipts returns the read position of everyone else in the thread
+func (c *Client) SeenReceipts(thread *Thread) []SeenReceipt {
+	usernames := make(map[string]string, len(thread.Users))
+	for _, user := range thread.Users {
+		usernames[user.Pk.String()] = user.Username
+	}
+
+	var receipts []SeenReceipt
+	for userID, marker := range thread.LastSeenAt {
+		username, ok := usernames[userID]
+		if !ok {
+			// Our own marker, or someone who left
+			continue
+		}
+
+		ts, _ := marker.Timestamp.Int64()
+		receipts = append(receipts, SeenReceipt{
+			Username:  username,
+			ItemID:    marker.ItemID,
+			Timestamp: time.Unix(0, ts*1000),
+		})
+	}
+
+	return receipts
+}
+
+// IndicateTyping shows or clears our typing indicator in a thread
+func (c *Client) IndicateTyping(threadID string, typing bool) error {
+	data := c.broadcastForm(threadID, NewClientContext())
+	if typing {
+		data.Set("activity_status", "1")
+	} else {
+		data.Set("activity_status", "0")
+	}
+
+	_, err := c.postDirect("threads/broadcast/indicate_activity/", data)
+	return err
 }
 
+// ApproveThread moves a pending request into the main inbox
 func (c *Client) ApproveThread(threadID string) error {
-	return fmt.Errorf("not implemented")
+	data := url.Values{}
+	data.Set("_uuid", c.UUID)
+
+	_, err := c.postDirect(fmt.Sprintf("threads/%s/approve/", threadID), data)
+	return err
 }
 
+// DeclineThread removes a pending request
 func (c *Client) DeclineThread(threadID string) error {
-	return fmt.Errorf("not implemented")
+	data := url.Values{}
+	data.Set("_uuid", c.UUID)
+
+	_, err := c.postDirect(fmt.Sprintf("threads/%s/decline/", threadID), data)
+	return err
+}
+
+// DeclineAllThreads declines every pending request at once
+func (c *Client) DeclineAllThreads() error {
+	data := url.Values{}
+	data.Set("_uuid", c.UUID)
+
+	_, err := c.postDirect("threads/decline_all/", data)
+	return err
+}
+
+// MuteThread turns a thread's notifications off, or back on
+func (c *Client) MuteThread(threadID string, mute bool) error {
+	action := "mute"
+	if !mute {
+		action = "unmute"
+	}
+
+	data := url.Values{}
+	data.Set("_uuid", c.UUID)
+
+	_, err := c.postDirect(fmt.Sprintf("threads/%s/%s/", threadID, action), data)
+	return err
+}
+
+// PinThread pins a thread to the top of the inbox, or unpins it
+func (c *Client) PinThread(threadID string, pin bool) error {
+	action := "pin"
+	if !pin {
+		action = "unpin"
+	}
+
+	data := url.Values{}
+	data.Set("_uuid", c.UUID)
+
+	_, err := c.postDirect(fmt.Sprintf("threads/%s/%s/", threadID, action), data)
+	return err
+}
+
+// HideThread removes a thread from your inbox. It comes back when someone
+// writes to it again; the other members aren't affected.
+func (c *Client) HideThread(threadID string) error {
+	data := url.Values{}
+	data.Set("_uuid", c.UUID)
+	data.Set("use_unified_inbox", "true")
+
+	_, err := c.postDirect(fmt.Sprintf("threads/%s/hide/", threadID), data)
+	return err
 }
 
-func (c *Client) GetConversations() ([]Conversation, error) {
+func (c *Client) GetConversations() (*ConversationList, error) {
 	inbox, err := c.GetInbox("", 50)
 	if err != nil {
 		return nil, err
 	}
 
-	var conversations []Conversation
+	return newConversationList(inbox), nil
+}
+
+// GetPendingRequests returns the threads waiting in the message requests folder
+func (c *Client) GetPendingRequests() (*ConversationList, error) {
+	inbox, err := c.GetPendingInbox("", 50)
+	if err != nil {
+		return nil, err
+	}
+
+	return newConversationList(inbox), nil
+}
+
+func newConversationList(inbox *InboxResponse) *ConversationList {
+	list := &ConversationList{
+		UnseenCount:     inbox.Inbox.UnseenCount,
+		PendingRequests: inbox.PendingRequestsTotal,
+		OldestCursor:    inbox.Inbox.OldestCursor,
+		HasOlder:        inbox.Inbox.HasOlder && inbox.Inbox.OldestCursor != "",
+	}
+
 	for _, thread := range inbox.Inbox.Threads {
-		conv := Conversation{
-			ThreadID:    thread.ThreadID,
-			Title:       thread.ThreadTitle,
-			UnreadCount: thread.UnseenCount,
-			IsMuted:     thread.Muted,
-			IsPinned:    thread.IsPin,
-		}
+		list.Conversations = append(list.Conversations, ThreadToConversation(thread))
+	}
 
-		for _, user := range thread.Users {
-			conv.Users = append(conv.Users, user.Username)
-		}
+	return list
+}
+
+// ThreadToConversation summarizes a thread for the inbox list
+func ThreadToConversation(thread Thread) Conversation {
+	conv := Conversation{
+		ThreadID:    thread.ThreadID,
+		Title:       thread.ThreadTitle,
+		UnreadCount: thread.UnseenCount,
+		IsMuted:     thread.Muted,
+		IsPinned:    thread.IsPin,
+		IsPending:   thread.Pending,
+		IsGroup:     thread.IsGroup || len(thread.Users) > 1,
+	}
+
+	for _, user := range thread.Users {
+		conv.Users = append(conv.Users, user.Username)
+	}
 
-		if conv.Title == "" && len(conv.Users) > 0 {
-			conv.Title = conv.Users[0]
-			if len(conv.Users) > 1 {
-				conv.Title = fmt.Sprintf("%s +%d", conv.Users[0], len(conv.Users)-1)
+	for _, id := range thread.AdminUserIDs {
+		for _, user := range thread.Users {
+			if user.Pk == id {
+				conv.Admins = append(conv.Admins, user.Username)
 			}
 		}
+	}
 
-		if thread.LastPermanentItem.ItemType != "" {
-			conv.LastMessage = formatMessagePreview(thread.LastPermanentItem)
-			if ts, err := thread.LastPermanentItem.Timestamp.Int64(); err == nil {
-				conv.LastMessageAt = time.Unix(0, ts*1000)
-			}
+	conv.Title = ThreadTitle(thread)
+
+	if thread.LastPermanentItem.ItemType != "" {
+		conv.LastMessage = formatMessagePreview(thread.LastPermanentItem)
+		if ts, err := thread.LastPermanentItem.Timestamp.Int64(); err == nil {
+			conv.LastMessageAt = time.Unix(0, ts*1000)
 		}
+	}
+
+	return conv
+}
+
+// ThreadTitle returns the thread's name, falling back to its participants
+func ThreadTitle(thread Thread) string {
+	if thread.ThreadTitle != "" {
+		return thread.ThreadTitle
+	}
 
-		conversations = append(conversations, conv)
+	if len(thread.Users) == 0 {
+		return thread.ThreadID
 	}
 
-	return conversations, nil
+	title := thread.Users[0].Username
+	if len(thread.Users) > 1 {
+		title = fmt.Sprintf("%s +%d", title, len(thread.Users)-1)
+	}
+	return title
 }
 
 func (c *Client) GetMessages(threadID string, limit int) ([]Message, map[int64]string, error) {
@@ -162,15 +478,22 @@ func (c *Client) GetMessages(threadID string, limit int) ([]Message, map[int64]s
 		return nil, nil, err
 	}
 
+	messages, userMap := c.ThreadMessages(&threadResp.Thread)
+	return messages, userMap, nil
+}
+
+// ThreadMessages converts the items of a thread into display messages and
+// returns the user ID to username map used for sender names
+func (c *Client) ThreadMessages(thread *Thread) ([]Message, map[int64]string) {
 	userMap := make(map[int64]string)
-	for _, user := range threadResp.Thread.Users {
+	for _, user := range thread.Users {
 		pk, _ := user.Pk.Int64()
 		userMap[pk] = user.Username
 	}
 	userMap[c.UserID()] = "You"
 
 	var messages []Message
-	for _, item := range threadResp.Thread.Items {
+	for _, item := range thread.Items {
 		senderID, _ := item.UserID.Int64()
 		ts, _ := item.Timestamp.Int64()
 		msg := Message{
@@ -180,6 +503,10 @@ func (c *Client) GetMessages(threadID string, limit int) ([]Message, map[int64]s
 			Type:      item.ItemType,
 			Timestamp: time.Unix(0, ts*1000),
 			IsFromMe:  senderID == c.UserID(),
+
+			ClientContext: item.ClientContext,
+			PreviewURL:    item.PreviewImageURL(),
+			Shared:        item.SharedMedia(),
 		}
 
 		if name, ok := userMap[senderID]; ok {
@@ -188,14 +515,90 @@ func (c *Client) GetMessages(threadID string, limit int) ([]Message, map[int64]s
 			msg.SenderName = fmt.Sprintf("User %d", senderID)
 		}
 
-		if item.Reactions != nil && (len(item.Reactions.Likes) > 0 || len(item.Reactions.Emojis) > 0) {
-			msg.HasReaction = true
+		msg.Reactions = mapReactions(item.Reactions, userMap)
+
+		if item.RepliedToMessage != nil {
+			msg.ReplyTo = mapQuotedMessage(*item.RepliedToMessage, userMap)
 		}
 
 		messages = append(messages, msg)
 	}
 
-	return messages, userMap, nil
+	return messages, userMap
+}
+
+// WalkThreadHistory pages through a thread from cursor towards its first
+// message, calling fn with every page. fn returns false to stop early.
+// Pass the OldestCursor of the last page seen to resume an interrupted walk.
+func (c *Client) WalkThreadHistory(threadID string, cursor string, fn func(page *Thread) (bool, error)) error {
+	for {
+		threadResp, err := c.GetThread(threadID, cursor, 50)
+		if err != nil {
+			return err
+		}
+
+		page := &threadResp.Thread
+		more, err := fn(page)
+		if err != nil || !more {
+			return err
+		}
+
+		if !page.HasOlder || page.OldestCursor == "" || page.OldestCursor == cursor {
+			return nil
+		}
+		cursor = page.OldestCursor
+
+		// Stay well under the request rate Instagram tolerates
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+func mapReactions(reactions *Reactions, userMap map[int64]string) []Reaction {
+	if reactions == nil {
+		return nil
+	}
+
+	var out []Reaction
+	for _, like := range reactions.Likes {
+		out = append(out, newReaction("❤️", like.SenderID, like.Timestamp, userMap))
+	}
+	for _, emoji := range reactions.Emojis {
+		out = append(out, newReaction(emoji.Emoji, emoji.SenderID, emoji.Timestamp, userMap))
+	}
+
+	return out
+}
+
+func newReaction(emoji string, senderID json.Number, timestamp json.Number, userMap map[int64]string) Reaction {
+	id, _ := senderID.Int64()
+	ts, _ := timestamp.Int64()
+
+	r := Reaction{
+		Emoji:      emoji,
+		SenderID:   id,
+		SenderName: userMap[id],
+		Timestamp:  time.Unix(0, ts*1000),
+	}
+	if r.SenderName == "" {
+		r.SenderName = fmt.Sprintf("User %d", id)
+	}
+
+	return r
+}
+
+func mapQuotedMessage(item MessageItem, userMap map[int64]string) *QuotedMessage {
+	senderID, _ := item.UserID.Int64()
+
+	quoted := &QuotedMessage{
+		ID:         item.ItemID,
+		SenderName: userMap[senderID],
+		Text:       formatMessageContent(item),
+	}
+	if quoted.SenderName == "" {
+		quoted.SenderName = fmt.Sprintf("User %d", senderID)
+	}
+
+	return quoted
 }
 
 func formatMessagePreview(item MessageItem) string {
@@ -222,45 +625,50 @@ func formatMessagePreview(item MessageItem) string {
 	case "animated_media":
 		return "🎭 GIF"
 	default:
-		return fmt.Sprintf("[%s]", item.ItemType)
+		headline, _, _ := strings.Cut(formatMessageContent(item), "\n")
+		return headline
 	}
 }
 
-func formatMessageContent(item MessageItem) string {
-	switch item.ItemType {
-	case "text":
-		return item.Text
-	case "media_share":
-		return "📷 [Shared a post]"
-	case "voice_media":
-		return "🎤 [Voice message]"
-	case "visual_media":
-		return "📸 [Photo/Video]"
-	case "reel_share":
-		if item.ReelShare != nil && item.ReelShare.Text != "" {
-			return fmt.Sprintf("🎬 [Shared a reel] %s", item.ReelShare.Text)
-		}
-		return "🎬 [Shared a reel]"
-	case "story_share":
-		if item.StoryShare != nil && item.StoryShare.Text != "" {
-			return fmt.Sprintf("📖 [Shared a story] %s", item.StoryShare.Text)
-		}
-		return "📖 [Shared a story]"
-	case "link":
-		if item.Link != nil {
-			return fmt.Sprintf("🔗 %s", item.Link.LinkContext.LinkURL)
+// SearchText returns everything searchable in an item: its text, link URL
+// and title, and the captions of shared posts, reels and stories
+func (item *MessageItem) SearchText() string {
+	parts := []string{item.Text}
+
+	if item.Link != nil {
+		parts = append(parts, item.Link.Text, item.Link.LinkContext.LinkURL, item.Link.LinkContext.LinkTitle)
+	}
+
+	media := []*DirectMedia{item.MediaShare}
+	if item.ReelShare != nil {
+		parts = append(parts, item.ReelShare.Text)
+		media = append(media, item.ReelShare.Media)
+	}
+	if item.StoryShare != nil {
+		parts = append(parts, item.StoryShare.Text)
+		media = append(media, item.StoryShare.Media)
+	}
+	if item.Clip != nil {
+		media = append(media, &item.Clip.Clip)
+	}
+	if xma := item.xma(); xma != nil {
+		parts = append(parts, xma.HeaderTitleText, xma.TitleText, xma.CaptionBodyText, xma.TargetURL)
+	}
+
+	for _, m := range media {
+		if m != nil && m.Caption != nil {
+			parts = append(parts, m.Caption.Text)
 		}
-		return "🔗 [Shared a link]"
-	case "like":
-		return "❤️"
-	case "animated_media":
-		return "🎭 [GIF]"
-	case "placeholder":
-		return "[Message unavailable]"
-	default:
-		if item.Text != "" {
-			return item.Text
+	}
+
+	var b strings.Builder
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			if b.Len() > 0 {
+				b.WriteByte('\n')
+			}
+			b.WriteString(part)
 		}
-		return fmt.Sprintf("[%s]", item.ItemType)
 	}
+	return b.String()
 }
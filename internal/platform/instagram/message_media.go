@@ -0,0 +1,209 @@
+package instagram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/video"
+)
+
+// SendPhoto converts an image to JPEG, uploads it and shares it into a thread
+func (c *Client) SendPhoto(ctx context.Context, threadID string, path string, pr ProgressReporter) (*SendMessageResponse, error) {
+	reportProgress(pr, ProgressReport{Type: ProgressMessage, Step: "PREPARE", Message: "Converting photo..."})
+
+	info, tmpDir, err := video.PrepareImage(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare photo: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	reportProgress(pr, ProgressReport{Type: ProgressMessage, Step: "INIT", TotalBytes: fileSize(info.Path)})
+
+	uploadID, err := c.ruploadPhoto(ctx, info, ProgressMessage, pr)
+	if err != nil {
+		return nil, err
+	}
+
+	reportProgress(pr, ProgressReport{Type: ProgressMessage, Step: "CONFIG", Current: 1, Total: 1, Message: "Sending photo"})
+
+	clientContext := NewClientContext()
+	data := c.broadcastForm(threadID, clientContext)
+	data.Set("upload_id", uploadID)
+	data.Set("allow_full_aspect_ratio", "true")
+
+	body, err := c.postDirect("threads/broadcast/configure_photo/", data)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeSendResponse(body, clientContext)
+}
+
+// SendVideo transcodes a video with video.PrepareVideo and shares every
+// resulting segment into the thread in order
+func (c *Client) SendVideo(ctx context.Context, threadID string, path string, pr ProgressReporter) (*SendMessageResponse, error) {
+	reportProgress(pr, ProgressReport{Type: ProgressMessage, Step: "PREPARE", Message: "Transcoding video..."})
+
+	segments, tmpDir, err := video.PrepareVideo(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare video: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Segments are appended as their encodes finish, not in playback order
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].Path < segments[j].Path
+	})
+
+	var totalBytes int64
+	for _, seg := range segments {
+		totalBytes += fileSize(seg.Path)
+	}
+	reportProgress(pr, ProgressReport{Type: ProgressMessage, Step: "INIT", TotalBytes: totalBytes})
+
+	var resp *SendMessageResponse
+	for i, seg := range segments {
+		current, total := i+1, len(segments)
+
+		uploadID, err := c.ruploadVideo(ctx, seg, map[string]string{
+			"media_type":          "2",
+			"direct_v2":           "1",
+			"extract_cover_frame": "1",
+		}, ProgressMessage, pr, current, total)
+		if err != nil {
+			return resp, fmt.Errorf("upload failed for part %d: %w", current, err)
+		}
+
+		reportProgress(pr, ProgressReport{
+			Type:       ProgressMessage,
+			Step:       "CONFIG",
+			Current:    current,
+			Total:      total,
+			TotalBytes: fileSize(seg.Path),
+			Message:    "Sending video",
+		})
+
+		clientContext := NewClientContext()
+		data := c.broadcastForm(threadID, clientContext)
+		data.Set("upload_id", uploadID)
+		data.Set("video_result", "")
+
+		body, err := c.postDirectWhenTranscoded(ctx, "threads/broadcast/configure_video/", data)
+		if err != nil {
+			return resp, fmt.Errorf("send failed for part %d: %w", current, err)
+		}
+
+		if resp, err = decodeSendResponse(body, clientContext); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+// SendVoice transcodes a recording to AAC and shares it as a voice note
+func (c *Client) SendVoice(ctx context.Context, threadID string, path string, pr ProgressReporter) (*SendMessageResponse, error) {
+	reportProgress(pr, ProgressReport{Type: ProgressMessage, Step: "PREPARE", Message: "Encoding voice note..."})
+
+	audio, tmpDir, err := video.PrepareAudio(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare voice note: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	reportProgress(pr, ProgressReport{Type: ProgressMessage, Step: "INIT", TotalBytes: fileSize(audio.Path)})
+
+	info := video.VideoInfo{Path: audio.Path, Duration: audio.Duration}
+	uploadID, err := c.ruploadVideo(ctx, info, map[string]string{
+		"media_type":      "11",
+		"is_direct_voice": "1",
+	}, ProgressMessage, pr, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	reportProgress(pr, ProgressReport{Type: ProgressMessage, Step: "CONFIG", Current: 1, Total: 1, Message: "Sending voice note"})
+
+	waveform, _ := json.Marshal(audio.Waveform)
+
+	clientContext := NewClientContext()
+	data := c.broadcastForm(threadID, clientContext)
+	data.Set("upload_id", uploadID)
+	data.Set("waveform", string(waveform))
+	data.Set("waveform_sampling_frequency_hz", fmt.Sprintf("%d", video.WaveformHz))
+
+	body, err := c.postDirectWhenTranscoded(ctx, "threads/broadcast/share_voice/", data)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeSendResponse(body, clientContext)
+}
+
+// Instagram answers configure calls with "transcode not finished" until the
+// uploaded media is ready; after this many tries it is treated as stuck
+const (
+	transcodeRetryInterval = 5 * time.Second
+	maxTranscodeAttempts   = 60
+)
+
+// ErrTranscodeTimeout is returned when Instagram never finishes transcoding
+// an upload
+var ErrTranscodeTimeout = errors.New("transcode timed out")
+
+// postDirectWhenTranscoded retries a configure call until Instagram finishes
+// transcoding the uploaded media, giving up after maxTranscodeAttempts
+func (c *Client) postDirectWhenTranscoded(ctx context.Context, path string, data url.Values) ([]byte, error) {
+	for attempt := 1; ; attempt++ {
+		body, err := c.postDirect(path, data)
+		if err == nil {
+			return body, nil
+		}
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || !isTranscodePending(apiErr) {
+			return nil, err
+		}
+		if attempt >= maxTranscodeAttempts {
+			return nil, fmt.Errorf("%w after %s", ErrTranscodeTimeout, time.Duration(attempt)*transcodeRetryInterval)
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, ErrTranscodeTimeout
+			}
+			return nil, ctx.Err()
+		case <-time.After(transcodeRetryInterval):
+		}
+	}
+}
+
+func isTranscodePending(err *APIError) bool {
+	msg := strings.ToLower(err.Message)
+	if err.Response != nil {
+		msg += strings.ToLower(string(err.Response.RawBody))
+	}
+	return strings.Contains(msg, "transcode not finished") || strings.Contains(msg, "transcode_not_finished")
+}
+
+func reportProgress(pr ProgressReporter, report ProgressReport) {
+	if pr != nil {
+		pr.Report(report)
+	}
+}
+
+func fileSize(path string) int64 {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return stat.Size()
+}
@@ -20,8 +20,21 @@ type Thread struct {
 	UnseenCount       int           `json:"unseen_count"`
 	HasNewer          bool          `json:"has_newer"`
 	HasOlder          bool          `json:"has_older"`
+	OldestCursor      string        `json:"oldest_cursor,omitempty"`
+	NewestCursor      string        `json:"newest_cursor,omitempty"`
 	ViewerID          json.Number   `json:"viewer_id"`
 	Inviter           *ThreadUser   `json:"inviter,omitempty"`
+	IsGroup           bool          `json:"is_group"`
+	AdminUserIDs      []json.Number `json:"admin_user_ids,omitempty"`
+
+	// LastSeenAt is keyed by user ID
+	LastSeenAt map[string]SeenMarker `json:"last_seen_at,omitempty"`
+}
+
+// SeenMarker is the last item a participant has read and when
+type SeenMarker struct {
+	ItemID    string      `json:"item_id"`
+	Timestamp json.Number `json:"timestamp"`
 }
 
 type ThreadUser struct {
@@ -33,6 +46,12 @@ type ThreadUser struct {
 	ProfilePicID     string         `json:"profile_pic_id,omitempty"`
 	IsVerified       bool           `json:"is_verified"`
 	FriendshipStatus map[string]any `json:"friendship_status,omitempty"`
+
+	// HasAnonymousPicture is set for accounts still on the default picture
+	HasAnonymousPicture bool `json:"has_anonymous_profile_picture,omitempty"`
+
+	// FollowerCount is only filled in by GetUserByUsername
+	FollowerCount int `json:"follower_count,omitempty"`
 }
 
 type MessageItem struct {
@@ -43,47 +62,128 @@ type MessageItem struct {
 	Text          string      `json:"text,omitempty"`
 	ClientContext string      `json:"client_context,omitempty"`
 
-	MediaShare  *MediaShare  `json:"media_share,omitempty"`
+	MediaShare  *DirectMedia `json:"media_share,omitempty"`
 	VoiceMedia  *VoiceMedia  `json:"voice_media,omitempty"`
 	VisualMedia *VisualMedia `json:"visual_media,omitempty"`
 	ReelShare   *ReelShare   `json:"reel_share,omitempty"`
 	StoryShare  *StoryShare  `json:"story_share,omitempty"`
+	Clip        *ClipShare   `json:"clip,omitempty"`
 	Link        *LinkShare   `json:"link,omitempty"`
 
+	AnimatedMedia  *AnimatedMedia `json:"animated_media,omitempty"`
+	Profile        *ThreadUser    `json:"profile,omitempty"`
+	ActionLog      *ActionLog     `json:"action_log,omitempty"`
+	VideoCallEvent *ActionLog     `json:"video_call_event,omitempty"`
+	Placeholder    *Placeholder   `json:"placeholder,omitempty"`
+
+	// Extensible message attachments (xma_*) replace the older share types
+	XMAShare      []XMA `json:"xma_share,omitempty"`
+	XMAMediaShare []XMA `json:"xma_media_share,omitempty"`
+	XMAReelShare  []XMA `json:"xma_reel_share,omitempty"`
+	XMAStoryShare []XMA `json:"xma_story_share,omitempty"`
+	XMAClip       []XMA `json:"xma_clip,omitempty"`
+	XMALink       []XMA `json:"xma_link,omitempty"`
+	GenericXMA    []XMA `json:"generic_xma,omitempty"`
+
 	Reactions *Reactions `json:"reactions,omitempty"`
 
 	RepliedToMessage *MessageItem `json:"replied_to_message,omitempty"`
 }
 
-type MediaShare struct {
-	MediaType int         `json:"media_type"`
-	ID        string      `json:"id"`
-	Code      string      `json:"code"`
-	User      *ThreadUser `json:"user,omitempty"`
+// DirectMedia is a photo, video, carousel or audio clip attached to a
+// direct item, either sent in the thread or shared from a post
+type DirectMedia struct {
+	ID             string         `json:"id"`
+	MediaType      int            `json:"media_type"`
+	ProductType    string         `json:"product_type,omitempty"`
+	Code           string         `json:"code,omitempty"`
+	ImageVersions2 ImageVersions  `json:"image_versions2"`
+	VideoVersions  []VideoVersion `json:"video_versions,omitempty"`
+	VideoDuration  float64        `json:"video_duration,omitempty"`
+	CarouselMedia  []DirectMedia  `json:"carousel_media,omitempty"`
+	OriginalWidth  int            `json:"original_width,omitempty"`
+	OriginalHeight int            `json:"original_height,omitempty"`
+	Caption        *Caption       `json:"caption,omitempty"`
+	User           *ThreadUser    `json:"user,omitempty"`
+	Audio          *DirectAudio   `json:"audio,omitempty"`
+	ExpiringAt     json.Number    `json:"expiring_at,omitempty"`
+}
+
+type DirectAudio struct {
+	AudioSrc                    string    `json:"audio_src"`
+	Duration                    int       `json:"duration"` // milliseconds
+	WaveformData                []float64 `json:"waveform_data,omitempty"`
+	WaveformSamplingFrequencyHz int       `json:"waveform_sampling_frequency_hz,omitempty"`
 }
 
 type VoiceMedia struct {
-	Media struct {
-		ID  string `json:"id"`
-		URL string `json:"audio_src"`
-	} `json:"media"`
+	Media DirectMedia `json:"media"`
 }
 
 type VisualMedia struct {
-	MediaType int    `json:"media_type"`
-	URL       string `json:"url_expire_at_secs,omitempty"`
+	Media           DirectMedia `json:"media"`
+	URLExpireAtSecs json.Number `json:"url_expire_at_secs,omitempty"`
+	SeenCount       int         `json:"seen_count,omitempty"`
+	ViewMode        string      `json:"view_mode,omitempty"`
 }
 
 type ReelShare struct {
-	Text     string      `json:"text,omitempty"`
-	ReelType string      `json:"type,omitempty"`
-	Media    *MediaShare `json:"media,omitempty"`
+	Text     string       `json:"text,omitempty"`
+	ReelType string       `json:"type,omitempty"`
+	Media    *DirectMedia `json:"media,omitempty"`
 }
 
 type StoryShare struct {
-	Text            string      `json:"text,omitempty"`
-	Media           *MediaShare `json:"media,omitempty"`
-	IsReelPersisted bool        `json:"is_reel_persisted"`
+	Text            string       `json:"text,omitempty"`
+	Media           *DirectMedia `json:"media,omitempty"`
+	IsReelPersisted bool         `json:"is_reel_persisted"`
+
+	// Set instead of Media when the story expired or isn't visible to you
+	Title   string `json:"title,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type ClipShare struct {
+	Clip DirectMedia `json:"clip"`
+}
+
+// Attachment is one downloadable file carried by a message item
+type Attachment struct {
+	Kind   string // photo, video or voice
+	URL    string
+	Ext    string
+	Shared bool // shared from a post, reel or story rather than sent directly
+	Index  int  // position inside a carousel, 0 otherwise
+}
+
+type AnimatedMedia struct {
+	IsSticker bool `json:"is_sticker"`
+	Images    struct {
+		FixedHeight struct {
+			URL string `json:"url"`
+		} `json:"fixed_height"`
+	} `json:"images"`
+}
+
+// ActionLog is a thread event such as a rename, a member joining or a call
+type ActionLog struct {
+	Description string `json:"description"`
+}
+
+type Placeholder struct {
+	Title   string `json:"title"`
+	Message string `json:"message"`
+}
+
+// XMA is an extensible message attachment: a shared post, reel, story or
+// link card rendered from server-provided text
+type XMA struct {
+	TargetURL       string `json:"target_url,omitempty"`
+	HeaderTitleText string `json:"header_title_text,omitempty"`
+	TitleText       string `json:"title_text,omitempty"`
+	SubtitleText    string `json:"subtitle_text,omitempty"`
+	CaptionBodyText string `json:"caption_body_text,omitempty"`
+	PreviewURL      string `json:"preview_url,omitempty"`
 }
 
 type LinkShare struct {
@@ -119,6 +219,7 @@ type InboxResponse struct {
 		BlendedInboxEnabled  bool        `json:"blended_inbox_enabled"`
 	} `json:"inbox"`
 	SeqID                 json.Number `json:"seq_id"`
+	SnapshotAtMs          json.Number `json:"snapshot_at_ms"`
 	PendingRequestsTotal  int         `json:"pending_requests_total"`
 	HasPendingTopRequests bool        `json:"has_pending_top_requests"`
 	Status                string      `json:"status"`
@@ -140,24 +241,114 @@ type SendMessageResponse struct {
 	} `json:"payload"`
 }
 
+// SendOptions controls how an outgoing message is sent
+type SendOptions struct {
+	// ClientContext is reused on retries so Instagram can drop duplicates
+	ClientContext string
+
+	// ReplyToItemID and ReplyToClientContext quote an earlier message
+	ReplyToItemID        string
+	ReplyToClientContext string
+}
+
+// SendState is the delivery state of a locally sent message
+type SendState string
+
+const (
+	SendPending SendState = "pending"
+	SendSent    SendState = "sent"
+	SendFailed  SendState = "failed"
+
+	// SendQueued messages wait in the outbox until their time or until the
+	// network is back
+	SendQueued SendState = "queued"
+)
+
 type Conversation struct {
-	ThreadID      string
-	Title         string
-	Users         []string
-	LastMessage   string
-	LastMessageAt time.Time
-	UnreadCount   int
-	IsMuted       bool
-	IsPinned      bool
+	ThreadID      string    `json:"thread_id"`
+	Title         string    `json:"title"`
+	Users         []string  `json:"users"`
+	LastMessage   string    `json:"last_message"`
+	LastMessageAt time.Time `json:"last_message_at"`
+	UnreadCount   int       `json:"unread_count"`
+	IsMuted       bool      `json:"muted"`
+	IsPinned      bool      `json:"pinned"`
+	IsPending     bool      `json:"pending"`
+	IsGroup       bool      `json:"group"`
+	Admins        []string  `json:"admins,omitempty"`
+}
+
+// ConversationList is one page of an inbox folder. OldestCursor fetches the
+// next page while HasOlder is set.
+type ConversationList struct {
+	Conversations   []Conversation
+	UnseenCount     int
+	PendingRequests int
+	OldestCursor    string
+	HasOlder        bool
 }
 
 type Message struct {
-	ID          string
-	SenderID    int64
-	SenderName  string
-	Text        string
-	Type        string
-	Timestamp   time.Time
-	IsFromMe    bool
-	HasReaction bool
+	ID         string         `json:"id"`
+	SenderID   int64          `json:"sender_id"`
+	SenderName string         `json:"sender"`
+	Text       string         `json:"text"`
+	Type       string         `json:"type"`
+	Timestamp  time.Time      `json:"timestamp"`
+	IsFromMe   bool           `json:"from_me"`
+	Reactions  []Reaction     `json:"reactions,omitempty"`
+	ReplyTo    *QuotedMessage `json:"reply_to,omitempty"`
+
+	ClientContext string    `json:"client_context,omitempty"`
+	State         SendState `json:"state,omitempty"`
+
+	// PreviewURL is a small image of the attached or shared media
+	PreviewURL string `json:"preview_url,omitempty"`
+
+	// SeenBy lists who read up to this message, filled in by the views
+	SeenBy []string `json:"seen_by,omitempty"`
+
+	// Shared is the post, reel or story the message passes on, if any
+	Shared *SharedMedia `json:"shared,omitempty"`
+}
+
+// Kinds of SharedMedia
+const (
+	SharedPost  = "post"
+	SharedClip  = "clip"
+	SharedStory = "story"
+)
+
+// SharedMedia is a post, reel or story shared into a thread, by the IDs
+// needed to share it again
+type SharedMedia struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+
+	// MediaType is photo, video or carousel, as the share endpoints name it
+	MediaType string `json:"media_type,omitempty"`
+
+	// ReelID is the story owner's user ID
+	ReelID string `json:"reel_id,omitempty"`
+}
+
+// SeenReceipt is how far another participant has read a thread
+type SeenReceipt struct {
+	Username  string    `json:"username"`
+	ItemID    string    `json:"item_id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type Reaction struct {
+	Emoji      string    `json:"emoji"`
+	SenderID   int64     `json:"sender_id"`
+	SenderName string    `json:"sender"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// QuotedMessage is the message a reply points at
+type QuotedMessage struct {
+	ID         string `json:"id"`
+	SenderName string `json:"sender"`
+	Text       string `json:"text"`
 }
@@ -0,0 +1,554 @@
+package instagram
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/mqtt"
+)
+
+// DefaultRealtimeAddr is Instagram's MQTT edge. A tcp:// address speaks plain
+// MQTT 3.1.1 instead of MQTToT, so a stock broker can stand in for testing.
+const DefaultRealtimeAddr = "tls://edge-mqtt.facebook.com:443"
+
+// MQTToT topics are addressed by ID
+const (
+	topicPubsub              = "88"
+	topicSendMessageResponse = "133"
+	topicSubIris             = "134"
+	topicSubIrisResponse     = "135"
+	topicMessageSync         = "146"
+	topicRealtimeSub         = "149"
+)
+
+var realtimeTopics = []string{topicPubsub, topicSendMessageResponse, topicSubIrisResponse, topicMessageSync, topicRealtimeSub}
+
+const directTypingSubscription = "1/graphqlsubscriptions/17867973967082385/"
+
+var (
+	itemPathPattern     = regexp.MustCompile(`^/direct_v2/threads/([^/]+)/items/([^/]+)$`)
+	reactionPathPattern = regexp.MustCompile(`^/direct_v2/threads/([^/]+)/items/([^/]+)/reactions/(likes|emojis)/(\d+)`)
+	typingPathPattern   = regexp.MustCompile(`^/direct_v2/threads/([^/]+)/activity_indicator_id/`)
+	seenPathPattern     = regexp.MustCompile(`^/direct_v2/threads/([^/]+)/participants/(\d+)/has_seen$`)
+	threadPathPattern   = regexp.MustCompile(`^/direct_v2/(?:inbox/)?threads/([^/]+)$`)
+)
+
+// Realtime is a live connection to the MQTT edge delivering direct events
+type Realtime struct {
+	c      *Client
+	conn   *mqtt.Conn
+	events chan Event
+
+	mu      sync.Mutex
+	threads map[string]Thread
+	err     error
+}
+
+type irisPatch struct {
+	Event string      `json:"event"`
+	Data  []irisOp    `json:"data"`
+	SeqID json.Number `json:"seq_id"`
+}
+
+type irisOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+type realtimeReaction struct {
+	SenderID  json.Number `json:"sender_id"`
+	Timestamp json.Number `json:"timestamp"`
+	Emoji     string      `json:"emoji"`
+}
+
+type realtimeActivity struct {
+	SenderID       json.Number `json:"sender_id"`
+	Timestamp      json.Number `json:"timestamp"`
+	ActivityStatus int         `json:"activity_status"`
+}
+
+type realtimeSeen struct {
+	ItemID    string      `json:"item_id"`
+	Timestamp json.Number `json:"timestamp"`
+}
+
+// ConnectRealtime logs in to the MQTT edge at addr and subscribes to message
+// sync and typing indicators. Events stop, and the channel closes, when the
+// connection drops or ctx is cancelled.
+func (c *Client) ConnectRealtime(ctx context.Context, addr string) (*Realtime, error) {
+	inbox, err := c.GetInbox("", 20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch inbox snapshot: %w", err)
+	}
+
+	conn, err := mqtt.Dial(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(addr, "tcp://") || strings.HasPrefix(addr, "mqtt://") {
+		err = c.connectPlain(conn)
+	} else {
+		err = c.connectMQTToT(conn)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if err := c.subscribeRealtime(conn, inbox); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	r := &Realtime{
+		c:       c,
+		conn:    conn,
+		events:  make(chan Event, 64),
+		threads: make(map[string]Thread, len(inbox.Inbox.Threads)),
+	}
+	for _, thread := range inbox.Inbox.Threads {
+		r.threads[thread.ThreadID] = thread
+	}
+
+	go r.run(ctx)
+
+	return r, nil
+}
+
+func (c *Client) realtimeClientID() string {
+	id := strings.ReplaceAll(c.UUID, "-", "")
+	if len(id) > 20 {
+		id = id[:20]
+	}
+	return id
+}
+
+// connectPlain logs in with a standard CONNECT. Unlike the real edge, a plain
+// broker needs explicit subscriptions to the topic IDs.
+func (c *Client) connectPlain(conn *mqtt.Conn) error {
+	err := conn.Connect(mqtt.ConnectOptions{
+		ClientID:  c.realtimeClientID(),
+		Username:  strconv.FormatInt(c.UserID(), 10),
+		Password:  "sessionid=" + c.GetSessionID(),
+		KeepAlive: 60 * time.Second,
+	})
+	if err != nil {
+		return err
+	}
+
+	return conn.Subscribe(realtimeTopics...)
+}
+
+func (c *Client) connectMQTToT(conn *mqtt.Conn) error {
+	payload, err := c.realtimeConnectPayload()
+	if err != nil {
+		return err
+	}
+
+	return conn.Connect(mqtt.ConnectOptions{
+		ProtocolName:  "MQTToT",
+		ProtocolLevel: 3,
+		KeepAlive:     60 * time.Second,
+		Payload:       payload,
+	})
+}
+
+// realtimeConnectPayload builds the zlib-compressed Thrift login MQTToT
+// expects in place of the usual CONNECT fields
+func (c *Client) realtimeConnectPayload() ([]byte, error) {
+	appID, _ := strconv.ParseInt(IGAppID, 10, 64)
+
+	userAgent := c.UserAgent
+	appVersion := ""
+	if c.DeviceSettings != nil {
+		appVersion = c.DeviceSettings.AppVersion
+	}
+
+	info := map[string]string{
+		"app_version":             appVersion,
+		"X-IG-Capabilities":       "3brTvw==",
+		"User-Agent":              userAgent,
+		"Accept-Language":         "en-US",
+		"platform":                "android",
+		"ig_mqtt_route":           "django",
+		"auth_cache_enabled":      "0",
+		"everclear_subscriptions": "{}",
+	}
+	keys := make([]string, 0, len(info))
+	for k := range info {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	w := &mqtt.ThriftWriter{}
+	w.String(1, c.realtimeClientID())
+
+	w.BeginStruct(4)
+	w.I64(1, c.UserID())
+	w.String(2, userAgent)
+	w.I64(3, 183)
+	w.I64(4, 0)
+	w.I32(5, 1)
+	w.Bool(6, false)
+	w.Bool(7, true)
+	w.String(8, c.UUID)
+	w.Bool(9, true)
+	w.I32(10, 1)
+	w.I32(11, 0)
+	w.I64(12, time.Now().UnixMilli()&0xffffffff)
+	w.I32List(14, []int32{88, 133, 135, 146, 149})
+	w.String(15, "cookie_auth")
+	w.I64(16, appID)
+	w.String(20, "")
+	w.Byte(21, 3)
+	w.EndStruct()
+
+	w.String(5, "sessionid="+c.GetSessionID())
+	w.StringMap(10, info, keys)
+	w.EndStruct()
+
+	return deflate(w.Bytes())
+}
+
+// subscribeRealtime starts message sync from the inbox snapshot and asks for
+// typing indicators
+func (c *Client) subscribeRealtime(conn *mqtt.Conn, inbox *InboxResponse) error {
+	seqID, _ := inbox.SeqID.Int64()
+	snapshotAt, _ := inbox.SnapshotAtMs.Int64()
+	if snapshotAt == 0 {
+		snapshotAt = time.Now().UnixMilli()
+	}
+
+	iris, err := json.Marshal(map[string]any{
+		"seq_id":               seqID,
+		"snapshot_at_ms":       snapshotAt,
+		"snapshot_app_version": "message",
+	})
+	if err != nil {
+		return err
+	}
+
+	typingInput, err := json.Marshal(map[string]any{
+		"input_data": map[string]string{"user_id": strconv.FormatInt(c.UserID(), 10)},
+	})
+	if err != nil {
+		return err
+	}
+
+	subs, err := json.Marshal(map[string][]string{
+		"sub": {directTypingSubscription + string(typingInput)},
+	})
+	if err != nil {
+		return err
+	}
+
+	for topic, data := range map[string][]byte{topicSubIris: iris, topicRealtimeSub: subs} {
+		payload, err := deflate(data)
+		if err != nil {
+			return err
+		}
+		if err := conn.Publish(topic, payload, 1); err != nil {
+			return fmt.Errorf("failed to subscribe: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// realtimeOpBuffer is how many patch operations can wait for their thread
+// lookup before reading from the connection waits too
+const realtimeOpBuffer = 256
+
+// run reads the connection and turns its patches into events. Decoding an
+// operation can fetch a thread over HTTP, so that happens on a goroutine of
+// its own and a slow request never holds up reading or keepalives.
+func (r *Realtime) run(ctx context.Context) {
+	ops := make(chan irisOp, realtimeOpBuffer)
+	resolved := make(chan struct{})
+	go func() {
+		defer close(resolved)
+		r.resolve(ctx, ops)
+	}()
+
+	err := r.conn.Run(ctx, func(msg mqtt.Message) {
+		for _, op := range r.decode(msg) {
+			select {
+			case ops <- op:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+
+	close(ops)
+	<-resolved
+
+	r.mu.Lock()
+	r.err = err
+	r.mu.Unlock()
+
+	close(r.events)
+}
+
+// resolve decodes operations into events until ops is closed
+func (r *Realtime) resolve(ctx context.Context, ops <-chan irisOp) {
+	for op := range ops {
+		if ctx.Err() != nil {
+			continue
+		}
+
+		e, ok := r.decodeOp(op)
+		if !ok {
+			continue
+		}
+		e.Source = "realtime"
+
+		select {
+		case r.events <- e:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// Events delivers events until the connection ends
+func (r *Realtime) Events() <-chan Event {
+	return r.events
+}
+
+// Err returns why the connection ended, once Events is closed
+func (r *Realtime) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+func (r *Realtime) Close() error {
+	return r.conn.Close()
+}
+
+// decode unpacks the patch operations of a message without looking
+// anything up
+func (r *Realtime) decode(msg mqtt.Message) []irisOp {
+	switch msg.Topic {
+	case topicMessageSync, topicRealtimeSub, topicPubsub:
+	default:
+		return nil
+	}
+
+	data := inflate(msg.Payload)
+
+	// Realtime subscriptions wrap their JSON in a Thrift {topic, payload}
+	if len(data) > 0 && data[0] != '{' && data[0] != '[' {
+		fields, err := mqtt.ReadThriftStrings(data)
+		if err != nil || fields[2] == "" {
+			if r.c.Debug {
+				fmt.Printf("[DEBUG] Undecodable realtime payload on %s\n", msg.Topic)
+			}
+			return nil
+		}
+		data = []byte(fields[2])
+	}
+
+	var patches []irisPatch
+	if len(data) > 0 && data[0] == '[' {
+		if err := json.Unmarshal(data, &patches); err != nil {
+			return nil
+		}
+	} else {
+		var patch irisPatch
+		if err := json.Unmarshal(data, &patch); err != nil {
+			return nil
+		}
+		patches = append(patches, patch)
+	}
+
+	var ops []irisOp
+	for _, patch := range patches {
+		ops = append(ops, patch.Data...)
+	}
+	return ops
+}
+
+func (r *Realtime) decodeOp(op irisOp) (Event, bool) {
+	if op.Op != "add" && op.Op != "replace" {
+		return Event{}, false
+	}
+
+	value := unwrapValue(op.Value)
+
+	if m := itemPathPattern.FindStringSubmatch(op.Path); m != nil && op.Op == "add" {
+		var item MessageItem
+		if err := json.Unmarshal(value, &item); err != nil {
+			return Event{}, false
+		}
+		thread := r.thread(m[1])
+		return itemEvent(r.c, m[1], ThreadTitle(thread), item, eventUserMap(r.c, thread)), true
+	}
+
+	if m := reactionPathPattern.FindStringSubmatch(op.Path); m != nil {
+		var reaction realtimeReaction
+		if err := json.Unmarshal(value, &reaction); err != nil {
+			return Event{}, false
+		}
+		if reaction.SenderID == "" {
+			reaction.SenderID = json.Number(m[4])
+		}
+
+		emoji := reaction.Emoji
+		if m[3] == "likes" || emoji == "" {
+			emoji = "❤️"
+		}
+
+		thread := r.thread(m[1])
+		react := newReaction(emoji, reaction.SenderID, reaction.Timestamp, eventUserMap(r.c, thread))
+		if react.Timestamp.Unix() <= 0 {
+			react.Timestamp = time.Now()
+		}
+
+		return Event{
+			Type:        EventReaction,
+			ThreadID:    m[1],
+			ThreadTitle: ThreadTitle(thread),
+			ItemID:      m[2],
+			SenderID:    react.SenderID,
+			SenderName:  react.SenderName,
+			Emoji:       react.Emoji,
+			FromMe:      react.SenderID == r.c.UserID(),
+			Timestamp:   react.Timestamp,
+		}, true
+	}
+
+	if m := typingPathPattern.FindStringSubmatch(op.Path); m != nil {
+		var activity realtimeActivity
+		if err := json.Unmarshal(value, &activity); err != nil || activity.ActivityStatus != 1 {
+			return Event{}, false
+		}
+		return r.participantEvent(EventTyping, m[1], activity.SenderID, ""), true
+	}
+
+	if m := seenPathPattern.FindStringSubmatch(op.Path); m != nil {
+		var seen realtimeSeen
+		if err := json.Unmarshal(value, &seen); err != nil {
+			return Event{}, false
+		}
+		return r.participantEvent(EventSeen, m[1], json.Number(m[2]), seen.ItemID), true
+	}
+
+	if m := threadPathPattern.FindStringSubmatch(op.Path); m != nil && op.Op == "add" {
+		var thread Thread
+		if err := json.Unmarshal(value, &thread); err != nil || !thread.Pending {
+			return Event{}, false
+		}
+		thread.ThreadID = m[1]
+
+		r.mu.Lock()
+		r.threads[thread.ThreadID] = thread
+		r.mu.Unlock()
+
+		e := Event{Type: EventRequest, ThreadID: thread.ThreadID, ThreadTitle: ThreadTitle(thread), Timestamp: time.Now()}
+		if thread.Inviter != nil {
+			e.SenderID, _ = thread.Inviter.Pk.Int64()
+			e.SenderName = thread.Inviter.Username
+		}
+		return e, true
+	}
+
+	return Event{}, false
+}
+
+func (r *Realtime) participantEvent(kind EventType, threadID string, senderID json.Number, itemID string) Event {
+	thread := r.thread(threadID)
+	id, _ := senderID.Int64()
+
+	e := Event{
+		Type:        kind,
+		ThreadID:    threadID,
+		ThreadTitle: ThreadTitle(thread),
+		ItemID:      itemID,
+		SenderID:    id,
+		SenderName:  eventUserMap(r.c, thread)[id],
+		FromMe:      id == r.c.UserID(),
+		Timestamp:   time.Now(),
+	}
+	if e.SenderName == "" {
+		e.SenderName = fmt.Sprintf("User %d", id)
+	}
+
+	return e
+}
+
+// thread returns what's known about a thread, fetching it the first time it
+// shows up outside the inbox snapshot
+func (r *Realtime) thread(threadID string) Thread {
+	r.mu.Lock()
+	thread, ok := r.threads[threadID]
+	r.mu.Unlock()
+
+	if ok {
+		return thread
+	}
+
+	thread = Thread{ThreadID: threadID}
+	if threadResp, err := r.c.GetThread(threadID, "", 1); err == nil {
+		thread = threadResp.Thread
+		thread.Items = nil
+	}
+
+	r.mu.Lock()
+	r.threads[threadID] = thread
+	r.mu.Unlock()
+
+	return thread
+}
+
+// unwrapValue handles patch values sent as JSON-encoded strings
+func unwrapValue(raw json.RawMessage) json.RawMessage {
+	var s string
+	if len(raw) > 0 && raw[0] == '"' && json.Unmarshal(raw, &s) == nil {
+		return json.RawMessage(s)
+	}
+	return raw
+}
+
+func deflate(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// inflate decompresses zlib payloads and passes anything else through
+func inflate(data []byte) []byte {
+	if len(data) < 2 || data[0] != 0x78 {
+		return data
+	}
+
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return data
+	}
+	defer r.Close()
+
+	out, err := io.ReadAll(r)
+	if err != nil {
+		return data
+	}
+	return out
+}
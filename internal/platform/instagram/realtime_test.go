@@ -0,0 +1,286 @@
+package instagram
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/mqtt"
+)
+
+// newTestRealtime returns a Realtime for user 1 (@me) that already knows
+// thread t1 with @alice, so decoding never reaches the API
+func newTestRealtime(conn *mqtt.Conn) *Realtime {
+	c := NewClient()
+	c.Username = "me"
+	c.Cookies["ds_user_id"] = "1"
+
+	return &Realtime{
+		c:      c,
+		conn:   conn,
+		events: make(chan Event, 64),
+		threads: map[string]Thread{
+			"t1": {ThreadID: "t1", Users: []ThreadUser{{Pk: "2", Username: "alice"}}},
+		},
+	}
+}
+
+// patch builds an iris patch with one operation, its value JSON-encoded as
+// a string the way message sync sends it
+func patch(t *testing.T, op string, path string, value any) []byte {
+	t.Helper()
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(irisPatch{
+		Event: "patch",
+		Data:  []irisOp{{Op: op, Path: path, Value: json.RawMessage(mustJSON(t, string(raw)))}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func mustJSON(t *testing.T, v any) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func compressed(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	out, err := deflate(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// thriftWrapped wraps data in the {topic, payload} struct realtime
+// subscriptions use
+func thriftWrapped(data []byte) []byte {
+	w := &mqtt.ThriftWriter{}
+	w.String(1, "/ig_realtime_sub")
+	w.String(2, string(data))
+	w.EndStruct()
+	return w.Bytes()
+}
+
+var textItem = map[string]any{
+	"item_id":   "i1",
+	"user_id":   2,
+	"timestamp": "1700000000000000",
+	"item_type": "text",
+	"text":      "hi",
+}
+
+func TestRealtimeDecode(t *testing.T) {
+	ownItem := map[string]any{"item_id": "i2", "user_id": 1, "timestamp": "1700000000000000", "item_type": "text", "text": "yo"}
+
+	tests := []struct {
+		name    string
+		topic   string
+		payload []byte
+		want    []Event
+	}{
+		{
+			name:    "message",
+			topic:   topicMessageSync,
+			payload: patch(t, "add", "/direct_v2/threads/t1/items/i1", textItem),
+			want: []Event{{Type: EventMessage, ThreadID: "t1", ThreadTitle: "alice", ItemID: "i1", ItemType: "text",
+				SenderID: 2, SenderName: "alice", Text: "hi", Timestamp: time.UnixMicro(1700000000000000)}},
+		},
+		{
+			name:    "compressed own message",
+			topic:   topicMessageSync,
+			payload: compressed(t, patch(t, "add", "/direct_v2/threads/t1/items/i2", ownItem)),
+			want: []Event{{Type: EventMessage, ThreadID: "t1", ThreadTitle: "alice", ItemID: "i2", ItemType: "text",
+				SenderID: 1, SenderName: "me", Text: "yo", FromMe: true, Timestamp: time.UnixMicro(1700000000000000)}},
+		},
+		{
+			name:    "batch of patches",
+			topic:   topicMessageSync,
+			payload: []byte("[" + string(patch(t, "add", "/direct_v2/threads/t1/items/i1", textItem)) + "," + string(patch(t, "add", "/direct_v2/threads/t1/items/i2", ownItem)) + "]"),
+			want: []Event{
+				{Type: EventMessage, ThreadID: "t1", ItemID: "i1"},
+				{Type: EventMessage, ThreadID: "t1", ItemID: "i2"},
+			},
+		},
+		{
+			name:  "reaction",
+			topic: topicMessageSync,
+			payload: patch(t, "add", "/direct_v2/threads/t1/items/i1/reactions/emojis/2",
+				map[string]any{"emoji": "🔥", "timestamp": "1700000000000000"}),
+			want: []Event{{Type: EventReaction, ThreadID: "t1", ThreadTitle: "alice", ItemID: "i1",
+				SenderID: 2, SenderName: "alice", Emoji: "🔥", Timestamp: time.UnixMicro(1700000000000000)}},
+		},
+		{
+			name:  "typing in a thrift wrapper",
+			topic: topicRealtimeSub,
+			payload: compressed(t, thriftWrapped(patch(t, "add", "/direct_v2/threads/t1/activity_indicator_id/5",
+				map[string]any{"sender_id": "2", "activity_status": 1}))),
+			want: []Event{{Type: EventTyping, ThreadID: "t1", ThreadTitle: "alice", SenderID: 2, SenderName: "alice"}},
+		},
+		{
+			name:  "typing stopped",
+			topic: topicRealtimeSub,
+			payload: thriftWrapped(patch(t, "add", "/direct_v2/threads/t1/activity_indicator_id/5",
+				map[string]any{"sender_id": "2", "activity_status": 0})),
+		},
+		{
+			name:    "seen",
+			topic:   topicMessageSync,
+			payload: patch(t, "replace", "/direct_v2/threads/t1/participants/2/has_seen", map[string]any{"item_id": "i1"}),
+			want:    []Event{{Type: EventSeen, ThreadID: "t1", ThreadTitle: "alice", ItemID: "i1", SenderID: 2, SenderName: "alice"}},
+		},
+		{
+			name:  "message request",
+			topic: topicMessageSync,
+			payload: patch(t, "add", "/direct_v2/threads/t9",
+				map[string]any{"pending": true, "inviter": map[string]any{"pk": 3, "username": "bob"}}),
+			want: []Event{{Type: EventRequest, ThreadID: "t9", ThreadTitle: "t9", SenderID: 3, SenderName: "bob"}},
+		},
+		{
+			name:    "removed item",
+			topic:   topicMessageSync,
+			payload: patch(t, "remove", "/direct_v2/threads/t1/items/i1", nil),
+		},
+		{
+			name:    "other topic",
+			topic:   topicSendMessageResponse,
+			payload: patch(t, "add", "/direct_v2/threads/t1/items/i1", textItem),
+		},
+		{
+			name:    "garbage",
+			topic:   topicRealtimeSub,
+			payload: []byte{0x1c, 0x00},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRealtime(nil)
+			got := decodeEvents(r, mqtt.Message{Topic: tt.topic, Payload: tt.payload})
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d events %+v, want %d", len(got), got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				checkEvent(t, got[i], want)
+			}
+		})
+	}
+}
+
+// decodeEvents runs a message through both decoding steps, as the read loop
+// and the resolver do
+func decodeEvents(r *Realtime, msg mqtt.Message) []Event {
+	ops := make(chan irisOp, realtimeOpBuffer)
+	for _, op := range r.decode(msg) {
+		ops <- op
+	}
+	close(ops)
+
+	r.resolve(context.Background(), ops)
+	close(r.events)
+
+	var events []Event
+	for e := range r.events {
+		events = append(events, e)
+	}
+	return events
+}
+
+// checkEvent compares the fields set in want. Events stamped with the time
+// they arrived are only checked for being recent.
+func checkEvent(t *testing.T, got Event, want Event) {
+	t.Helper()
+
+	if got.Source != "realtime" {
+		t.Errorf("source = %q, want realtime", got.Source)
+	}
+	if got.Type != want.Type || got.ThreadID != want.ThreadID || got.ItemID != want.ItemID {
+		t.Errorf("got %s %s/%s, want %s %s/%s", got.Type, got.ThreadID, got.ItemID, want.Type, want.ThreadID, want.ItemID)
+	}
+	if want.SenderName == "" {
+		return
+	}
+
+	if got.ThreadTitle != want.ThreadTitle || got.SenderID != want.SenderID || got.SenderName != want.SenderName ||
+		got.Text != want.Text || got.Emoji != want.Emoji || got.FromMe != want.FromMe || got.ItemType != want.ItemType {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+
+	if want.Timestamp.IsZero() {
+		if time.Since(got.Timestamp) > time.Minute {
+			t.Errorf("timestamp = %s, want about now", got.Timestamp)
+		}
+	} else if !got.Timestamp.Equal(want.Timestamp) {
+		t.Errorf("timestamp = %s, want %s", got.Timestamp, want.Timestamp)
+	}
+}
+
+// publishPacket encodes a QoS 0 PUBLISH the way a broker sends it
+func publishPacket(topic string, payload []byte) []byte {
+	body := append([]byte{byte(len(topic) >> 8), byte(len(topic))}, topic...)
+	body = append(body, payload...)
+
+	packet := []byte{0x30}
+	for n := len(body); ; {
+		digit := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			digit |= 0x80
+		}
+		packet = append(packet, digit)
+		if n == 0 {
+			break
+		}
+	}
+	return append(packet, body...)
+}
+
+// TestRealtimeStandIn feeds a PUBLISH through a stand-in connection and
+// reads the decoded event from Events
+func TestRealtimeStandIn(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	r := newTestRealtime(mqtt.NewConn(client))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.run(ctx)
+
+	go func() {
+		server.Write(publishPacket(topicMessageSync, compressed(t, patch(t, "add", "/direct_v2/threads/t1/items/i1", textItem))))
+		// Take the client's DISCONNECT so closing doesn't block
+		io.Copy(io.Discard, server)
+	}()
+
+	select {
+	case e := <-r.Events():
+		checkEvent(t, e, Event{Type: EventMessage, ThreadID: "t1", ThreadTitle: "alice", ItemID: "i1", ItemType: "text",
+			SenderID: 2, SenderName: "alice", Text: "hi", Timestamp: time.UnixMicro(1700000000000000)})
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event")
+	}
+
+	cancel()
+	for range r.Events() {
+	}
+	if r.Err() == nil {
+		t.Error("Err() = nil after the connection ended")
+	}
+}
@@ -23,3 +23,12 @@ type Session struct {
 	DeviceSettings    *DeviceSettings   `json:"device_settings"`
 	UUIDs             map[string]string `json:"uuids"`
 }
+
+// AccountName names the account the session belongs to: its username, or its
+// user ID for sessions restored from a session ID
+func (s *Session) AccountName() string {
+	if s.Username != "" {
+		return s.Username
+	}
+	return s.Cookies["ds_user_id"]
+}
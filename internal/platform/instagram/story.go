@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"math/rand"
 	"net/http"
 	"net/url"
 	"os"
@@ -13,7 +12,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/google/uuid"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/PiotrWarzachowski/go-instagram-cli/internal/video"
@@ -185,100 +183,14 @@ func (c *Client) getStoryViewers(ctx context.Context, storyID string) ([]StoryVi
 }
 
 func (c *Client) rawUploadVideo(ctx context.Context, info video.VideoInfo, pr ProgressReporter, current, total int) (string, error) {
-	uploadID := strconv.FormatInt(time.Now().UnixMilli(), 10)
-	waterfallID := uuid.New().String()
-	uploadName := fmt.Sprintf("%s_0_%d", uploadID, rand.Int63n(9000000000)+1000000000)
-
-	ruploadParams := map[string]string{
-		"retry_context":            `{"num_step_auto_retry":0,"num_reupload":0,"num_step_manual_retry":0}`,
-		"media_type":               "2",
-		"upload_id":                uploadID,
-		"upload_media_duration_ms": strconv.Itoa(int(info.Duration * 1000)),
-		"upload_media_width":       strconv.Itoa(info.Width),
-		"upload_media_height":      strconv.Itoa(info.Height),
-		"for_album":                "1",
-		"extract_cover_frame":      "1",
-		"content_tags":             "has-overlay",
+	storyParams := map[string]string{
+		"media_type":          "2",
+		"for_album":           "1",
+		"extract_cover_frame": "1",
+		"content_tags":        "has-overlay",
 	}
 
-	paramsJSON, _ := json.Marshal(ruploadParams)
-	url := fmt.Sprintf("https://i.instagram.com/rupload_igvideo/%s", uploadName)
-
-	// 1. Context-aware Handshake (GET)
-	getReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return "", err
-	}
-
-	getReq.Header.Set("X-Instagram-Rupload-Params", string(paramsJSON))
-	getReq.Header.Set("X_FB_VIDEO_WATERFALL_ID", waterfallID)
-	getReq.Header.Set("Accept-Encoding", "gzip, deflate")
-	c.setWebUploadHeaders(getReq)
-
-	getResp, err := c.httpClient.Do(getReq)
-	if err != nil {
-		return "", fmt.Errorf("handshake network error: %w", err)
-	}
-	defer getResp.Body.Close()
-
-	if getResp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("handshake failed with status %d", getResp.StatusCode)
-	}
-
-	// 2. Stream video from disk instead of reading it all into RAM
-	file, err := os.Open(info.Path)
-	if err != nil {
-		return "", fmt.Errorf("failed to open video file: %w", err)
-	}
-	defer file.Close()
-
-	fileInfo, err := file.Stat()
-	if err != nil {
-		return "", err
-	}
-
-	pw := &progressWriter{
-		reader: file,
-		total:  fileInfo.Size(),
-		onProg: func(read, total int64) {
-			if pr != nil {
-				pr.Report(ProgressReport{
-					Step:       "UPLOAD",
-					Current:    int(current),
-					Total:      int(total),
-					BytesSent:  read,         // The 'read' from progressWriter
-					TotalBytes: int64(total), // The 'total' from progressWriter
-				})
-			}
-		},
-	}
-
-	postReq, err := http.NewRequestWithContext(ctx, "POST", url, pw)
-	if err != nil {
-		return "", err
-	}
-	postReq.ContentLength = fileInfo.Size()
-	postReq.Header.Set("X-Entity-Name", uploadName)
-	postReq.Header.Set("X-Entity-Length", strconv.FormatInt(fileInfo.Size(), 10))
-	postReq.Header.Set("X-Entity-Type", "video/mp4")
-	postReq.Header.Set("Offset", "0")
-	postReq.Header.Set("Content-Type", "application/octet-stream")
-	postReq.Header.Set("X-Instagram-Rupload-Params", string(paramsJSON))
-	postReq.Header.Set("X_FB_VIDEO_WATERFALL_ID", waterfallID)
-	c.setWebUploadHeaders(postReq) // Ensure headers are consistent
-
-	postResp, err := c.httpClient.Do(postReq)
-	if err != nil {
-		return "", fmt.Errorf("upload network error: %w", err)
-	}
-	defer postResp.Body.Close()
-
-	if postResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(postResp.Body)
-		return "", fmt.Errorf("upload failed (%d): %s", postResp.StatusCode, string(body))
-	}
-
-	return uploadID, nil
+	return c.ruploadVideo(ctx, info, storyParams, ProgressStory, pr, current, total)
 }
 
 func (c *Client) configureStory(ctx context.Context, uploadID string, info video.VideoInfo) error {
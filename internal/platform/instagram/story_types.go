@@ -105,11 +105,15 @@ type ImageVersions struct {
 }
 
 type ImageCandidate struct {
-	URL string `json:"url"`
+	URL    string `json:"url"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
 }
 
 type VideoVersion struct {
-	URL string `json:"url"`
+	URL    string `json:"url"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
 }
 
 type Caption struct {
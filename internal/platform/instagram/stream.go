@@ -0,0 +1,158 @@
+package instagram
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const maxStreamBackoff = 5 * time.Minute
+
+// StreamOptions configures StreamEvents
+type StreamOptions struct {
+	// Interval between inbox polls
+	Interval time.Duration
+
+	// RealtimeAddr enables the MQTT transport when set
+	RealtimeAddr string
+
+	// OnError is told about every failure and how long until the next try
+	OnError func(err error, retryIn time.Duration)
+}
+
+// StreamEvents delivers inbox events until ctx is cancelled. With a realtime
+// address it listens on MQTT and polls the inbox only while disconnected;
+// otherwise it polls at the given interval. Errors back off exponentially.
+func (c *Client) StreamEvents(ctx context.Context, opts StreamOptions) <-chan Event {
+	if opts.Interval <= 0 {
+		opts.Interval = 15 * time.Second
+	}
+	if opts.OnError == nil {
+		opts.OnError = func(error, time.Duration) {}
+	}
+
+	out := make(chan Event, 64)
+	go c.stream(ctx, opts, out)
+	return out
+}
+
+func (c *Client) stream(ctx context.Context, opts StreamOptions, out chan<- Event) {
+	defer close(out)
+
+	watcher := c.NewInboxWatcher()
+	delivered := make(map[string]bool)
+
+	emit := func(events []Event, source string) bool {
+		for _, e := range events {
+			if key := eventKey(e); key != "" {
+				if delivered[key] {
+					continue
+				}
+				// Bound memory on long runs; a rare repeat is acceptable
+				if len(delivered) > 10000 {
+					delivered = make(map[string]bool)
+				}
+				delivered[key] = true
+			}
+
+			if e.Source == "" {
+				e.Source = source
+			}
+
+			select {
+			case out <- e:
+			case <-ctx.Done():
+				return false
+			}
+		}
+		return true
+	}
+
+	var (
+		primed           bool
+		delay            time.Duration
+		pollFailures     int
+		realtimeFailures int
+		nextRealtime     time.Time
+	)
+
+	for ctx.Err() == nil {
+		// The first poll records where the inbox stands, so polls after a
+		// realtime disconnect report only what was missed
+		if primed && opts.RealtimeAddr != "" && !time.Now().Before(nextRealtime) {
+			started := time.Now()
+
+			rt, err := c.ConnectRealtime(ctx, opts.RealtimeAddr)
+			if err == nil {
+				for e := range rt.Events() {
+					if !emit([]Event{e}, "realtime") {
+						rt.Close()
+						return
+					}
+				}
+				err = rt.Err()
+			}
+			if ctx.Err() != nil {
+				return
+			}
+
+			if time.Since(started) > time.Minute {
+				realtimeFailures = 0
+			}
+			realtimeFailures++
+
+			retry := backoff(30*time.Second, realtimeFailures)
+			nextRealtime = time.Now().Add(retry)
+			opts.OnError(fmt.Errorf("realtime: %w (polling until reconnect)", err), retry)
+
+			delay = 0
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		events, err := watcher.Poll()
+		if err != nil {
+			pollFailures++
+			delay = backoff(opts.Interval, pollFailures)
+			opts.OnError(err, delay)
+			continue
+		}
+
+		pollFailures = 0
+		delay = opts.Interval
+		primed = true
+
+		if !emit(events, "poll") {
+			return
+		}
+	}
+}
+
+// backoff doubles the wait after every consecutive failure
+func backoff(interval time.Duration, failures int) time.Duration {
+	delay := interval
+	for i := 1; i < failures && delay < maxStreamBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxStreamBackoff)
+}
+
+// eventKey identifies events both transports may report. Typing and seen
+// events are transient and never deduplicated.
+func eventKey(e Event) string {
+	switch e.Type {
+	case EventMessage:
+		return fmt.Sprintf("%s|%s|%s", e.Type, e.ThreadID, e.ItemID)
+	case EventRequest:
+		return fmt.Sprintf("%s|%s", e.Type, e.ThreadID)
+	case EventReaction:
+		return fmt.Sprintf("%s|%s|%d|%s", e.ItemID, e.ThreadID, e.SenderID, e.Emoji)
+	default:
+		return ""
+	}
+}
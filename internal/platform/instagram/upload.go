@@ -0,0 +1,174 @@
+package instagram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/video"
+)
+
+// ruploadVideo streams a video or audio file to the rupload_igvideo endpoint.
+// extraParams are merged into the rupload params so callers can pick the media
+// type and surface (story segment, direct video, voice note).
+func (c *Client) ruploadVideo(ctx context.Context, info video.VideoInfo, extraParams map[string]string, progressType ProgressType, pr ProgressReporter, current, total int) (string, error) {
+	uploadID := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	waterfallID := uuid.New().String()
+	uploadName := fmt.Sprintf("%s_0_%d", uploadID, rand.Int63n(9000000000)+1000000000)
+
+	ruploadParams := map[string]string{
+		"retry_context":            `{"num_step_auto_retry":0,"num_reupload":0,"num_step_manual_retry":0}`,
+		"upload_id":                uploadID,
+		"upload_media_duration_ms": strconv.Itoa(int(info.Duration * 1000)),
+		"upload_media_width":       strconv.Itoa(info.Width),
+		"upload_media_height":      strconv.Itoa(info.Height),
+	}
+	for k, v := range extraParams {
+		ruploadParams[k] = v
+	}
+
+	paramsJSON, _ := json.Marshal(ruploadParams)
+	url := fmt.Sprintf("https://i.instagram.com/rupload_igvideo/%s", uploadName)
+
+	// 1. Context-aware Handshake (GET)
+	getReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	getReq.Header.Set("X-Instagram-Rupload-Params", string(paramsJSON))
+	getReq.Header.Set("X_FB_VIDEO_WATERFALL_ID", waterfallID)
+	getReq.Header.Set("Accept-Encoding", "gzip, deflate")
+	c.setWebUploadHeaders(getReq)
+
+	getResp, err := c.httpClient.Do(getReq)
+	if err != nil {
+		return "", fmt.Errorf("handshake network error: %w", err)
+	}
+	defer getResp.Body.Close()
+
+	if getResp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("handshake failed with status %d", getResp.StatusCode)
+	}
+
+	// 2. Stream video from disk instead of reading it all into RAM
+	file, err := os.Open(info.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open video file: %w", err)
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	postReq, err := http.NewRequestWithContext(ctx, "POST", url, c.newUploadReader(file, fileInfo.Size(), progressType, pr, current, total))
+	if err != nil {
+		return "", err
+	}
+	postReq.ContentLength = fileInfo.Size()
+	postReq.Header.Set("X-Entity-Name", uploadName)
+	postReq.Header.Set("X-Entity-Length", strconv.FormatInt(fileInfo.Size(), 10))
+	postReq.Header.Set("X-Entity-Type", "video/mp4")
+	postReq.Header.Set("Offset", "0")
+	postReq.Header.Set("Content-Type", "application/octet-stream")
+	postReq.Header.Set("X-Instagram-Rupload-Params", string(paramsJSON))
+	postReq.Header.Set("X_FB_VIDEO_WATERFALL_ID", waterfallID)
+	c.setWebUploadHeaders(postReq) // Ensure headers are consistent
+
+	postResp, err := c.httpClient.Do(postReq)
+	if err != nil {
+		return "", fmt.Errorf("upload network error: %w", err)
+	}
+	defer postResp.Body.Close()
+
+	if postResp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(postResp.Body)
+		return "", fmt.Errorf("upload failed (%d): %s", postResp.StatusCode, string(body))
+	}
+
+	return uploadID, nil
+}
+
+// ruploadPhoto uploads a JPEG to the rupload_igphoto endpoint
+func (c *Client) ruploadPhoto(ctx context.Context, info video.ImageInfo, progressType ProgressType, pr ProgressReporter) (string, error) {
+	uploadID := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	uploadName := fmt.Sprintf("%s_0_%d", uploadID, rand.Int63n(9000000000)+1000000000)
+
+	ruploadParams := map[string]string{
+		"retry_context":     `{"num_step_auto_retry":0,"num_reupload":0,"num_step_manual_retry":0}`,
+		"media_type":        "1",
+		"upload_id":         uploadID,
+		"xsharing_user_ids": "[]",
+		"image_compression": `{"lib_name":"moz","lib_version":"3.1.m","quality":"80"}`,
+	}
+
+	paramsJSON, _ := json.Marshal(ruploadParams)
+	url := fmt.Sprintf("https://i.instagram.com/rupload_igphoto/%s", uploadName)
+
+	file, err := os.Open(info.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open image file: %w", err)
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, c.newUploadReader(file, fileInfo.Size(), progressType, pr, 1, 1))
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = fileInfo.Size()
+	req.Header.Set("X-Entity-Name", uploadName)
+	req.Header.Set("X-Entity-Length", strconv.FormatInt(fileInfo.Size(), 10))
+	req.Header.Set("X-Entity-Type", "image/jpeg")
+	req.Header.Set("Offset", "0")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("X-Instagram-Rupload-Params", string(paramsJSON))
+	c.setWebUploadHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("upload network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("upload failed (%d): %s", resp.StatusCode, string(body))
+	}
+
+	return uploadID, nil
+}
+
+// newUploadReader wraps an upload body so every read is reported as UPLOAD progress
+func (c *Client) newUploadReader(r io.Reader, size int64, progressType ProgressType, pr ProgressReporter, current, total int) io.Reader {
+	return &progressWriter{
+		reader: r,
+		total:  size,
+		onProg: func(read, size int64) {
+			if pr != nil {
+				pr.Report(ProgressReport{
+					Type:       progressType,
+					Step:       "UPLOAD",
+					Current:    current,
+					Total:      total,
+					BytesSent:  read,
+					TotalBytes: size,
+				})
+			}
+		},
+	}
+}
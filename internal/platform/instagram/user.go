@@ -0,0 +1,86 @@
+package instagram
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrUserNotFound is returned when a username doesn't belong to any account
+var ErrUserNotFound = errors.New("user not found")
+
+// GetUserByUsername looks up an account by its username
+func (c *Client) GetUserByUsername(username string) (*ThreadUser, error) {
+	username = strings.TrimPrefix(strings.TrimSpace(username), "@")
+	if username == "" {
+		return nil, fmt.Errorf("username cannot be empty")
+	}
+
+	req, err := http.NewRequest("GET", "https://www.instagram.com/api/v1/users/web_profile_info/?username="+url.QueryEscape(username), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.setWebHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if c.Debug {
+		fmt.Printf("[DEBUG] Profile response status: %d\n", resp.StatusCode)
+		fmt.Printf("[DEBUG] Profile response: %s\n", string(body))
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: @%s", ErrUserNotFound, username)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch profile: status %d", resp.StatusCode)
+	}
+
+	var profile struct {
+		Data struct {
+			User *struct {
+				ID            json.Number `json:"id"`
+				Username      string      `json:"username"`
+				FullName      string      `json:"full_name"`
+				IsPrivate     bool        `json:"is_private"`
+				IsVerified    bool        `json:"is_verified"`
+				ProfilePicURL string      `json:"profile_pic_url"`
+				FollowedBy    struct {
+					Count int `json:"count"`
+				} `json:"edge_followed_by"`
+			} `json:"user"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse profile response: %w", err)
+	}
+
+	u := profile.Data.User
+	if u == nil || u.ID == "" {
+		return nil, fmt.Errorf("%w: @%s", ErrUserNotFound, username)
+	}
+
+	return &ThreadUser{
+		Pk:            u.ID,
+		Username:      u.Username,
+		FullName:      u.FullName,
+		IsPrivate:     u.IsPrivate,
+		IsVerified:    u.IsVerified,
+		ProfilePicURL: u.ProfilePicURL,
+		FollowerCount: u.FollowedBy.Count,
+	}, nil
+}
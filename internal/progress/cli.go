@@ -0,0 +1,141 @@
+// Package progress draws upload progress reported by the Instagram client
+// as a terminal progress bar
+package progress
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/vbauerster/mpb/v8"
+	"github.com/vbauerster/mpb/v8/decor"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram"
+)
+
+const (
+	colorReset = "\033[0m"
+	colorDim   = "\033[2m"
+)
+
+// labels are the wording of the bar for one kind of upload
+type labels struct {
+	upload string // a single upload
+	part   string // one of several parts, formatted with part and count
+	config string
+	parts  string // configuring one of several parts
+	done   string
+}
+
+var kindLabels = map[instagram.ProgressType]labels{
+	instagram.ProgressStory: {
+		upload: "🎬 Uploading",
+		part:   "🎬 Part %d/%d",
+		config: "⚙️  Config",
+		parts:  "⚙️  Config %d/%d",
+		done:   "✨ Done!",
+	},
+	instagram.ProgressMessage: {
+		upload: "📤 Uploading",
+		part:   "📤 Part %d/%d",
+		config: "✉️  Sending",
+		parts:  "✉️  Send %d/%d",
+		done:   "✨ Sent!",
+	},
+}
+
+// CLIReporter renders the upload of a story or message as one bar across
+// all of its parts
+type CLIReporter struct {
+	progress *mpb.Progress
+	master   *mpb.Bar
+	labels   labels
+	mu       sync.Mutex
+
+	statusMsg    string
+	bytesHandled int64
+	part         int
+	partBytes    int64
+}
+
+// NewCLIReporter returns a reporter worded for kind
+func NewCLIReporter(kind instagram.ProgressType) *CLIReporter {
+	l, ok := kindLabels[kind]
+	if !ok {
+		l = kindLabels[instagram.ProgressMessage]
+	}
+
+	return &CLIReporter{
+		progress:  mpb.New(mpb.WithWidth(60)),
+		labels:    l,
+		statusMsg: "Initializing...",
+	}
+}
+
+func (r *CLIReporter) Report(p instagram.ProgressReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p.Step == "INIT" && r.master == nil {
+		r.master = r.progress.AddBar(p.TotalBytes,
+			mpb.PrependDecorators(
+				decor.Any(func(st decor.Statistics) string {
+					return fmt.Sprintf("%-15s", r.statusMsg)
+				}, decor.WCSyncSpaceR),
+				decor.Counters(decor.SizeB1024(0), "% .2f / % .2f", decor.WCSyncSpace),
+			),
+			mpb.AppendDecorators(
+				decor.AverageSpeed(decor.SizeB1024(0), "% .2f", decor.WCSyncSpace),
+				decor.Name(" | "),
+				decor.OnComplete(
+					decor.AverageETA(decor.ET_STYLE_GO), r.labels.done,
+				),
+			),
+		)
+		return
+	}
+
+	if r.master == nil {
+		if p.Message != "" {
+			fmt.Printf("%s📦 %s%s\n", colorDim, p.Message, colorReset)
+		}
+		r.statusMsg = p.Step
+		return
+	}
+
+	switch p.Step {
+	case "UPLOAD":
+		// Each part reports its own byte counts, so carry finished parts forward
+		if p.Current != r.part {
+			r.bytesHandled += r.partBytes
+			r.part = p.Current
+		}
+		r.partBytes = p.TotalBytes
+
+		r.statusMsg = r.labels.upload
+		if p.Total > 1 {
+			r.statusMsg = fmt.Sprintf(r.labels.part, p.Current, p.Total)
+		}
+		r.master.SetCurrent(r.bytesHandled + p.BytesSent)
+
+	case "CONFIG":
+		r.statusMsg = r.labels.config
+		if p.Total > 1 {
+			r.statusMsg = fmt.Sprintf(r.labels.parts, p.Current, p.Total)
+		}
+
+	case "PREPARE":
+		r.statusMsg = "📦 Preparing..."
+	}
+}
+
+// Wait completes the bar (uploads can finish short of the estimated size)
+// and blocks until it is rendered
+func (r *CLIReporter) Wait() {
+	r.mu.Lock()
+	if r.master != nil && !r.master.Completed() {
+		r.master.SetTotal(-1, true)
+	}
+	r.mu.Unlock()
+
+	r.progress.Wait()
+}
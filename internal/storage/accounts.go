@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/PiotrWarzachowski/go-instagram-cli/internal/platform/instagram/session"
+)
+
+func (s *Storage) accountPath(name string) string {
+	return filepath.Join(s.basePath, AccountsDir, name+".enc")
+}
+
+// saveAccount keeps a copy of the session next to the other logged-in
+// accounts, so logging in to another account doesn't forget this one
+func (s *Storage) saveAccount(stored *session.Session) error {
+	name := stored.AccountName()
+	if name == "" {
+		return nil
+	}
+	return s.writeEncrypted(s.accountPath(name), stored)
+}
+
+// LoadAccounts returns the session of every logged-in account, ordered by
+// name. The current session is included even if it was saved before
+// accounts were kept.
+func (s *Storage) LoadAccounts() ([]*session.Session, error) {
+	entries, err := os.ReadDir(filepath.Join(s.basePath, AccountsDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read accounts: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var accounts []*session.Session
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".enc") {
+			continue
+		}
+
+		stored := &session.Session{}
+		if _, err := s.readEncrypted(filepath.Join(s.basePath, AccountsDir, entry.Name()), stored); err != nil {
+			return nil, err
+		}
+
+		seen[stored.AccountName()] = true
+		accounts = append(accounts, stored)
+	}
+
+	current, err := s.LoadSession()
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && !seen[current.AccountName()] {
+		accounts = append(accounts, current)
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return strings.ToLower(accounts[i].AccountName()) < strings.ToLower(accounts[j].AccountName())
+	})
+	return accounts, nil
+}
+
+// DeleteAccount forgets the session of a logged-in account
+func (s *Storage) DeleteAccount(name string) error {
+	err := os.Remove(s.accountPath(name))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete account %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// maxAutoReplyEntries bounds the log; older entries are dropped first
+const maxAutoReplyEntries = 1000
+
+// LoadAutoReplyLog returns the auto-responder's record, empty on first run
+func (s *Storage) LoadAutoReplyLog() (*AutoReplyLog, error) {
+	log := &AutoReplyLog{}
+	if _, err := s.readEncrypted(filepath.Join(s.basePath, AutoReplyFile), log); err != nil {
+		return nil, err
+	}
+
+	if log.LastSent == nil {
+		log.LastSent = make(map[string]int64)
+	}
+
+	// Older logs kept a cooldown per rule as "thread_id|rule"
+	for key, sent := range log.LastSent {
+		if threadID, _, ok := strings.Cut(key, "|"); ok {
+			delete(log.LastSent, key)
+			log.LastSent[threadID] = max(log.LastSent[threadID], sent)
+		}
+	}
+	return log, nil
+}
+
+func (s *Storage) SaveAutoReplyLog(log *AutoReplyLog) error {
+	if n := len(log.Entries); n > maxAutoReplyEntries {
+		log.Entries = log.Entries[n-maxAutoReplyEntries:]
+	}
+	return s.writeEncrypted(filepath.Join(s.basePath, AutoReplyFile), log)
+}
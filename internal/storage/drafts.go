@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LoadDrafts returns the saved drafts, empty if there are none
+func (s *Storage) LoadDrafts() (*Drafts, error) {
+	drafts := &Drafts{}
+	if _, err := s.readEncrypted(filepath.Join(s.basePath, DraftsFile), drafts); err != nil {
+		return nil, err
+	}
+
+	if drafts.Threads == nil {
+		drafts.Threads = make(map[string]Draft)
+	}
+	return drafts, nil
+}
+
+// SaveDraft stores the draft of a thread, removing it when text is blank.
+// The file is reread first so drafts saved by another session are kept.
+func (s *Storage) SaveDraft(threadID string, text string) error {
+	drafts, err := s.LoadDrafts()
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(text) == "" {
+		if _, ok := drafts.Threads[threadID]; !ok {
+			return nil
+		}
+		delete(drafts.Threads, threadID)
+	} else {
+		drafts.Threads[threadID] = Draft{Text: text, UpdatedAt: time.Now()}
+	}
+
+	return s.writeEncrypted(filepath.Join(s.basePath, DraftsFile), drafts)
+}
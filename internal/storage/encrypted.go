@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// writeEncrypted marshals v and writes it encrypted to path
+func (s *Storage) writeEncrypted(path string, v any) error {
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", filepath.Base(path), err)
+	}
+
+	encrypted, err := s.encrypt(jsonData)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt %s: %w", filepath.Base(path), err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	// Write then rename so an interrupted save never leaves a torn file. Each
+	// writer gets its own temp file, so concurrent saves can't mix.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	_, err = tmp.Write(encrypted)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// readEncrypted decrypts path into v. It reports false when the file doesn't exist.
+func (s *Storage) readEncrypted(path string, v any) (bool, error) {
+	encrypted, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+
+	decrypted, err := s.decrypt(encrypted)
+	if err != nil {
+		return false, fmt.Errorf("failed to decrypt %s: %w", filepath.Base(path), err)
+	}
+
+	if err := json.Unmarshal(decrypted, v); err != nil {
+		return false, fmt.Errorf("failed to unmarshal %s: %w", filepath.Base(path), err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func (s *Storage) historyPath(threadID string) string {
+	return filepath.Join(s.basePath, HistoryDir, threadID+".enc")
+}
+
+// LoadHistory returns the synced history of a thread, or nil if it was never synced
+func (s *Storage) LoadHistory(threadID string) (*ThreadHistory, error) {
+	var history ThreadHistory
+	found, err := s.readEncrypted(s.historyPath(threadID), &history)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &history, nil
+}
+
+func (s *Storage) SaveHistory(history *ThreadHistory) error {
+	history.UpdatedAt = time.Now().Unix()
+	return s.writeEncrypted(s.historyPath(history.ThreadID), history)
+}
+
+// DeleteHistory removes the synced history of a thread, if there is one
+func (s *Storage) DeleteHistory(threadID string) error {
+	if err := os.Remove(s.historyPath(threadID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete history: %w", err)
+	}
+	return nil
+}
+
+// ListHistories returns the IDs of every thread synced locally
+func (s *Storage) ListHistories() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(s.basePath, HistoryDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list history: %w", err)
+	}
+
+	var threadIDs []string
+	for _, entry := range entries {
+		if id, ok := strings.CutSuffix(entry.Name(), ".enc"); ok && !entry.IsDir() {
+			threadIDs = append(threadIDs, id)
+		}
+	}
+
+	return threadIDs, nil
+}
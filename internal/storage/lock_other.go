@@ -0,0 +1,9 @@
+//go:build !unix
+
+package storage
+
+// lockFile is a no-op where flock isn't available. Writes still replace
+// files atomically, but concurrent updates can lose one another's changes.
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
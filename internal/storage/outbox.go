@@ -0,0 +1,35 @@
+package storage
+
+import "path/filepath"
+
+// LoadOutbox returns the queued messages, empty if nothing was queued yet
+func (s *Storage) LoadOutbox() (*Outbox, error) {
+	outbox := &Outbox{}
+	if _, err := s.readEncrypted(filepath.Join(s.basePath, OutboxFile), outbox); err != nil {
+		return nil, err
+	}
+	return outbox, nil
+}
+
+func (s *Storage) SaveOutbox(outbox *Outbox) error {
+	return s.writeEncrypted(filepath.Join(s.basePath, OutboxFile), outbox)
+}
+
+// UpdateOutbox loads the outbox, applies fn and saves it. The file stays
+// locked in between, so a flush and a chat queueing at the same time can't
+// lose each other's messages.
+func (s *Storage) UpdateOutbox(fn func(outbox *Outbox)) error {
+	unlock, err := lockFile(filepath.Join(s.basePath, OutboxFile))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	outbox, err := s.LoadOutbox()
+	if err != nil {
+		return err
+	}
+
+	fn(outbox)
+	return s.SaveOutbox(outbox)
+}
@@ -0,0 +1,20 @@
+package storage
+
+import "path/filepath"
+
+// LoadSearchIndex returns the local search index, empty if none was built yet
+func (s *Storage) LoadSearchIndex() (*SearchIndex, error) {
+	index := &SearchIndex{}
+	if _, err := s.readEncrypted(filepath.Join(s.basePath, SearchIndexFile), index); err != nil {
+		return nil, err
+	}
+
+	if index.Threads == nil {
+		index.Threads = make(map[string]*IndexedThread)
+	}
+	return index, nil
+}
+
+func (s *Storage) SaveSearchIndex(index *SearchIndex) error {
+	return s.writeEncrypted(filepath.Join(s.basePath, SearchIndexFile), index)
+}
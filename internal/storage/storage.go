@@ -23,6 +23,13 @@ const (
 	KeyFile         = ".key"
 	CredentialsFile = "credentials.enc"
 	CacheFile       = "cache.enc"
+	HistoryDir      = "history"
+	SearchIndexFile = "search.enc"
+	AutoReplyFile   = "autoreply.enc"
+	OutboxFile      = "outbox.enc"
+	TriageFile      = "triage.enc"
+	DraftsFile      = "drafts.enc"
+	AccountsDir     = "accounts"
 )
 
 func NewSessionStorage() (*Storage, error) {
@@ -140,7 +147,7 @@ func (s *Storage) SaveSession(sessionToStore *session.Session, password string)
 		return fmt.Errorf("failed to write session file: %w", err)
 	}
 
-	return nil
+	return s.saveAccount(storedSession)
 }
 
 func (s *Storage) LoadSession() (*session.Session, error) {
@@ -190,6 +197,12 @@ func (s *Storage) GetBasePath() string {
 	return s.basePath
 }
 
+// ConfigPath returns the path of a user-editable file kept next to the
+// encrypted database, e.g. ~/.local/go-instagram-cli/autoreply.json
+func (s *Storage) ConfigPath(name string) string {
+	return filepath.Join(filepath.Dir(s.basePath), name)
+}
+
 func (s *Storage) SaveCredentials(username, password string) error {
 	creds := &StoredCredentials{
 		Username: username,
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"encoding/json"
+	"time"
 )
 
 type Storage struct {
@@ -30,3 +31,106 @@ type CachedThread struct {
 	CachedAt  int64           `json:"cached_at"`
 	ExpiresAt int64           `json:"expires_at"`
 }
+
+// ThreadHistory is a locally synced copy of a direct thread. Items are kept
+// as raw API JSON (newest first) so the storage layer stays API-agnostic.
+type ThreadHistory struct {
+	ThreadID  string          `json:"thread_id"`
+	Thread    json.RawMessage `json:"thread"`
+	Items     json.RawMessage `json:"items"`
+	Cursor    string          `json:"cursor"`
+	Complete  bool            `json:"complete"`
+	UpdatedAt int64           `json:"updated_at"`
+}
+
+// SearchIndex holds the searchable text of every synced thread
+type SearchIndex struct {
+	Threads map[string]*IndexedThread `json:"threads"`
+}
+
+// IndexedThread is the index of one thread. UpdatedAt mirrors the history it
+// was built from, so unchanged threads aren't reindexed.
+type IndexedThread struct {
+	Title     string        `json:"title"`
+	UpdatedAt int64         `json:"updated_at"`
+	Entries   []SearchEntry `json:"entries"`
+}
+
+type SearchEntry struct {
+	ItemID    string    `json:"item_id"`
+	ItemType  string    `json:"item_type"`
+	Sender    string    `json:"sender"`
+	FromMe    bool      `json:"from_me"`
+	Text      string    `json:"text"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// AutoReplyLog records what the auto-responder sent. LastSent holds the
+// time of the last reply per thread ID for cooldowns.
+type AutoReplyLog struct {
+	LastSent map[string]int64 `json:"last_sent"`
+	Entries  []AutoReplyEntry `json:"entries"`
+}
+
+type AutoReplyEntry struct {
+	Rule        string    `json:"rule"`
+	ThreadID    string    `json:"thread_id"`
+	ThreadTitle string    `json:"thread_title"`
+	Sender      string    `json:"sender"`
+	ItemID      string    `json:"item_id"`
+	Text        string    `json:"text"`
+	SentItemID  string    `json:"sent_item_id,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// Outbox holds messages waiting to be sent, oldest first
+type Outbox struct {
+	Messages []OutboxMessage `json:"messages"`
+}
+
+// OutboxMessage is a queued text message. ClientContext is fixed when it's
+// queued and reused on every attempt, so Instagram drops duplicates. Account
+// is the account that sends it, empty for messages queued before there
+// could be several. Failed messages were rejected by Instagram and are kept
+// for the user to see, but never retried.
+type OutboxMessage struct {
+	ClientContext        string    `json:"client_context"`
+	ThreadID             string    `json:"thread_id"`
+	ThreadTitle          string    `json:"thread_title"`
+	Account              string    `json:"account,omitempty"`
+	Text                 string    `json:"text"`
+	ReplyToItemID        string    `json:"reply_to_item_id,omitempty"`
+	ReplyToClientContext string    `json:"reply_to_client_context,omitempty"`
+	CreatedAt            time.Time `json:"created_at"`
+	SendAt               time.Time `json:"send_at"`
+	Attempts             int       `json:"attempts"`
+	NextAttempt          time.Time `json:"next_attempt,omitempty"`
+	LastError            string    `json:"last_error,omitempty"`
+	Failed               bool      `json:"failed,omitempty"`
+}
+
+// TriageLog records the decisions made by message request triage
+type TriageLog struct {
+	Entries []TriageEntry `json:"entries"`
+}
+
+// TriageEntry is one triaged request. Error is set when approving or
+// declining it failed; ignored requests are logged without being touched.
+type TriageEntry struct {
+	ThreadID  string    `json:"thread_id"`
+	Sender    string    `json:"sender"`
+	Decision  string    `json:"decision"`
+	Reasons   []string  `json:"reasons,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Drafts holds unsent messages by thread ID
+type Drafts struct {
+	Threads map[string]Draft `json:"threads"`
+}
+
+type Draft struct {
+	Text      string    `json:"text"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,29 @@
+package storage
+
+import "path/filepath"
+
+// maxTriageEntries bounds the log; older entries are dropped first
+const maxTriageEntries = 5000
+
+// LoadTriageLog returns the request triage record, empty on first run
+func (s *Storage) LoadTriageLog() (*TriageLog, error) {
+	log := &TriageLog{}
+	if _, err := s.readEncrypted(filepath.Join(s.basePath, TriageFile), log); err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+// AppendTriageLog adds entries to the triage record
+func (s *Storage) AppendTriageLog(entries ...TriageEntry) error {
+	log, err := s.LoadTriageLog()
+	if err != nil {
+		return err
+	}
+
+	log.Entries = append(log.Entries, entries...)
+	if n := len(log.Entries); n > maxTriageEntries {
+		log.Entries = log.Entries[n-maxTriageEntries:]
+	}
+	return s.writeEncrypted(filepath.Join(s.basePath, TriageFile), log)
+}
@@ -0,0 +1,99 @@
+package termimg
+
+import (
+	"fmt"
+	"image"
+	"strings"
+)
+
+// encodeSixel quantizes img to a 6×6×6 colour cube and writes it as sixel
+// bands of six pixel rows
+func encodeSixel(img *image.RGBA) string {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	// Palette index of every pixel
+	pixels := make([]byte, w*h)
+	used := make([]bool, 216)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			o := img.PixOffset(b.Min.X+x, b.Min.Y+y)
+			r, g, bl := img.Pix[o], img.Pix[o+1], img.Pix[o+2]
+			idx := cubeLevel(r)*36 + cubeLevel(g)*6 + cubeLevel(bl)
+			pixels[y*w+x] = byte(idx)
+			used[idx] = true
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "\x1bP0;1;0q\"1;1;%d;%d", w, h)
+
+	for i, ok := range used {
+		if ok {
+			// Sixel colours are given in percent
+			fmt.Fprintf(&out, "#%d;2;%d;%d;%d", i, (i/36)*20, (i/6%6)*20, (i%6)*20)
+		}
+	}
+
+	row := make([]byte, w)
+	for top := 0; top < h; top += 6 {
+		first := true
+		for color := 0; color < 216; color++ {
+			if !used[color] {
+				continue
+			}
+
+			hit := false
+			for x := 0; x < w; x++ {
+				var bits byte
+				for dy := 0; dy < 6 && top+dy < h; dy++ {
+					if pixels[(top+dy)*w+x] == byte(color) {
+						bits |= 1 << dy
+					}
+				}
+				row[x] = bits
+				hit = hit || bits != 0
+			}
+			if !hit {
+				continue
+			}
+
+			if !first {
+				out.WriteByte('$')
+			}
+			first = false
+
+			fmt.Fprintf(&out, "#%d", color)
+			writeSixelRow(&out, row)
+		}
+		out.WriteByte('-')
+	}
+
+	out.WriteString("\x1b\\\n")
+	return out.String()
+}
+
+// writeSixelRow writes one colour's bits for a band, run-length encoded
+func writeSixelRow(out *strings.Builder, row []byte) {
+	for x := 0; x < len(row); {
+		run := 1
+		for x+run < len(row) && row[x+run] == row[x] {
+			run++
+		}
+
+		ch := byte('?' + row[x])
+		if run > 3 {
+			fmt.Fprintf(out, "!%d%c", run, ch)
+		} else {
+			for i := 0; i < run; i++ {
+				out.WriteByte(ch)
+			}
+		}
+		x += run
+	}
+}
+
+// cubeLevel maps a channel to one of the cube's six levels
+func cubeLevel(v uint8) int {
+	return (int(v)*5 + 127) / 255
+}
@@ -0,0 +1,122 @@
+// Package termimg draws images inline in terminals that speak the kitty
+// graphics protocol or sixel.
+package termimg
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"strings"
+)
+
+type Protocol int
+
+const (
+	None Protocol = iota
+	Kitty
+	Sixel
+)
+
+// Cell size assumed when scaling for sixel, which is drawn in pixels
+const (
+	cellWidth  = 10
+	cellHeight = 20
+)
+
+func (p Protocol) String() string {
+	switch p {
+	case Kitty:
+		return "kitty"
+	case Sixel:
+		return "sixel"
+	}
+	return "off"
+}
+
+// ParseProtocol reads a protocol name. auto detects what the terminal
+// supports from its environment.
+func ParseProtocol(name string) (Protocol, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "auto":
+		return Detect(), nil
+	case "kitty":
+		return Kitty, nil
+	case "sixel":
+		return Sixel, nil
+	case "off", "none", "no":
+		return None, nil
+	}
+	return None, fmt.Errorf("unknown image protocol %q (use auto, kitty, sixel or off)", name)
+}
+
+// Detect guesses the graphics protocol from environment variables set by
+// terminals known to support one. Terminals that can't be recognized this
+// way get None.
+func Detect() Protocol {
+	term := os.Getenv("TERM")
+	program := os.Getenv("TERM_PROGRAM")
+
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "", term == "xterm-kitty", term == "xterm-ghostty",
+		program == "ghostty", program == "WezTerm":
+		return Kitty
+	case strings.Contains(term, "sixel"), term == "foot", strings.HasPrefix(term, "foot-"),
+		term == "mlterm", strings.HasPrefix(term, "contour"):
+		return Sixel
+	}
+	return None
+}
+
+// Decode reads a JPEG, PNG or GIF image
+func Decode(r io.Reader) (image.Image, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, nil
+}
+
+// Encode returns the escape sequence drawing img in at most cols×rows cells,
+// keeping its aspect ratio. The cursor ends up on the line below the image.
+func Encode(img image.Image, p Protocol, cols, rows int) string {
+	b := img.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 || cols <= 0 || rows <= 0 {
+		return ""
+	}
+
+	// Fit in pixels, then round to whole cells
+	w, h := cols*cellWidth, cols*cellWidth*b.Dy()/b.Dx()
+	if h > rows*cellHeight {
+		h = rows * cellHeight
+		w = h * b.Dx() / b.Dy()
+	}
+	w, h = max(w, 1), max(h, 1)
+
+	switch p {
+	case Kitty:
+		return encodeKitty(resize(img, w, h), (w+cellWidth-1)/cellWidth, (h+cellHeight-1)/cellHeight)
+	case Sixel:
+		return encodeSixel(resize(img, w, h))
+	}
+	return ""
+}
+
+// resize scales img to w×h with nearest-neighbour sampling, which is enough
+// for a thumbnail
+func resize(img image.Image, w, h int) *image.RGBA {
+	b := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		sy := b.Min.Y + y*b.Dy()/h
+		for x := 0; x < w; x++ {
+			sx := b.Min.X + x*b.Dx()/w
+			out.Set(x, y, img.At(sx, sy))
+		}
+	}
+	return out
+}
@@ -0,0 +1,171 @@
+package tui
+
+import (
+	"github.com/mattn/go-runewidth"
+)
+
+// Input is an editable multi-line text buffer
+type Input struct {
+	buf    []rune
+	cursor int
+}
+
+func (in *Input) String() string {
+	return string(in.buf)
+}
+
+func (in *Input) Empty() bool {
+	return len(in.buf) == 0
+}
+
+// Set replaces the contents and moves the cursor to the end
+func (in *Input) Set(s string) {
+	in.buf = []rune(s)
+	in.cursor = len(in.buf)
+}
+
+func (in *Input) Clear() {
+	in.Set("")
+}
+
+func (in *Input) Insert(text string) {
+	runes := []rune(text)
+	in.buf = append(in.buf[:in.cursor], append(runes, in.buf[in.cursor:]...)...)
+	in.cursor += len(runes)
+}
+
+func (in *Input) Backspace() {
+	if in.cursor == 0 {
+		return
+	}
+	in.buf = append(in.buf[:in.cursor-1], in.buf[in.cursor:]...)
+	in.cursor--
+}
+
+func (in *Input) Delete() {
+	if in.cursor >= len(in.buf) {
+		return
+	}
+	in.buf = append(in.buf[:in.cursor], in.buf[in.cursor+1:]...)
+}
+
+func (in *Input) Left() {
+	if in.cursor > 0 {
+		in.cursor--
+	}
+}
+
+func (in *Input) Right() {
+	if in.cursor < len(in.buf) {
+		in.cursor++
+	}
+}
+
+// Home moves to the start of the current line
+func (in *Input) Home() {
+	for in.cursor > 0 && in.buf[in.cursor-1] != '\n' {
+		in.cursor--
+	}
+}
+
+// End moves to the end of the current line
+func (in *Input) End() {
+	for in.cursor < len(in.buf) && in.buf[in.cursor] != '\n' {
+		in.cursor++
+	}
+}
+
+// DeleteToStart removes everything before the cursor on the current line
+func (in *Input) DeleteToStart() {
+	start := in.cursor
+	in.Home()
+	in.buf = append(in.buf[:in.cursor], in.buf[start:]...)
+}
+
+// Layout wraps the buffer to width cells and returns the lines along with
+// the cursor's row and column
+func (in *Input) Layout(width int) ([]string, int, int) {
+	if width < 1 {
+		width = 1
+	}
+
+	lines := []string{""}
+	lineWidth := 0
+	row, col := 0, 0
+
+	for i, r := range in.buf {
+		if i == in.cursor {
+			row, col = len(lines)-1, lineWidth
+		}
+
+		if r == '\n' {
+			lines = append(lines, "")
+			lineWidth = 0
+			continue
+		}
+
+		rw := runewidth.RuneWidth(r)
+		if lineWidth+rw > width {
+			lines = append(lines, "")
+			lineWidth = 0
+			if i == in.cursor {
+				row, col = len(lines)-1, 0
+			}
+		}
+
+		lines[len(lines)-1] += string(r)
+		lineWidth += rw
+	}
+
+	if in.cursor == len(in.buf) {
+		row, col = len(lines)-1, lineWidth
+		if col >= width {
+			lines = append(lines, "")
+			row, col = len(lines)-1, 0
+		}
+	}
+
+	return lines, row, col
+}
+
+// Up moves to the same column on the previous line. It reports false when
+// the cursor is already on the first line.
+func (in *Input) Up() bool {
+	start := in.lineStart(in.cursor)
+	if start == 0 {
+		return false
+	}
+
+	col := in.cursor - start
+	prev := in.lineStart(start - 1)
+	in.cursor = min(prev+col, start-1)
+	return true
+}
+
+// Down moves to the same column on the next line. It reports false when the
+// cursor is already on the last line.
+func (in *Input) Down() bool {
+	end := in.cursor
+	for end < len(in.buf) && in.buf[end] != '\n' {
+		end++
+	}
+	if end >= len(in.buf) {
+		return false
+	}
+
+	col := in.cursor - in.lineStart(in.cursor)
+	next := end + 1
+	nextEnd := next
+	for nextEnd < len(in.buf) && in.buf[nextEnd] != '\n' {
+		nextEnd++
+	}
+	in.cursor = min(next+col, nextEnd)
+	return true
+}
+
+func (in *Input) lineStart(pos int) int {
+	for pos > 0 && in.buf[pos-1] != '\n' {
+		pos--
+	}
+	return pos
+}
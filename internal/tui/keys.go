@@ -0,0 +1,177 @@
+package tui
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+type KeyType int
+
+const (
+	KeyRune KeyType = iota
+	KeyCtrl
+	KeyEnter
+	KeyNewline
+	KeyTab
+	KeyShiftTab
+	KeyBackspace
+	KeyDelete
+	KeyEsc
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+	KeyPgUp
+	KeyPgDn
+	KeyPaste
+)
+
+// Key is one decoded keypress. Rune holds the character for KeyRune and the
+// letter for KeyCtrl; Text holds pasted text.
+type Key struct {
+	Type KeyType
+	Rune rune
+	Text string
+}
+
+const (
+	pasteStart = "\x1b[200~"
+	pasteEnd   = "\x1b[201~"
+)
+
+// Decoder turns raw terminal input into keys. Bracketed pastes may span
+// several reads, so it keeps state between calls.
+type Decoder struct {
+	pasting bool
+	paste   strings.Builder
+}
+
+func (d *Decoder) Feed(data []byte) []Key {
+	var keys []Key
+	s := string(data)
+
+	for len(s) > 0 {
+		if d.pasting {
+			end := strings.Index(s, pasteEnd)
+			if end < 0 {
+				d.paste.WriteString(s)
+				return keys
+			}
+
+			d.paste.WriteString(s[:end])
+			text := strings.ReplaceAll(d.paste.String(), "\r\n", "\n")
+			keys = append(keys, Key{Type: KeyPaste, Text: strings.ReplaceAll(text, "\r", "\n")})
+
+			d.pasting = false
+			d.paste.Reset()
+			s = s[end+len(pasteEnd):]
+			continue
+		}
+
+		if strings.HasPrefix(s, pasteStart) {
+			d.pasting = true
+			s = s[len(pasteStart):]
+			continue
+		}
+
+		key, n := decodeKey(s)
+		s = s[n:]
+		if n == 0 {
+			return keys
+		}
+		if key != nil {
+			keys = append(keys, *key)
+		}
+	}
+
+	return keys
+}
+
+func decodeKey(s string) (*Key, int) {
+	b := s[0]
+
+	switch {
+	case b == 0x1b:
+		return decodeEscape(s)
+	case b == '\r':
+		return &Key{Type: KeyEnter}, 1
+	case b == '\n':
+		return &Key{Type: KeyNewline}, 1
+	case b == '\t':
+		return &Key{Type: KeyTab}, 1
+	case b == 0x7f || b == 0x08:
+		return &Key{Type: KeyBackspace}, 1
+	case b < 0x20:
+		if b >= 1 && b <= 26 {
+			return &Key{Type: KeyCtrl, Rune: rune('a' + b - 1)}, 1
+		}
+		return nil, 1
+	}
+
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError && size <= 1 {
+		return nil, 1
+	}
+	return &Key{Type: KeyRune, Rune: r}, size
+}
+
+func decodeEscape(s string) (*Key, int) {
+	if len(s) == 1 {
+		return &Key{Type: KeyEsc}, 1
+	}
+
+	switch s[1] {
+	case '\r':
+		return &Key{Type: KeyNewline}, 2
+	case '[', 'O':
+	default:
+		// Alt+key arrives as ESC followed by the key; treat it as the key
+		return &Key{Type: KeyEsc}, 1
+	}
+
+	// CSI / SS3: parameters then a final byte in @..~
+	end := 2
+	for end < len(s) && (s[end] < 0x40 || s[end] > 0x7e) {
+		end++
+	}
+	if end >= len(s) {
+		return &Key{Type: KeyEsc}, 1
+	}
+
+	params, final := s[2:end], s[end]
+	n := end + 1
+
+	switch final {
+	case 'A':
+		return &Key{Type: KeyUp}, n
+	case 'B':
+		return &Key{Type: KeyDown}, n
+	case 'C':
+		return &Key{Type: KeyRight}, n
+	case 'D':
+		return &Key{Type: KeyLeft}, n
+	case 'H':
+		return &Key{Type: KeyHome}, n
+	case 'F':
+		return &Key{Type: KeyEnd}, n
+	case 'Z':
+		return &Key{Type: KeyShiftTab}, n
+	case '~':
+		switch params {
+		case "1", "7":
+			return &Key{Type: KeyHome}, n
+		case "4", "8":
+			return &Key{Type: KeyEnd}, n
+		case "3":
+			return &Key{Type: KeyDelete}, n
+		case "5":
+			return &Key{Type: KeyPgUp}, n
+		case "6":
+			return &Key{Type: KeyPgDn}, n
+		}
+	}
+
+	return nil, n
+}
@@ -0,0 +1,184 @@
+// Package tui holds the terminal plumbing behind the full-screen interface:
+// raw mode and the alternate screen, key decoding and width-aware text.
+package tui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	enterSequence = "\x1b[?1049h\x1b[?7l\x1b[?2004h"
+	leaveSequence = "\x1b[?2004l\x1b[?7h\x1b[?25h\x1b[?1049l"
+)
+
+type Screen struct {
+	in    *os.File
+	out   *bufio.Writer
+	fd    int
+	state *term.State
+
+	// paused is set while another program reads the terminal and closed
+	// when input is resumed
+	mu     sync.Mutex
+	paused chan struct{}
+}
+
+// Supported reports whether stdin and stdout are a terminal capable of the
+// full-screen interface
+func Supported() bool {
+	switch os.Getenv("TERM") {
+	case "", "dumb":
+		return false
+	}
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// Open switches the terminal to raw mode on the alternate screen
+func Open() (*Screen, error) {
+	// A separate handle on the terminal can be read with deadlines, which
+	// lets PauseInput stop the reader without touching stdin itself
+	in := os.Stdin
+	if tty, err := os.Open("/dev/tty"); err == nil {
+		in = tty
+	}
+
+	s := &Screen{
+		in:  in,
+		out: bufio.NewWriterSize(os.Stdout, 64*1024),
+		fd:  int(os.Stdin.Fd()),
+	}
+
+	if err := s.Resume(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Suspend gives the terminal back in its normal state, e.g. to run a command
+// that prints and reads lines
+func (s *Screen) Suspend() error {
+	s.out.WriteString(leaveSequence)
+	s.out.Flush()
+
+	if s.state == nil {
+		return nil
+	}
+
+	err := term.Restore(s.fd, s.state)
+	s.state = nil
+	return err
+}
+
+// Resume re-enters raw mode after Suspend
+func (s *Screen) Resume() error {
+	state, err := term.MakeRaw(s.fd)
+	if err != nil {
+		return fmt.Errorf("failed to enter raw mode: %w", err)
+	}
+	s.state = state
+
+	s.out.WriteString(enterSequence)
+	return s.out.Flush()
+}
+
+func (s *Screen) Close() error {
+	err := s.Suspend()
+	if s.in != os.Stdin {
+		s.in.Close()
+	}
+	return err
+}
+
+// PauseInput stops ReadInput from consuming keys, so a program like an
+// editor can read the terminal. It fails where reads can't be interrupted.
+func (s *Screen) PauseInput() error {
+	s.mu.Lock()
+	if s.paused == nil {
+		s.paused = make(chan struct{})
+	}
+	s.mu.Unlock()
+
+	if err := s.in.SetReadDeadline(time.Now()); err != nil {
+		s.ResumeInput()
+		return fmt.Errorf("input can't be paused: %w", err)
+	}
+	return nil
+}
+
+// ResumeInput lets ReadInput continue after PauseInput
+func (s *Screen) ResumeInput() {
+	s.in.SetReadDeadline(time.Time{})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.paused != nil {
+		close(s.paused)
+		s.paused = nil
+	}
+}
+
+// Size returns the terminal's width and height
+func (s *Screen) Size() (int, int) {
+	w, h, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || w <= 0 || h <= 0 {
+		return 80, 24
+	}
+	return w, h
+}
+
+// Draw replaces the screen with lines and places the cursor at row, col
+// (zero-based). A negative row hides the cursor.
+func (s *Screen) Draw(lines []string, row, col int) error {
+	var b strings.Builder
+
+	b.WriteString("\x1b[?25l\x1b[H")
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(line)
+		b.WriteString("\x1b[0m\x1b[K")
+	}
+	b.WriteString("\x1b[J")
+
+	if row >= 0 {
+		fmt.Fprintf(&b, "\x1b[%d;%dH\x1b[?25h", row+1, col+1)
+	}
+
+	s.out.WriteString(b.String())
+	return s.out.Flush()
+}
+
+// ReadInput reads raw chunks from stdin into ch until stdin closes
+func (s *Screen) ReadInput(ch chan<- []byte) {
+	buf := make([]byte, 4096)
+	for {
+		n, err := s.in.Read(buf)
+		if n > 0 {
+			chunk := make([]byte, n)
+			copy(chunk, buf[:n])
+			ch <- chunk
+		}
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			s.mu.Lock()
+			paused := s.paused
+			s.mu.Unlock()
+			if paused != nil {
+				<-paused
+			}
+			continue
+		}
+		if err != nil {
+			close(ch)
+			return
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Width is the number of terminal cells s occupies
+func Width(s string) int {
+	return runewidth.StringWidth(s)
+}
+
+// Truncate shortens s to at most w cells, marking the cut with an ellipsis
+func Truncate(s string, w int) string {
+	if w <= 0 {
+		return ""
+	}
+	return runewidth.Truncate(s, w, "…")
+}
+
+// Pad truncates or right-pads s to exactly w cells
+func Pad(s string, w int) string {
+	return runewidth.FillRight(Truncate(s, w), w)
+}
+
+// PadLeft truncates or left-pads s to exactly w cells
+func PadLeft(s string, w int) string {
+	return runewidth.FillLeft(Truncate(s, w), w)
+}
+
+// Wrap breaks s into lines of at most w cells, preferring word boundaries
+// and keeping explicit newlines
+func Wrap(s string, w int) []string {
+	if w <= 0 {
+		return nil
+	}
+
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		lines = append(lines, wrapParagraph(paragraph, w)...)
+	}
+	return lines
+}
+
+func wrapParagraph(s string, w int) []string {
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	var lines []string
+	var line strings.Builder
+	lineWidth := 0
+
+	for _, word := range words {
+		wordWidth := Width(word)
+
+		if lineWidth > 0 && lineWidth+1+wordWidth > w {
+			lines = append(lines, line.String())
+			line.Reset()
+			lineWidth = 0
+		}
+
+		// Words longer than a line are split by cell. The line is empty here,
+		// since a long word never fits after another one.
+		for wordWidth > w {
+			head := runewidth.Truncate(word, w, "")
+			if head == "" {
+				break
+			}
+			lines = append(lines, head)
+			word = word[len(head):]
+			wordWidth = Width(word)
+		}
+
+		if lineWidth > 0 {
+			line.WriteByte(' ')
+			lineWidth++
+		}
+		line.WriteString(word)
+		lineWidth += wordWidth
+	}
+
+	return append(lines, line.String())
+}
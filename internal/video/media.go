@@ -0,0 +1,150 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// WaveformHz is the sampling frequency of AudioInfo.Waveform, matching what
+// Instagram expects for voice notes
+const WaveformHz = 10
+
+type ImageInfo struct {
+	Path   string
+	Width  int
+	Height int
+}
+
+type AudioInfo struct {
+	Path     string
+	Duration float64
+	Waveform []float64
+}
+
+func probeImage(path string) (int, int, error) {
+	w, h, _, err := probeVideo(path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to probe image: %w", err)
+	}
+	return w, h, nil
+}
+
+// PrepareImage converts any ffmpeg-readable image into a JPEG Instagram accepts
+func PrepareImage(ctx context.Context, inputPath string) (ImageInfo, string, error) {
+	tmpDir, err := os.MkdirTemp("", "dm_photo")
+	if err != nil {
+		return ImageInfo{}, "", err
+	}
+
+	outputPath := filepath.Join(tmpDir, "photo.jpg")
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-y",
+		"-i", inputPath,
+		"-vf", "scale='min(1440,iw)':-2",
+		"-q:v", "2",
+		"-frames:v", "1",
+		outputPath)
+
+	if err := cmd.Run(); err != nil {
+		os.RemoveAll(tmpDir)
+		return ImageInfo{}, "", fmt.Errorf("image conversion failed: %w", err)
+	}
+
+	w, h, err := probeImage(outputPath)
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return ImageInfo{}, "", err
+	}
+
+	return ImageInfo{Path: outputPath, Width: w, Height: h}, tmpDir, nil
+}
+
+// PrepareAudio transcodes a recording to AAC and samples its waveform
+func PrepareAudio(ctx context.Context, inputPath string) (AudioInfo, string, error) {
+	tmpDir, err := os.MkdirTemp("", "dm_voice")
+	if err != nil {
+		return AudioInfo{}, "", err
+	}
+
+	outputPath := filepath.Join(tmpDir, "voice.m4a")
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-y",
+		"-i", inputPath,
+		"-vn",
+		"-ac", "1",
+		"-ar", "44100",
+		"-c:a", "aac",
+		"-b:a", "64k",
+		outputPath)
+
+	if err := cmd.Run(); err != nil {
+		os.RemoveAll(tmpDir)
+		return AudioInfo{}, "", fmt.Errorf("audio conversion failed: %w", err)
+	}
+
+	duration, err := getTotalDuration(outputPath)
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return AudioInfo{}, "", err
+	}
+
+	waveform, err := sampleWaveform(ctx, outputPath)
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return AudioInfo{}, "", err
+	}
+
+	return AudioInfo{Path: outputPath, Duration: duration, Waveform: waveform}, tmpDir, nil
+}
+
+// sampleWaveform decodes the audio to 8kHz PCM and returns normalized peak
+// levels at WaveformHz, which is what Instagram draws under voice notes
+func sampleWaveform(ctx context.Context, path string) ([]float64, error) {
+	const sampleRate = 8000
+
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-i", path,
+		"-ac", "1",
+		"-ar", fmt.Sprintf("%d", sampleRate),
+		"-f", "s16le",
+		"-")
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode audio: %w", err)
+	}
+
+	samples := make([]int16, len(out)/2)
+	if err := binary.Read(bytes.NewReader(out[:len(samples)*2]), binary.LittleEndian, samples); err != nil {
+		return nil, fmt.Errorf("failed to read samples: %w", err)
+	}
+
+	bucket := sampleRate / WaveformHz
+	waveform := make([]float64, 0, len(samples)/bucket+1)
+
+	var peak float64
+	for i := 0; i < len(samples); i += bucket {
+		end := min(i+bucket, len(samples))
+
+		level := 0.0
+		for _, s := range samples[i:end] {
+			level = math.Max(level, math.Abs(float64(s))/math.MaxInt16)
+		}
+		peak = math.Max(peak, level)
+		waveform = append(waveform, level)
+	}
+
+	if peak > 0 {
+		for i := range waveform {
+			waveform[i] = math.Round(waveform[i]/peak*100) / 100
+		}
+	}
+
+	return waveform, nil
+}